@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+)
+
+// 遍历审计事件的哈希链并报告断裂, 存在断裂时以非零状态退出
+func main() {
+	provider.Init()
+
+	report, err := provider.Get().AuditService.VerifyChain(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "校验审计日志失败: %v\n", err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("已校验 %d 条审计事件, 链尾序号 %d, 链尾哈希 %s\n", report.Checked, report.LastSeq, report.LastHash)
+	if len(report.Breaks) == 0 {
+		fmt.Println("哈希链完整")
+		return
+	}
+
+	for _, b := range report.Breaks {
+		fmt.Printf("序号 %d: %s\n", b.Seq, b.Reason)
+	}
+	fmt.Printf("发现 %d 处断裂\n", len(report.Breaks))
+	os.Exit(1)
+}
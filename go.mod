@@ -4,6 +4,7 @@ go 1.25.0
 
 require (
 	github.com/cloudwego/hertz v0.10.2
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.1
 	github.com/gin-gonic/gin v1.10.1
 	github.com/golang-jwt/jwt/v4 v4.5.2
 	github.com/google/uuid v1.6.0
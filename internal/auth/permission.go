@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+)
+
+// Permission 是具名权限, 以 "资源.动作" 命名
+// 角色配置中可以使用通配符: "*" 匹配全部权限, "user.*" 匹配 user 下的全部权限
+type Permission string
+
+const (
+	PermUserRead       Permission = "user.read"
+	PermUserExport     Permission = "user.export"
+	PermUserRoleUpdate Permission = "user.role.update"
+	PermUserBan        Permission = "user.ban"
+	PermUserSuspend    Permission = "user.suspend"
+	PermUserUnlock     Permission = "user.unlock" // 解除登录失败锁定
+
+	PermInviteManage Permission = "invite.manage" // 生成预设角色、不限次数或不过期的邀请码
+
+	PermOrganizationManage Permission = "organization.manage" // 管理任意组织, 创建者无需此权限即可管理自己的组织
+
+	PermProposalManage Permission = "proposal.manage" // 结算与执行提案
+	PermProposalCancel Permission = "proposal.cancel" // 取消任意提案, 发起人无需此权限即可取消自己草稿或投票中的提案
+
+	PermSnapshotCreate Permission = "snapshot.create"
+	PermMerklePublish  Permission = "merkle.publish"
+
+	PermAuditRead Permission = "audit.read"
+
+	PermAPIKeyRevoke Permission = "apikey.revoke" // 吊销任意用户的 API Key
+)
+
+// API Key 按请求方法区分的 scope, 访问需要权限的接口时还必须包含对应的权限
+const (
+	ScopeRead  Permission = "read"  // 调用 GET 接口
+	ScopeWrite Permission = "write" // 调用全部接口
+)
+
+// defaultRoles 未配置 Config.Roles 时使用的角色权限
+var defaultRoles = []config.Role{
+	{Code: int(enum.RoleAdmin), Name: enum.GetUserRoleDesc(enum.RoleAdmin), Permissions: []string{"*"}, RequireTwoFactor: true},
+	{Code: int(enum.RoleUser), Name: enum.GetUserRoleDesc(enum.RoleUser)},
+	{Code: int(enum.RoleModerator), Name: enum.GetUserRoleDesc(enum.RoleModerator), Permissions: []string{
+		string(PermUserRead),
+		string(PermUserBan),
+		string(PermUserSuspend),
+		string(PermUserUnlock),
+		string(PermAPIKeyRevoke),
+	}},
+	{Code: int(enum.RoleAuditor), Name: enum.GetUserRoleDesc(enum.RoleAuditor), Permissions: []string{
+		string(PermUserRead),
+		string(PermUserExport),
+		string(PermAuditRead),
+	}},
+}
+
+// Authorizer 根据角色判断是否拥有权限
+type Authorizer struct {
+	roles     map[enum.UserRole][]string
+	twoFactor map[enum.UserRole]bool
+}
+
+// NewAuthorizer 从配置加载角色与权限的映射, 新增角色只需修改配置
+func NewAuthorizer(config *config.Config) *Authorizer {
+	roles := config.Roles
+	if len(roles) == 0 {
+		roles = defaultRoles
+	}
+
+	a := &Authorizer{
+		roles:     make(map[enum.UserRole][]string, len(roles)),
+		twoFactor: make(map[enum.UserRole]bool, len(roles)),
+	}
+	for _, role := range roles {
+		code := enum.UserRole(role.Code)
+		if role.Name != "" {
+			enum.RegisterUserRole(code, role.Name)
+		}
+		a.roles[code] = role.Permissions
+		a.twoFactor[code] = role.RequireTwoFactor
+		log.Info("加载角色 %s(%d), 权限 %v", enum.GetUserRoleDesc(code), role.Code, role.Permissions)
+	}
+	return a
+}
+
+// IsRoleDefined 判断角色是否已配置
+func (a *Authorizer) IsRoleDefined(role enum.UserRole) bool {
+	_, ok := a.roles[role]
+	return ok
+}
+
+// RequiresTwoFactor 判断角色是否必须开启两步验证
+func (a *Authorizer) RequiresTwoFactor(role enum.UserRole) bool {
+	return a.twoFactor[role]
+}
+
+// HasPermission 判断角色是否拥有全部给定权限
+func (a *Authorizer) HasPermission(role enum.UserRole, perms ...Permission) bool {
+	granted := a.roles[role]
+	for _, perm := range perms {
+		if !matchAny(granted, perm) {
+			return false
+		}
+	}
+	return true
+}
+
+// Allows 判断认证主体是否拥有全部给定权限, 使用 API Key 认证时还要求 scope 覆盖这些权限
+func (a *Authorizer) Allows(principal *Principal, perms ...Permission) bool {
+	return a.HasPermission(principal.Role, perms...) && principal.HasScope(perms...)
+}
+
+// Covers 判断 operator 角色是否拥有 target 角色的全部权限, 即不低于 target
+func (a *Authorizer) Covers(operator, target enum.UserRole) bool {
+	return covers(a.roles[operator], a.roles[target])
+}
+
+// Outranks 判断 operator 角色是否严格高于 target 角色:
+// operator 拥有 target 的全部权限, 而 target 不拥有 operator 的全部权限
+func (a *Authorizer) Outranks(operator, target enum.UserRole) bool {
+	return a.Covers(operator, target) && !a.Covers(target, operator)
+}
+
+// covers 判断 granted 是否覆盖 patterns 中的每一项, 通配符按字面参与匹配
+func covers(granted, patterns []string) bool {
+	for _, pattern := range patterns {
+		if !matchAny(granted, Permission(pattern)) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAny(granted []string, perm Permission) bool {
+	for _, pattern := range granted {
+		if pattern == "*" || pattern == string(perm) {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(string(perm), prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+)
+
+func TestOutranks(t *testing.T) {
+	const roleSupport enum.UserRole = 100
+	a := NewAuthorizer(&config.Config{Roles: append(defaultRoles[:len(defaultRoles):len(defaultRoles)],
+		config.Role{Code: int(roleSupport), Permissions: []string{"user.*"}},
+	)})
+
+	tests := []struct {
+		name             string
+		operator, target enum.UserRole
+		want             bool
+	}{
+		{"admin over moderator", enum.RoleAdmin, enum.RoleModerator, true},
+		{"admin over admin", enum.RoleAdmin, enum.RoleAdmin, false},
+		{"moderator over user", enum.RoleModerator, enum.RoleUser, true},
+		{"moderator over moderator", enum.RoleModerator, enum.RoleModerator, false},
+		{"moderator over admin", enum.RoleModerator, enum.RoleAdmin, false},
+		{"moderator over auditor", enum.RoleModerator, enum.RoleAuditor, false},
+		{"auditor over moderator", enum.RoleAuditor, enum.RoleModerator, false},
+		{"user over user", enum.RoleUser, enum.RoleUser, false},
+		{"wildcard over moderator", roleSupport, enum.RoleModerator, false},
+		{"wildcard over auditor", roleSupport, enum.RoleAuditor, false},
+		{"admin over wildcard", enum.RoleAdmin, roleSupport, true},
+		{"moderator over wildcard", enum.RoleModerator, roleSupport, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.Outranks(tt.operator, tt.target); got != tt.want {
+				t.Errorf("Outranks(%d, %d) = %v, want %v", tt.operator, tt.target, got, tt.want)
+			}
+		})
+	}
+}
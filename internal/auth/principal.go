@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Principal 当前请求的认证主体, 由认证中间件写入上下文
+type Principal struct {
+	UserID        bson.ObjectID
+	Role          enum.UserRole
+	Status        enum.UserStatus
+	EmailVerified bool          // 邮箱是否已验证, 未验证的账号只能访问有限的接口
+	TwoFactor     bool          // 是否已开启两步验证
+	TokenID       string        // 访问令牌的 jti
+	FamilyID      string        // 访问令牌所属的刷新令牌家族
+	ExpiresAt     time.Time     // 访问令牌的过期时间
+	APIKeyID      bson.ObjectID // 使用 API Key 认证时为密钥ID, 此时上述令牌字段为空
+	Scopes        []string      // API Key 的 scope, 支持与权限相同的通配
+}
+
+// IsAPIKey 判断是否通过 API Key 认证
+func (p *Principal) IsAPIKey() bool {
+	return !p.APIKeyID.IsZero()
+}
+
+// HasScope 判断 API Key 的 scope 是否覆盖全部给定权限, 使用访问令牌认证时总是为 true
+func (p *Principal) HasScope(perms ...Permission) bool {
+	if !p.IsAPIKey() {
+		return true
+	}
+	for _, perm := range perms {
+		if !matchAny(p.Scopes, perm) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetPrincipal 从上下文中获取认证主体
+func GetPrincipal(ctx context.Context) (*Principal, error) {
+	principal, ok := ctx.Value(consts.ContextPrincipal).(*Principal)
+	if !ok || principal == nil || principal.UserID.IsZero() {
+		return nil, errorx.ErrContextUserIDInvalid
+	}
+	return principal, nil
+}
+
+// GetUserID 从上下文中获取当前用户ID
+func GetUserID(ctx context.Context) (bson.ObjectID, error) {
+	principal, err := GetPrincipal(ctx)
+	if err != nil {
+		return bson.NilObjectID, err
+	}
+	return principal.UserID, nil
+}
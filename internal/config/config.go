@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 
+	"github.com/NoANameGroup/DAOld-Backend/pkg/lib"
 	"github.com/zeromicro/go-zero/core/conf"
 	"github.com/zeromicro/go-zero/core/service"
 	"github.com/zeromicro/go-zero/core/stores/cache"
@@ -11,18 +12,138 @@ import (
 
 var config *Config
 
+// Auth JWT 签名配置
+// SecretKey/PublicKey 为当前签名密钥对, 可以是 PEM 内容或 PEM 文件路径
+// 轮换密钥时把旧公钥移入 VerifyKeys, 直到旧令牌全部过期
 type Auth struct {
-	SecretKey    string
-	PublicKey    string
-	AccessExpire int64
+	Algorithm     string `json:",default=RS256,options=RS256|EdDSA"`
+	KeyID         string `json:",optional"` // 为空时使用公钥的 JWK 指纹
+	SecretKey     string
+	PublicKey     string      `json:",optional"`
+	VerifyKeys    []VerifyKey `json:",optional"`
+	AccessExpire  int64       // 访问令牌有效期(秒)
+	RefreshExpire int64       `json:",optional"` // 刷新令牌有效期(秒)
+}
+
+// VerifyKey 仅用于验签的公钥
+type VerifyKey struct {
+	KeyID     string
+	Algorithm string `json:",default=RS256,options=RS256|EdDSA"`
+	PublicKey string
+}
+
+// Mail 邮件发送配置, Driver 为 smtp、file 或 log
+type Mail struct {
+	Driver   string `json:",default=log,options=smtp|file|log"`
+	Host     string `json:",optional"`
+	Port     int    `json:",default=587"`
+	Username string `json:",optional"`
+	Password string `json:",optional"`
+	From     string `json:",optional"`
+	Dir      string `json:",optional"` // file 模式下邮件的输出目录
+}
+
+// EmailVerify 邮箱验证配置, 未配置 Secret 时关闭邮箱验证, 新注册的账号视为已验证
+type EmailVerify struct {
+	Secret         string `json:",optional"`      // 验证令牌的签名密钥
+	Expire         int64  `json:",default=86400"` // 验证令牌有效期(秒)
+	URL            string `json:",optional"`      // 验证页面地址, 令牌以 token 参数附加在其后
+	ResendInterval int64  `json:",default=60"`    // 两次发送的最小间隔(秒)
+	ResendPerDay   int64  `json:",default=10"`    // 每天最多发送次数
+}
+
+// PasswordReset 找回密码配置
+type PasswordReset struct {
+	Expire          int64  `json:",default=3600"` // 重置令牌有效期(秒)
+	URL             string `json:",optional"`     // 重置页面地址, 令牌以 token 参数附加在其后
+	PerEmailPerHour int64  `json:",default=3"`    // 同一邮箱每小时最多请求次数
+	PerIPPerHour    int64  `json:",default=20"`   // 同一 IP 每小时最多请求次数
+}
+
+// TwoFactor 两步验证配置
+type TwoFactor struct {
+	Issuer        string `json:",default=DAOld"` // 验证器应用中显示的发行方
+	PendingExpire int64  `json:",default=300"`   // 密码校验通过后完成二次验证的时限(秒)
+	MaxAttempts   int64  `json:",default=5"`     // 同一次登录最多可尝试的验证码次数
+	RecoveryCodes int    `json:",default=10"`    // 生成的恢复码数量
+}
+
+// SIWE 以太坊钱包登录 (EIP-4361) 配置
+type SIWE struct {
+	Domain      string  `json:",optional"`    // 签名消息中必须出现的域名, 为空时不启用钱包登录
+	URI         string  `json:",optional"`    // 签名消息中的 URI 必须以此为前缀
+	ChainIDs    []int64 `json:",optional"`    // 允许的链 ID, 为空时不限制
+	NonceExpire int64   `json:",default=300"` // nonce 有效期(秒)
+	MaxAge      int64   `json:",default=600"` // 签发时间距今的最长时间(秒)
+}
+
+// Invite 邀请码配置, 成员生成的邀请码受以下限制, 拥有 invite.manage 权限的用户不受限制
+type Invite struct {
+	Required        bool  `json:",optional"`       // 注册是否必须使用邀请码
+	MemberMaxUses   int64 `json:",default=5"`      // 成员生成的邀请码最多可使用次数
+	MemberExpire    int64 `json:",default=604800"` // 成员生成的邀请码最长有效期(秒)
+	MemberMaxActive int64 `json:",default=10"`     // 成员同时持有的可用邀请码上限
+}
+
+// Lockout 登录失败锁定配置, 账号按邮箱计数, 未注册的邮箱同样会被锁定, 避免泄露账号是否存在
+// 失败次数达到阈值后锁定 BaseDuration, 此后每多失败一次锁定时长翻倍, 最长为 MaxDuration
+type Lockout struct {
+	AccountThreshold int64 `json:",default=5"`     // 同一账号允许的连续失败次数
+	IPThreshold      int64 `json:",default=20"`    // 同一 IP 允许的连续失败次数
+	BaseDuration     int64 `json:",default=60"`    // 首次锁定时长(秒)
+	MaxDuration      int64 `json:",default=3600"`  // 最长锁定时长(秒)
+	Window           int64 `json:",default=86400"` // 失败计数的保留时间(秒), 从首次失败开始计算
+}
+
+// APIKey 个人 API Key 配置, 每个密钥都必须有过期时间
+type APIKey struct {
+	MaxPerUser    int64 `json:",default=10"`       // 每个用户同时持有的有效密钥上限
+	DefaultExpire int64 `json:",default=7776000"`  // 未指定有效期时使用的有效期(秒)
+	MaxExpire     int64 `json:",default=31536000"` // 最长有效期(秒)
+}
+
+// RateLimit 限流配置, Rules 按名称覆盖内置规则, 配置了 Redis 时多实例共享计数
+type RateLimit struct {
+	Disabled bool            `json:",optional"`
+	Rules    []RateLimitRule `json:",optional"`
+}
+
+// RateLimitRule 限流规则, Limit 不大于 0 时关闭该规则
+// 滑动窗口: 任意 Period 秒内最多 Limit 次; 令牌桶: 容量为 Limit, 每 Period 秒补满
+type RateLimitRule struct {
+	Name      string
+	Algorithm string `json:",default=token_bucket,options=token_bucket|sliding_window"`
+	KeyBy     string `json:",default=ip,options=ip|user|api_key"` // 按 IP、用户ID 或 API Key 计数
+	Limit     int64
+	Period    int64 `json:",default=60"`
+}
+
+// Role 角色及其拥有的权限, 权限支持 "*" 与 "user.*" 形式的通配
+type Role struct {
+	Code             int
+	Name             string
+	Permissions      []string `json:",optional"`
+	RequireTwoFactor bool     `json:",optional"` // 该角色访问受保护的接口前必须开启两步验证
 }
 
 type Config struct {
 	service.ServiceConf
-	ListenOn string
-	State    string
-	Auth     Auth
-	Mongo    struct {
+	ListenOn       string
+	State          string
+	TrustedProxies []string `json:",optional"` // 可信反向代理的 IP 或 CIDR, 为空时忽略 X-Forwarded-For, 使用连接地址
+	Auth           Auth
+	Roles          []Role      `json:",optional"` // 为空时使用内置角色
+	Mail           Mail        `json:",optional"`
+	EmailVerify    EmailVerify `json:",optional"`
+	PasswordReset  PasswordReset
+	TwoFactor      TwoFactor
+	SIWE           SIWE `json:",optional"`
+	Invite         Invite
+	Lockout        Lockout
+	APIKey         APIKey
+	RateLimit      RateLimit `json:",optional"`
+	RedactKeys     []string  `json:",optional"` // 请求与响应日志中额外需要脱敏的键名
+	Mongo          struct {
 		URL string
 		DB  string
 	}
@@ -44,6 +165,7 @@ func NewConfig() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	lib.AddRedactKeys(c.RedactKeys...)
 	config = c
 	return c, nil
 }
@@ -5,13 +5,19 @@ const ()
 
 // JWT 相关
 const (
-	JWTSecret = "your-secret-key" // 可以从环境变量读取更安全
+	DefaultAccessExpire  = 15 * 60           // 访问令牌默认有效期(秒)
+	DefaultRefreshExpire = 30 * 24 * 60 * 60 // 刷新令牌默认有效期(秒)
 )
 
 // 业务相关
 const (
-	ContextUserID   = "userId"
-	ContextTargetID = "targetId"
+	ContextPrincipal = "principal"
+	ContextTargetID  = "targetId"
+	ContextClientIP  = "clientIp"
+	ContextUserAgent = "userAgent"
+	ContextRequestID = "requestId"
+
+	HeaderRequestID = "X-Request-ID"
 )
 
 // 数据库相关
@@ -34,4 +40,70 @@ const (
 	FirstName   = "firstName"
 	LastName    = "lastName"
 	LastLoginAt = "lastLoginAt"
+	TokenHash   = "tokenHash"
+	FamilyID    = "familyId"
+	Used        = "used"
+	UsedAt      = "usedAt"
+	Revoked     = "revoked"
+	ExpiresAt   = "expiresAt"
+
+	StatusReason   = "statusReason"
+	SuspendedUntil = "suspendedUntil"
+
+	EmailUnverified = "emailUnverified"
+	NonceHash       = "nonceHash"
+
+	TOTPEnabled       = "totpEnabled"
+	TOTPSecret        = "totpSecret"
+	TOTPPendingSecret = "totpPendingSecret"
+	TOTPLastStep      = "totpLastStep"
+	RecoveryCodes     = "recoveryCodes"
+
+	Primary = "primary"
+
+	Name        = "name"
+	Slug        = "slug"
+	Description = "description"
+	Governance  = "governance"
+	ArchivedAt  = "archivedAt"
+
+	Code         = "code"
+	CreatorID    = "creatorId"
+	MaxUses      = "maxUses"
+	UsedCount    = "usedCount"
+	ReferredBy   = "referredBy"
+	InviteCodeID = "inviteCodeId"
+
+	Title         = "title"
+	ProposerID    = "proposerId"
+	VotingPeriod  = "votingPeriod"
+	VotingStartAt = "votingStartAt"
+	VotingEndAt   = "votingEndAt"
+	Transitions   = "transitions"
+
+	PollID  = "pollId"
+	VoterID = "voterId"
+	Choices = "choices"
+	Credits = "credits"
+
+	DelegatorID = "delegatorId"
+	DelegateID  = "delegateId"
+
+	Data = "data"
+
+	Seq        = "seq"
+	ActorID    = "actorId"
+	Action     = "action"
+	TargetType = "targetType"
+	TargetID   = "targetId"
+
+	TokenID    = "tokenId"
+	Device     = "device"
+	RevokedAt  = "revokedAt"
+	LastSeenAt = "lastSeenAt"
+
+	KeyHash    = "keyHash"
+	RevokedBy  = "revokedBy"
+	LastUsedAt = "lastUsedAt"
+	LastUsedIP = "lastUsedIp"
 )
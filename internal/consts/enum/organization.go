@@ -0,0 +1,30 @@
+package enum
+
+// OrganizationStatus
+type OrganizationStatus int
+
+const (
+	OrganizationActive   OrganizationStatus = 1 // 活跃
+	OrganizationArchived OrganizationStatus = 2 // 已归档
+)
+
+var OrganizationStatusMap = map[OrganizationStatus]string{
+	OrganizationActive:   "活跃",
+	OrganizationArchived: "已归档",
+}
+
+func GetOrganizationStatusDesc(code OrganizationStatus) string {
+	if desc, ok := OrganizationStatusMap[code]; ok {
+		return desc
+	}
+	return "未知状态"
+}
+
+func GetOrganizationStatusCode(desc string) OrganizationStatus {
+	for code, d := range OrganizationStatusMap {
+		if d == desc {
+			return code
+		}
+	}
+	return 0
+}
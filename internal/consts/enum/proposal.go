@@ -0,0 +1,38 @@
+package enum
+
+// ProposalStatus
+type ProposalStatus int
+
+const (
+	ProposalDraft     ProposalStatus = 1 // 草稿
+	ProposalActive    ProposalStatus = 2 // 投票中
+	ProposalSucceeded ProposalStatus = 3 // 已通过
+	ProposalDefeated  ProposalStatus = 4 // 未通过
+	ProposalExecuted  ProposalStatus = 5 // 已执行
+	ProposalCancelled ProposalStatus = 6 // 已取消
+)
+
+var ProposalStatusMap = map[ProposalStatus]string{
+	ProposalDraft:     "草稿",
+	ProposalActive:    "投票中",
+	ProposalSucceeded: "已通过",
+	ProposalDefeated:  "未通过",
+	ProposalExecuted:  "已执行",
+	ProposalCancelled: "已取消",
+}
+
+func GetProposalStatusDesc(code ProposalStatus) string {
+	if desc, ok := ProposalStatusMap[code]; ok {
+		return desc
+	}
+	return "未知状态"
+}
+
+func GetProposalStatusCode(desc string) ProposalStatus {
+	for code, d := range ProposalStatusMap {
+		if d == desc {
+			return code
+		}
+	}
+	return 0
+}
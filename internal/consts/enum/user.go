@@ -66,13 +66,22 @@ func GetUserGenderCode(desc string) UserGender {
 type UserRole int
 
 const (
-	RoleAdmin UserRole = 1 // 管理员
-	RoleUser  UserRole = 2 // 普通用户
+	RoleAdmin     UserRole = 1 // 管理员
+	RoleUser      UserRole = 2 // 普通用户
+	RoleModerator UserRole = 3 // 协管员
+	RoleAuditor   UserRole = 4 // 审计员
 )
 
 var UserRoleMap = map[UserRole]string{
-	RoleAdmin: "管理员",
-	RoleUser:  "用户",
+	RoleAdmin:     "管理员",
+	RoleUser:      "用户",
+	RoleModerator: "协管员",
+	RoleAuditor:   "审计员",
+}
+
+// RegisterUserRole 注册配置中定义的角色, 启动时调用
+func RegisterUserRole(code UserRole, desc string) {
+	UserRoleMap[code] = desc
 }
 
 func GetUserRoleDesc(code UserRole) string {
@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+)
+
+type SuspendUserReq struct {
+	Reason string    `json:"reason"`
+	Until  time.Time `json:"until"`
+}
+
+type BanUserReq struct {
+	Reason string `json:"reason"`
+}
+
+type UnlockIPReq struct {
+	IP string `json:"-" uri:"ip"`
+}
+
+// ListUsersReq 用户目录查询参数, 角色、状态、性别使用描述文字, 时间使用 RFC3339 格式
+// 传入 Cursor 时按游标分页并忽略 PageNum; Format 为 csv 或 jsonl 时导出全部匹配用户
+type ListUsersReq struct {
+	dto.PageParam
+	Role          string    `form:"role"`
+	Status        string    `form:"status"`
+	Gender        string    `form:"gender"`
+	CreatedFrom   time.Time `form:"createdFrom"`
+	CreatedTo     time.Time `form:"createdTo"`
+	LastLoginFrom time.Time `form:"lastLoginFrom"`
+	LastLoginTo   time.Time `form:"lastLoginTo"`
+	Keyword       string    `form:"keyword"`
+	SearchMode    string    `form:"searchMode"` // prefix(默认) 或 text
+	SortBy        string    `form:"sortBy"`     // createdAt(默认)、lastLoginAt、username、email
+	SortOrder     string    `form:"sortOrder"`  // desc(默认) 或 asc
+	Cursor        string    `form:"cursor"`
+	Format        string    `form:"format"` // json(默认)、csv、jsonl
+}
+
+// CreateSnapshotReq 创建成员快照, 状态与角色使用描述文字, 为空时不过滤; RegisteredBefore 为空时使用当前时间
+type CreateSnapshotReq struct {
+	Name             string    `json:"name"`
+	Status           string    `json:"status"`
+	Role             string    `json:"role"`
+	RegisteredBefore time.Time `json:"registeredBefore"`
+}
+
+type GetSnapshotReq struct {
+	ID string `json:"-" uri:"id"`
+}
+
+type ListSnapshotsReq struct {
+	dto.PageParam
+}
+
+type ListSnapshotMembersReq struct {
+	ID string `json:"-" uri:"id" form:"-"`
+	dto.PageParam
+}
+
+type CheckSnapshotMemberReq struct {
+	ID     string `json:"-" uri:"id"`
+	UserID string `json:"-" uri:"userId"`
+}
+
+// ListAuditEventsReq 审计事件查询参数, 时间使用 RFC3339 格式, 范围为 [From, To)
+type ListAuditEventsReq struct {
+	dto.PageParam
+	ActorID    string    `form:"actorId"`
+	Action     string    `form:"action"`
+	TargetType string    `form:"targetType"`
+	TargetID   string    `form:"targetId"`
+	From       time.Time `form:"from"`
+	To         time.Time `form:"to"`
+}
+
+// DiffSnapshotsReq 比较两个快照, Added 为在 ID 中而不在 Against 中的成员
+type DiffSnapshotsReq struct {
+	ID      string `json:"-" uri:"id" form:"-"`
+	Against string `json:"-" form:"against"`
+}
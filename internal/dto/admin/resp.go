@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type SuspendUserResp struct {
+	*dto.Resp
+}
+
+type BanUserResp struct {
+	*dto.Resp
+}
+
+type UnbanUserResp struct {
+	*dto.Resp
+}
+
+type UnlockUserResp struct {
+	*dto.Resp
+}
+
+type UnlockIPResp struct {
+	*dto.Resp
+}
+
+type ListUsersResp struct {
+	*dto.Resp
+	Total      int64     `json:"total"`
+	Users      []*UserVO `json:"users"`
+	NextCursor string    `json:"nextCursor"`
+}
+
+type CreateSnapshotResp struct {
+	*dto.Resp
+	*SnapshotVO
+}
+
+type GetSnapshotResp struct {
+	*dto.Resp
+	*SnapshotVO
+}
+
+type ListSnapshotsResp struct {
+	*dto.Resp
+	Total     int64         `json:"total"`
+	Snapshots []*SnapshotVO `json:"snapshots"`
+}
+
+type ListSnapshotMembersResp struct {
+	*dto.Resp
+	Total   int64           `json:"total"`
+	Members []bson.ObjectID `json:"members"`
+}
+
+type CheckSnapshotMemberResp struct {
+	*dto.Resp
+	Member bool `json:"member"`
+}
+
+// DiffSnapshotsResp 两个快照的差异, 成员列表最多返回 1000 个
+type DiffSnapshotsResp struct {
+	*dto.Resp
+	AddedCount   int64           `json:"addedCount"`
+	RemovedCount int64           `json:"removedCount"`
+	Added        []bson.ObjectID `json:"added"`
+	Removed      []bson.ObjectID `json:"removed"`
+	Truncated    bool            `json:"truncated"`
+}
+
+type ListAuditEventsResp struct {
+	*dto.Resp
+	Total  int64           `json:"total"`
+	Events []*AuditEventVO `json:"events"`
+}
@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type UserVO struct {
+	ID bson.ObjectID `json:"id"`
+	*user.UserVO
+}
+
+type SnapshotVO struct {
+	ID               bson.ObjectID `json:"id"`
+	Name             string        `json:"name"`
+	Status           string        `json:"status,omitempty"`
+	Role             string        `json:"role,omitempty"`
+	RegisteredBefore time.Time     `json:"registeredBefore"`
+	MemberCount      int64         `json:"memberCount"`
+	ContentHash      string        `json:"contentHash"`
+	CreatorID        bson.ObjectID `json:"creatorId"`
+	CreatedAt        time.Time     `json:"createdAt"`
+}
+
+type AuditEventVO struct {
+	ID         bson.ObjectID    `json:"id"`
+	Seq        int64            `json:"seq"`
+	PrevHash   string           `json:"prevHash"`
+	Hash       string           `json:"hash"`
+	ActorID    bson.ObjectID    `json:"actorId"`
+	Action     string           `json:"action"`
+	TargetType string           `json:"targetType"`
+	TargetID   string           `json:"targetId"`
+	Changes    []*AuditChangeVO `json:"changes"`
+	IP         string           `json:"ip"`
+	UserAgent  string           `json:"userAgent"`
+	RequestID  string           `json:"requestId"`
+	CreatedAt  time.Time        `json:"createdAt"`
+}
+
+type AuditChangeVO struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
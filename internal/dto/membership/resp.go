@@ -0,0 +1,26 @@
+package membership
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+)
+
+type PublishMerkleRootResp struct {
+	*dto.Resp
+	*MerkleRootVO
+}
+
+type GetMerkleRootResp struct {
+	*dto.Resp
+	*MerkleRootVO
+}
+
+// GetMembershipProofResp 当前用户在最新成员承诺中的包含证明
+// 叶子原文为 abi.encodePacked(bytes12 userId, address wallet), 没有主钱包时 wallet 为零地址
+type GetMembershipProofResp struct {
+	*dto.Resp
+	Root    *MerkleRootVO `json:"root"`
+	UserID  string        `json:"userId"`
+	Address string        `json:"address,omitempty"`
+	Leaf    string        `json:"leaf"`
+	Proof   []string      `json:"proof"`
+}
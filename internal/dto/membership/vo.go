@@ -0,0 +1,14 @@
+package membership
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MerkleRootVO struct {
+	ID        bson.ObjectID `json:"id"`
+	Root      string        `json:"root"`
+	LeafCount int64         `json:"leafCount"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
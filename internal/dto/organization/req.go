@@ -0,0 +1,37 @@
+package organization
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+)
+
+// CreateOrganizationReq 创建组织, Governance 为空时使用默认治理参数
+type CreateOrganizationReq struct {
+	Name        string        `json:"name"`
+	Slug        string        `json:"slug"`
+	Description string        `json:"description"`
+	Avatar      string        `json:"avatar"`
+	Governance  *GovernanceVO `json:"governance"`
+}
+
+type GetOrganizationReq struct {
+	Slug string `json:"-" uri:"slug"`
+}
+
+// UpdateOrganizationReq 更新组织, 空字段不修改, Governance 非空时整体替换
+type UpdateOrganizationReq struct {
+	Slug        string        `json:"-" uri:"slug"`
+	Name        string        `json:"name"`
+	NewSlug     string        `json:"slug"`
+	Description string        `json:"description"`
+	Avatar      string        `json:"avatar"`
+	Governance  *GovernanceVO `json:"governance"`
+}
+
+type ArchiveOrganizationReq struct {
+	Slug string `json:"-" uri:"slug"`
+}
+
+type ListOrganizationsReq struct {
+	dto.PageParam
+	Status string `form:"status"` // 活跃(默认)、已归档, all 表示全部
+}
@@ -0,0 +1,30 @@
+package organization
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+)
+
+type CreateOrganizationResp struct {
+	*dto.Resp
+	*OrganizationVO
+}
+
+type GetOrganizationResp struct {
+	*dto.Resp
+	*OrganizationVO
+}
+
+type UpdateOrganizationResp struct {
+	*dto.Resp
+	*OrganizationVO
+}
+
+type ArchiveOrganizationResp struct {
+	*dto.Resp
+}
+
+type ListOrganizationsResp struct {
+	*dto.Resp
+	Total         int64             `json:"total"`
+	Organizations []*OrganizationVO `json:"organizations"`
+}
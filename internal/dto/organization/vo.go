@@ -0,0 +1,29 @@
+package organization
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type OrganizationVO struct {
+	ID          bson.ObjectID `json:"id"`
+	Name        string        `json:"name"`
+	Slug        string        `json:"slug"`
+	Description string        `json:"description"`
+	Avatar      string        `json:"avatar"`
+	Governance  *GovernanceVO `json:"governance"`
+	CreatorID   bson.ObjectID `json:"creatorId"`
+	Status      string        `json:"status"`
+	ArchivedAt  *time.Time    `json:"archivedAt,omitempty"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+}
+
+type GovernanceVO struct {
+	VotingStrategy    string `json:"votingStrategy"`
+	VotingPeriod      int64  `json:"votingPeriod"`
+	QuorumPercent     int    `json:"quorumPercent"`
+	PassPercent       int    `json:"passPercent"`
+	ProposalThreshold int64  `json:"proposalThreshold"`
+}
@@ -0,0 +1,35 @@
+package poll
+
+import (
+	"time"
+)
+
+// CreatePollReq 创建投票, Strategy 为空时一人一票, 不支持 weighted, Credits 只用于二次方计票
+type CreatePollReq struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Options     []string  `json:"options"`
+	Strategy    string    `json:"strategy"`
+	Credits     int64     `json:"credits"`
+	Deadline    time.Time `json:"deadline"`
+}
+
+type GetPollReq struct {
+	ID string `json:"-" uri:"id"`
+}
+
+// CastBallotReq 投票或改票
+// 单选与认可投票填写 Choices, 排序复选按偏好从高到低填写 Choices, 二次方计票填写 Credits
+type CastBallotReq struct {
+	ID      string           `json:"-" uri:"id"`
+	Choices []string         `json:"choices"`
+	Credits map[string]int64 `json:"credits"`
+}
+
+type GetMyBallotReq struct {
+	ID string `json:"-" uri:"id"`
+}
+
+type GetPollResultReq struct {
+	ID string `json:"-" uri:"id"`
+}
@@ -0,0 +1,33 @@
+package poll
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/tally"
+)
+
+type CreatePollResp struct {
+	*dto.Resp
+	*PollVO
+}
+
+type GetPollResp struct {
+	*dto.Resp
+	*PollVO
+}
+
+type CastBallotResp struct {
+	*dto.Resp
+	*BallotVO
+}
+
+type GetMyBallotResp struct {
+	*dto.Resp
+	*BallotVO
+}
+
+// GetPollResultResp 计票结果, 截止前为实时结果
+type GetPollResultResp struct {
+	*dto.Resp
+	Final  bool          `json:"final"`
+	Result *tally.Result `json:"result"`
+}
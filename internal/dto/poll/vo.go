@@ -0,0 +1,28 @@
+package poll
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type PollVO struct {
+	ID          bson.ObjectID `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	CreatorID   bson.ObjectID `json:"creatorId"`
+	Options     []string      `json:"options"`
+	Strategy    string        `json:"strategy"`
+	Credits     int64         `json:"credits,omitempty"`
+	Deadline    time.Time     `json:"deadline"`
+	Closed      bool          `json:"closed"`
+	CreatedAt   time.Time     `json:"createdAt"`
+}
+
+type BallotVO struct {
+	PollID    bson.ObjectID    `json:"pollId"`
+	Choices   []string         `json:"choices,omitempty"`
+	Credits   map[string]int64 `json:"credits,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
@@ -0,0 +1,54 @@
+package proposal
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+)
+
+// CreateProposalReq 创建提案草稿, VotingPeriod 为 0 时使用默认投票期
+type CreateProposalReq struct {
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	VotingPeriod int64  `json:"votingPeriod"`
+}
+
+type GetProposalReq struct {
+	ID string `json:"-" uri:"id"`
+}
+
+// UpdateProposalReq 修改草稿, 空字段不修改
+type UpdateProposalReq struct {
+	ID           string `json:"-" uri:"id"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	VotingPeriod int64  `json:"votingPeriod"`
+}
+
+// ActivateProposalReq 草稿 -> 投票中
+type ActivateProposalReq struct {
+	ID string `json:"-" uri:"id"`
+}
+
+// CloseProposalReq 投票中 -> 已通过/未通过
+type CloseProposalReq struct {
+	ID      string `json:"-" uri:"id"`
+	Outcome string `json:"outcome"` // 已通过、未通过
+	Reason  string `json:"reason"`
+}
+
+// ExecuteProposalReq 已通过 -> 已执行
+type ExecuteProposalReq struct {
+	ID     string `json:"-" uri:"id"`
+	Reason string `json:"reason"`
+}
+
+// CancelProposalReq 取消未结束的提案
+type CancelProposalReq struct {
+	ID     string `json:"-" uri:"id"`
+	Reason string `json:"reason"`
+}
+
+type ListProposalsReq struct {
+	dto.PageParam
+	Status     string `form:"status"`
+	ProposerID string `form:"proposerId"`
+}
@@ -0,0 +1,32 @@
+package proposal
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+)
+
+type CreateProposalResp struct {
+	*dto.Resp
+	*ProposalVO
+}
+
+type GetProposalResp struct {
+	*dto.Resp
+	*ProposalVO
+}
+
+type UpdateProposalResp struct {
+	*dto.Resp
+	*ProposalVO
+}
+
+// TransitionProposalResp 状态流转后的提案
+type TransitionProposalResp struct {
+	*dto.Resp
+	*ProposalVO
+}
+
+type ListProposalsResp struct {
+	*dto.Resp
+	Total     int64         `json:"total"`
+	Proposals []*ProposalVO `json:"proposals"`
+}
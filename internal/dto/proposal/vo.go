@@ -0,0 +1,29 @@
+package proposal
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ProposalVO struct {
+	ID            bson.ObjectID   `json:"id"`
+	Title         string          `json:"title"`
+	Description   string          `json:"description"`
+	ProposerID    bson.ObjectID   `json:"proposerId"`
+	Status        string          `json:"status"`
+	VotingPeriod  int64           `json:"votingPeriod"`
+	VotingStartAt *time.Time      `json:"votingStartAt,omitempty"`
+	VotingEndAt   *time.Time      `json:"votingEndAt,omitempty"`
+	Transitions   []*TransitionVO `json:"transitions"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+}
+
+type TransitionVO struct {
+	From    string        `json:"from,omitempty"`
+	To      string        `json:"to"`
+	ActorID bson.ObjectID `json:"actorId"`
+	Reason  string        `json:"reason,omitempty"`
+	At      time.Time     `json:"at"`
+}
@@ -1,31 +1,153 @@
 package user
 
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+)
+
 type RegisterReq struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	Password   string `json:"password" log:"redact"`
+	InviteCode string `json:"inviteCode"`
 }
 
 type LoginReq struct {
 	Email    string `json:"email"`
-	Password string `json:"password"`
+	Password string `json:"password" log:"redact"`
+}
+
+type LoginTwoFactorReq struct {
+	MFAToken     string `json:"mfaToken" log:"redact"`
+	Code         string `json:"code" log:"redact"`
+	RecoveryCode string `json:"recoveryCode" log:"redact"`
+}
+
+type SIWELoginReq struct {
+	Message    string `json:"message"`
+	Signature  string `json:"signature"`
+	InviteCode string `json:"inviteCode"` // 地址首次登录自动注册时使用
+}
+
+type WalletChallengeReq struct {
+	Address string `json:"address"`
+}
+
+type AddWalletReq struct {
+	Address   string `json:"address"`
+	Signature string `json:"signature"`
+}
+
+type SetPrimaryWalletReq struct {
+	Address string `json:"-" uri:"address"`
+}
+
+type RemoveWalletReq struct {
+	Address string `json:"-" uri:"address"`
+}
+
+// CreateInviteCodeReq 生成邀请码, 零值使用成员邀请码的默认限制
+type CreateInviteCodeReq struct {
+	MaxUses   int64  `json:"maxUses"`
+	ExpiresIn int64  `json:"expiresIn"` // 有效期(秒)
+	Role      string `json:"role"`      // 预设角色, 需要 invite.manage 权限
+}
+
+type RevokeInviteCodeReq struct {
+	Code string `json:"-" uri:"code"`
+}
+
+type GetInviteTreeReq struct {
+	Depth int `form:"depth"` // 展开层数, 默认 3, 最多 5
+}
+
+type ConfirmTOTPReq struct {
+	Code string `json:"code" log:"redact"`
+}
+
+// DisableTOTPReq 关闭两步验证, 未设置密码的账号不填 Password, 改用已绑定钱包对确认消息的签名
+type DisableTOTPReq struct {
+	Password     string `json:"password" log:"redact"`
+	Code         string `json:"code" log:"redact"`
+	RecoveryCode string `json:"recoveryCode" log:"redact"`
+	Address      string `json:"address"`
+	Signature    string `json:"signature"`
 }
 
+type RegenerateRecoveryCodesReq struct {
+	Code string `json:"code" log:"redact"`
+}
+
+// ReauthProof 未设置密码的账号(钱包注册)确认敏感操作的凭证, 提供两步验证码或已绑定钱包对确认消息的签名
+type ReauthProof struct {
+	Code      string `json:"code" log:"redact"`
+	Address   string `json:"address"`
+	Signature string `json:"signature"`
+}
+
+// ChangePasswordReq 修改密码, 未设置密码的账号不填 OldPassword, 改用 ReauthProof 确认后设置密码
 type ChangePasswordReq struct {
-	OldPassword     string `json:"oldPassword"`
-	NewPassword     string `json:"newPassword"`
-	ConfirmPassword string `json:"confirmPassword"`
+	OldPassword     string `json:"oldPassword" log:"redact"`
+	NewPassword     string `json:"newPassword" log:"redact"`
+	ConfirmPassword string `json:"confirmPassword" log:"redact"`
+	ReauthProof
 }
 
 type DeleteAccountReq struct {
-	Password     string `json:"password"`
+	Password     string `json:"password" log:"redact"`
 	Confirmation string `json:"confirmation"`
+	ReauthProof
 }
 
 type UpdateMyProfileReq struct {
 	*UserVO
 }
 
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refreshToken" log:"redact"`
+}
+
+type VerifyEmailReq struct {
+	Token string `json:"token" log:"redact"`
+}
+
+type ForgotPasswordReq struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordReq struct {
+	Token           string `json:"token" log:"redact"`
+	NewPassword     string `json:"newPassword" log:"redact"`
+	ConfirmPassword string `json:"confirmPassword" log:"redact"`
+}
+
 type UpdateUserRoleReq struct {
 	Role string `json:"role"`
 }
+
+type DelegateReq struct {
+	DelegateID string `json:"delegateId"`
+}
+
+type ListDelegatorsReq struct {
+	dto.PageParam
+}
+
+type ListSessionsReq struct {
+	dto.PageParam
+	Active bool `form:"active"` // 只返回未吊销且未过期的会话
+}
+
+type RevokeSessionReq struct {
+	SessionID string `json:"-" uri:"sessionId"`
+}
+
+type CreateAPIKeyReq struct {
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`     // read、write 或权限名, 权限名支持 "user.*" 形式的通配
+	ExpiresIn  int64    `json:"expiresIn"`  // 有效期(秒), 为 0 时使用默认有效期
+	AllowedIPs []string `json:"allowedIps"` // 允许的 IP 或 CIDR, 为空时不限制
+}
+
+type RevokeAPIKeyReq struct {
+	KeyID string `json:"-" uri:"keyId"`
+}
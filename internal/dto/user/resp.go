@@ -11,8 +11,21 @@ type RegisterResp struct {
 
 type LoginResp struct {
 	*dto.Resp
-	UserID      bson.ObjectID `json:"userId"`
-	AccessToken string        `json:"accessToken"`
+	UserID                 bson.ObjectID `json:"userId"`
+	AccessToken            string        `json:"accessToken" log:"redact"`
+	RefreshToken           string        `json:"refreshToken" log:"redact"`
+	ExpiresIn              int64         `json:"expiresIn"`
+	MFARequired            bool          `json:"mfaRequired,omitempty"`            // 需要提交两步验证码完成登录
+	MFAToken               string        `json:"mfaToken,omitempty" log:"redact"`  // 提交两步验证码时携带的短期令牌
+	TwoFactorSetupRequired bool          `json:"twoFactorSetupRequired,omitempty"` // 当前角色要求开启两步验证但尚未开启
+}
+
+type RefreshTokenResp struct {
+	*dto.Resp
+	UserID       bson.ObjectID `json:"userId"`
+	AccessToken  string        `json:"accessToken" log:"redact"`
+	RefreshToken string        `json:"refreshToken" log:"redact"`
+	ExpiresIn    int64         `json:"expiresIn"`
 }
 
 type GetMyProfileResp struct {
@@ -37,6 +50,157 @@ type LogoutResp struct {
 	*dto.Resp
 }
 
+type LogoutAllResp struct {
+	*dto.Resp
+}
+
 type UpdateUserRoleResp struct {
 	*dto.Resp
 }
+
+type VerifyEmailResp struct {
+	*dto.Resp
+}
+
+type ResendVerificationEmailResp struct {
+	*dto.Resp
+}
+
+type ForgotPasswordResp struct {
+	*dto.Resp
+}
+
+type ResetPasswordResp struct {
+	*dto.Resp
+}
+
+type SetupTOTPResp struct {
+	*dto.Resp
+	Secret string `json:"secret" log:"redact"`
+	URI    string `json:"uri" log:"redact"`
+}
+
+type ConfirmTOTPResp struct {
+	*dto.Resp
+	RecoveryCodes []string `json:"recoveryCodes" log:"redact"`
+}
+
+type DisableTOTPResp struct {
+	*dto.Resp
+}
+
+type RegenerateRecoveryCodesResp struct {
+	*dto.Resp
+	RecoveryCodes []string `json:"recoveryCodes" log:"redact"`
+}
+
+type SIWENonceResp struct {
+	*dto.Resp
+	Nonce     string `json:"nonce"`
+	ExpiresIn int64  `json:"expiresIn"`
+}
+
+type WalletChallengeResp struct {
+	*dto.Resp
+	Message   string `json:"message"`
+	ExpiresIn int64  `json:"expiresIn"`
+}
+
+type ReauthChallengeResp struct {
+	*dto.Resp
+	Message   string `json:"message"`
+	ExpiresIn int64  `json:"expiresIn"`
+}
+
+type AddWalletResp struct {
+	*dto.Resp
+	*WalletVO
+}
+
+type ListWalletsResp struct {
+	*dto.Resp
+	Wallets []*WalletVO `json:"wallets"`
+}
+
+type SetPrimaryWalletResp struct {
+	*dto.Resp
+}
+
+type RemoveWalletResp struct {
+	*dto.Resp
+}
+
+type CreateInviteCodeResp struct {
+	*dto.Resp
+	*InviteCodeVO
+}
+
+type ListInviteCodesResp struct {
+	*dto.Resp
+	InviteCodes []*InviteCodeVO `json:"inviteCodes"`
+}
+
+type RevokeInviteCodeResp struct {
+	*dto.Resp
+}
+
+type GetInviteTreeResp struct {
+	*dto.Resp
+	Stats     *InviteStatsVO  `json:"stats"`
+	Invitees  []*InviteNodeVO `json:"invitees"`
+	Truncated bool            `json:"truncated"` // 节点过多时只返回部分
+}
+
+type DelegateResp struct {
+	*dto.Resp
+	*DelegationVO
+}
+
+type RevokeDelegationResp struct {
+	*dto.Resp
+}
+
+// ListMyDelegationsResp 当前用户的委托与有效投票权
+type ListMyDelegationsResp struct {
+	*dto.Resp
+	Delegation      *DelegationVO `json:"delegation"`      // 未委托时为空
+	EffectiveWeight int64         `json:"effectiveWeight"` // 计入传递委托后的有效投票权
+	Delegators      int64         `json:"delegators"`      // 直接委托给自己的人数
+}
+
+type ListDelegatorsResp struct {
+	*dto.Resp
+	Total      int64          `json:"total"`
+	Delegators []*DelegatorVO `json:"delegators"`
+}
+
+type ListSessionsResp struct {
+	*dto.Resp
+	Total    int64        `json:"total"`
+	Sessions []*SessionVO `json:"sessions"`
+}
+
+type RevokeSessionResp struct {
+	*dto.Resp
+}
+
+type RevokeOtherSessionsResp struct {
+	*dto.Resp
+	Count int64 `json:"count"` // 被吊销的会话数
+}
+
+// CreateAPIKeyResp 密钥明文只在创建时返回一次
+type CreateAPIKeyResp struct {
+	*dto.Resp
+	Key string `json:"key" log:"redact"`
+	*APIKeyVO
+}
+
+type ListAPIKeysResp struct {
+	*dto.Resp
+	APIKeys []*APIKeyVO `json:"apiKeys"`
+}
+
+type RevokeAPIKeyResp struct {
+	*dto.Resp
+}
@@ -2,6 +2,8 @@ package user
 
 import (
 	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type UserVO struct {
@@ -17,6 +19,81 @@ type UserVO struct {
 	Gender      string    `json:"gender"`
 	Birthday    string    `json:"birthday"`
 	Bio         string    `json:"bio"`
+	TwoFactor   bool      `json:"twoFactor"`
 	LastLoginAt time.Time `json:"lastLoginAt"`
 	CreatedAt   time.Time `json:"createdAt"`
 }
+
+type WalletVO struct {
+	Address   string    `json:"address"`
+	Primary   bool      `json:"primary"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type InviteCodeVO struct {
+	Code      string     `json:"code"`
+	Role      string     `json:"role,omitempty"`
+	MaxUses   int64      `json:"maxUses"`
+	UsedCount int64      `json:"usedCount"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Revoked   bool       `json:"revoked"`
+	Usable    bool       `json:"usable"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// InviteStatsVO 邀请统计, 间接邀请按返回的层数统计
+type InviteStatsVO struct {
+	CodesCreated     int64 `json:"codesCreated"`
+	ActiveCodes      int64 `json:"activeCodes"`
+	Redemptions      int64 `json:"redemptions"`
+	DirectInvitees   int64 `json:"directInvitees"`
+	IndirectInvitees int64 `json:"indirectInvitees"`
+}
+
+type InviteNodeVO struct {
+	UserID   bson.ObjectID   `json:"userId"`
+	Username string          `json:"username"`
+	JoinedAt time.Time       `json:"joinedAt"`
+	Invitees []*InviteNodeVO `json:"invitees,omitempty"`
+}
+
+type DelegationVO struct {
+	DelegateID bson.ObjectID `json:"delegateId"`
+	Username   string        `json:"username"`
+	Active     bool          `json:"active"` // 受托人被暂停或封禁时委托不生效
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+type DelegatorVO struct {
+	UserID    bson.ObjectID `json:"userId"`
+	Username  string        `json:"username"`
+	Active    bool          `json:"active"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+type SessionVO struct {
+	ID         bson.ObjectID `json:"id"`
+	Device     string        `json:"device"`
+	UserAgent  string        `json:"userAgent"`
+	IP         string        `json:"ip"`
+	Current    bool          `json:"current"` // 是否为发起请求的会话
+	Active     bool          `json:"active"`
+	RevokedAt  *time.Time    `json:"revokedAt,omitempty"`
+	ExpiresAt  time.Time     `json:"expiresAt"`
+	LastSeenAt time.Time     `json:"lastSeenAt"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+type APIKeyVO struct {
+	ID         bson.ObjectID `json:"id"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	Scopes     []string      `json:"scopes"`
+	AllowedIPs []string      `json:"allowedIps"`
+	ExpiresAt  time.Time     `json:"expiresAt"`
+	Revoked    bool          `json:"revoked"`
+	Active     bool          `json:"active"`
+	LastUsedAt *time.Time    `json:"lastUsedAt,omitempty"`
+	LastUsedIP string        `json:"lastUsedIp,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
@@ -7,6 +7,7 @@ package errorx
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
 )
@@ -36,6 +37,42 @@ func (e Errorx) Error() string {
 	return fmt.Sprintf("code=%d, msg=%s", e.Code, e.Msg)
 }
 
+// As 判断 err 是否为 Errorx, 兼容值与指针两种形式
+func As(err error) (*Errorx, bool) {
+	var p *Errorx
+	if errors.As(err, &p) && p != nil {
+		return p, true
+	}
+	var v Errorx
+	if errors.As(err, &v) {
+		return &v, true
+	}
+	return nil, false
+}
+
+// RetryAfterError 需要客户端等待一段时间后重试的 Errorx
+// PostProcess 与 Abort 会据此设置 Retry-After 响应头, 并在响应体中返回 retryAfter(秒)
+type RetryAfterError struct {
+	*Errorx
+	After time.Duration
+}
+
+func WithRetryAfter(err *Errorx, after time.Duration) *RetryAfterError {
+	return &RetryAfterError{
+		Errorx: err,
+		After:  after,
+	}
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Errorx
+}
+
+// Seconds 返回向上取整的等待秒数, 至少为 1
+func (e *RetryAfterError) Seconds() int64 {
+	return max(int64((e.After+time.Second-1)/time.Second), 1)
+}
+
 // EndE 的作用是记录错误日志, 并返回一个与err相同的Errorx
 func EndE(err error) error {
 	log.Error("error: ", err)
@@ -71,4 +108,142 @@ var (
 	ErrConfirmationNotMatch        = New(1008, "确认信息不匹配")
 	ErrBirthdayFormatInvalid       = New(1009, "生日格式无效")
 	ErrUserPermissionsInsufficient = New(1010, "用户权限不足")
+	ErrTokenInvalid                = New(1011, "令牌无效")
+	ErrRefreshTokenInvalid         = New(1012, "刷新令牌无效或已过期")
+	ErrRefreshTokenReused          = New(1013, "刷新令牌已被使用, 请重新登录")
+	ErrTokenMissing                = New(1014, "缺少认证令牌")
+	ErrTokenExpired                = New(1015, "令牌已过期")
+	ErrUserRoleInvalid             = New(1016, "角色不存在")
+	ErrUserNotFound                = New(1017, "用户不存在")
+	ErrUserSuspended               = New(1018, "账号已被暂停")
+	ErrUserBanned                  = New(1019, "账号已被封禁")
+	ErrUserIDFormatInvalid         = New(1020, "用户ID格式无效")
+	ErrSuspendUntilInvalid         = New(1021, "暂停结束时间无效")
+	ErrCannotModifySelf            = New(1022, "不能对自己执行该操作")
+	ErrUserStatusInvalid           = New(1023, "用户状态不存在")
+	ErrUserGenderInvalid           = New(1024, "性别不存在")
+	ErrSortFieldInvalid            = New(1025, "排序字段无效")
+	ErrCursorInvalid               = New(1026, "分页游标无效")
+	ErrExportFormatInvalid         = New(1027, "导出格式无效")
+	ErrEmailVerifyTokenInvalid     = New(1028, "邮箱验证链接无效或已过期")
+	ErrEmailAlreadyVerified        = New(1029, "邮箱已验证")
+	ErrEmailSendTooFrequent        = New(1030, "邮件发送过于频繁, 请稍后再试")
+	ErrEmailNotVerified            = New(1031, "请先验证邮箱")
+	ErrPasswordResetTokenInvalid   = New(1032, "密码重置链接无效或已过期")
+	ErrPasswordResetTooFrequent    = New(1033, "请求过于频繁, 请稍后再试")
+	ErrTwoFactorCodeInvalid        = New(1034, "两步验证码错误")
+	ErrMFATokenInvalid             = New(1035, "二次验证已过期, 请重新登录")
+	ErrTwoFactorAlreadyEnabled     = New(1036, "已开启两步验证")
+	ErrTwoFactorNotEnabled         = New(1037, "未开启两步验证")
+	ErrTwoFactorNotSetup           = New(1038, "请先生成两步验证密钥")
+	ErrTwoFactorRequired           = New(1039, "当前角色必须开启两步验证")
+	ErrSIWEDisabled                = New(1040, "未启用钱包登录")
+	ErrSIWEMessageInvalid          = New(1041, "签名消息无效")
+	ErrSIWEMessageExpired          = New(1042, "签名消息已过期")
+	ErrSIWENonceInvalid            = New(1043, "签名消息的 nonce 无效或已被使用")
+	ErrSIWESignatureInvalid        = New(1044, "钱包签名无效")
+	ErrWalletAddressInvalid        = New(1045, "钱包地址格式无效")
+	ErrWalletAlreadyLinked         = New(1046, "该钱包已绑定账号")
+	ErrWalletNotFound              = New(1047, "钱包不存在")
+	ErrWalletChallengeInvalid      = New(1048, "钱包验证已过期, 请重新获取签名消息")
+	ErrWalletLastLoginMethod       = New(1049, "不能移除账号唯一的登录方式")
+	ErrInviteCodeRequired          = New(1050, "注册需要邀请码")
+	ErrInviteCodeInvalid           = New(1051, "邀请码无效、已过期或已用完")
+	ErrInviteCodeLimitExceeded     = New(1052, "可用的邀请码数量已达上限")
+	ErrInviteParamInvalid          = New(1053, "邀请码参数无效")
+	ErrInviteCodeNotFound          = New(1054, "邀请码不存在")
+	ErrLoginLocked                 = New(1055, "登录失败次数过多, 账号已被临时锁定")
+	ErrLoginIPLocked               = New(1056, "当前网络登录失败次数过多, 请稍后再试")
+	ErrIPInvalid                   = New(1057, "IP 地址格式无效")
+	ErrSessionIDInvalid            = New(1058, "会话ID无效")
+	ErrSessionNotFound             = New(1059, "会话不存在")
+	ErrAPIKeyInvalid               = New(1060, "API Key 无效、已过期或已被吊销")
+	ErrAPIKeyIPNotAllowed          = New(1061, "当前 IP 不在该 API Key 的允许列表中")
+	ErrAPIKeyScopeInsufficient     = New(1062, "API Key 的权限范围不足")
+	ErrAPIKeyNotAllowed            = New(1063, "该接口不支持使用 API Key 访问")
+	ErrAPIKeyParamInvalid          = New(1064, "API Key 参数无效")
+	ErrAPIKeyLimitExceeded         = New(1065, "可用的 API Key 数量已达上限")
+	ErrAPIKeyIDInvalid             = New(1066, "API Key ID无效")
+	ErrAPIKeyNotFound              = New(1067, "API Key 不存在")
+	ErrTargetRoleNotLower          = New(1068, "不能操作角色不低于自己的用户")
+	ErrEmailVerifyDisabled         = New(1069, "未启用邮箱验证")
+	ErrTwoFactorLocked             = New(1070, "两步验证失败次数过多, 账号已被临时锁定")
+	ErrReauthRequired              = New(1071, "该账号未设置密码, 请提供两步验证码或已绑定钱包的签名")
+)
+
+// 组织相关
+var (
+	ErrOrganizationNotFound      = New(2001, "组织不存在")
+	ErrOrganizationSlugExisted   = New(2002, "组织标识已被使用")
+	ErrOrganizationSlugInvalid   = New(2003, "组织标识只能包含小写字母、数字和连字符, 长度为 3-40")
+	ErrOrganizationNameInvalid   = New(2004, "组织名称不能为空且不能超过 64 个字符")
+	ErrGovernanceInvalid         = New(2005, "治理参数无效")
+	ErrOrganizationArchived      = New(2006, "组织已归档")
+	ErrOrganizationStatusInvalid = New(2007, "组织状态不存在")
+)
+
+// 提案相关
+var (
+	ErrProposalNotFound         = New(3001, "提案不存在")
+	ErrProposalTitleInvalid     = New(3002, "提案标题不能为空且不能超过 128 个字符")
+	ErrProposalPeriodInvalid    = New(3003, "投票期无效")
+	ErrProposalTransitionDenied = New(3004, "提案当前状态不允许该操作")
+	ErrProposalVotingNotEnded   = New(3005, "投票尚未结束")
+	ErrProposalOutcomeInvalid   = New(3006, "投票结果只能是已通过或未通过")
+	ErrProposalStatusInvalid    = New(3007, "提案状态不存在")
+	ErrProposalCreateForbidden  = New(3008, "只有状态正常的用户可以发起提案")
+	ErrProposalIDInvalid        = New(3009, "提案ID无效")
+)
+
+// 投票相关
+var (
+	ErrPollNotFound            = New(4001, "投票不存在")
+	ErrPollIDInvalid           = New(4002, "投票ID无效")
+	ErrPollTitleInvalid        = New(4003, "投票标题不能为空且不能超过 128 个字符")
+	ErrPollOptionsInvalid      = New(4004, "投票需要 2-20 个不重复的选项, 每个选项不能超过 64 个字符")
+	ErrPollStrategyInvalid     = New(4005, "计票方式不存在")
+	ErrPollDeadlineInvalid     = New(4006, "截止时间无效")
+	ErrPollCreditsInvalid      = New(4007, "积分预算无效")
+	ErrPollClosed              = New(4008, "投票已截止")
+	ErrBallotInvalid           = New(4009, "选票不符合计票方式的要求")
+	ErrBallotNotFound          = New(4010, "尚未投票")
+	ErrPollStrategyUnsupported = New(4011, "投票不支持加权计票")
+)
+
+// 委托相关
+var (
+	ErrDelegationSelf         = New(5001, "不能委托给自己")
+	ErrDelegationCycle        = New(5002, "委托会形成循环")
+	ErrDelegateInactive       = New(5003, "受托人账号状态异常")
+	ErrDelegationNotFound     = New(5004, "当前没有委托")
+	ErrDelegationChainTooLong = New(5005, "委托链过长")
+	ErrDelegationBusy         = New(5006, "委托正在处理中, 请稍后再试")
+)
+
+// 快照相关
+var (
+	ErrSnapshotNotFound      = New(6001, "快照不存在")
+	ErrSnapshotIDInvalid     = New(6002, "快照ID无效")
+	ErrSnapshotNameInvalid   = New(6003, "快照名称不能为空且不能超过 64 个字符")
+	ErrSnapshotCorrupted     = New(6004, "快照内容与哈希不一致")
+	ErrSnapshotCutoffInvalid = New(6005, "截止时间不能晚于当前时间")
+)
+
+// 成员承诺相关
+var (
+	ErrMerkleRootNotFound      = New(7001, "尚未发布成员承诺")
+	ErrMerkleRootEmpty         = New(7002, "没有可承诺的成员")
+	ErrMerkleRootCorrupted     = New(7003, "成员承诺数据损坏")
+	ErrMembershipProofNotFound = New(7004, "最新的成员承诺中不包含当前用户")
+)
+
+// 审计相关
+var (
+	ErrAuditActorIDInvalid   = New(8001, "操作者ID无效")
+	ErrAuditTimeRangeInvalid = New(8002, "开始时间必须早于结束时间")
+)
+
+// 限流相关
+var (
+	ErrTooManyRequests = New(9001, "请求过于频繁, 请稍后再试")
 )
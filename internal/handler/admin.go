@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/admin"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/NoANameGroup/DAOld-Backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// SuspendUser .
+// @router /api/admin/users/:userId/suspend [POST]
+func SuspendUser(c *gin.Context) {
+	var err error
+	var req admin.SuspendUserReq
+	var resp *admin.SuspendUserResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	if err = setTargetID(c); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().AdminService.SuspendUser(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// BanUser .
+// @router /api/admin/users/:userId/ban [POST]
+func BanUser(c *gin.Context) {
+	var err error
+	var req admin.BanUserReq
+	var resp *admin.BanUserResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	if err = setTargetID(c); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().AdminService.BanUser(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// UnbanUser .
+// @router /api/admin/users/:userId/unban [POST]
+func UnbanUser(c *gin.Context) {
+	var err error
+	var resp *admin.UnbanUserResp
+
+	if err = setTargetID(c); err != nil {
+		response.PostProcess(c, nil, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().AdminService.UnbanUser(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// UnlockUser .
+// @router /api/admin/users/:userId/unlock [POST]
+func UnlockUser(c *gin.Context) {
+	var err error
+	var resp *admin.UnlockUserResp
+
+	if err = setTargetID(c); err != nil {
+		response.PostProcess(c, nil, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().LockoutService.UnlockUser(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// UnlockIP .
+// @router /api/admin/ips/:ip/unlock [POST]
+func UnlockIP(c *gin.Context) {
+	var err error
+	var req admin.UnlockIPReq
+	var resp *admin.UnlockIPResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().LockoutService.UnlockIP(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ListUsers .
+// @router /api/admin/users [GET]
+func ListUsers(c *gin.Context) {
+	var err error
+	var req admin.ListUsersReq
+	var resp *admin.ListUsersResp
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	if req.Format != "" && req.Format != service.ExportFormatJSON {
+		exportUsers(c, &req)
+		return
+	}
+
+	resp, err = provider.Get().AdminService.ListUsers(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// exportUsers 以附件形式流式导出用户, 需要 user.export 权限
+func exportUsers(c *gin.Context, req *admin.ListUsersReq) {
+	if principal, err := auth.GetPrincipal(c); err != nil || !provider.Get().Authorizer.Allows(principal, auth.PermUserExport) {
+		response.Abort(c, http.StatusForbidden, errorx.ErrUserPermissionsInsufficient)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if req.Format == service.ExportFormatJSONL {
+		contentType = "application/x-ndjson"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="users-%s.%s"`, time.Now().Format("20060102150405"), req.Format))
+	c.Status(http.StatusOK)
+
+	err := provider.Get().AdminService.ExportUsers(c, req, c.Writer)
+	if err != nil && !c.Writer.Written() {
+		// 尚未写出任何内容, 仍可以返回正常的错误响应
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		response.PostProcess(c, req, nil, err)
+	}
+}
+
+// setTargetID 解析路径中的 userId 并写入上下文
+func setTargetID(c *gin.Context) error {
+	targetId, err := bson.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		return errorx.ErrUserIDFormatInvalid
+	}
+	c.Set(consts.ContextTargetID, targetId)
+	return nil
+}
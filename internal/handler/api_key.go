@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPIKey .
+// @router /api/users/me/api-keys [POST]
+func CreateAPIKey(c *gin.Context) {
+	var err error
+	var req user.CreateAPIKeyReq
+	var resp *user.CreateAPIKeyResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().APIKeyService.CreateAPIKey(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ListMyAPIKeys .
+// @router /api/users/me/api-keys [GET]
+func ListMyAPIKeys(c *gin.Context) {
+	var err error
+	var resp *user.ListAPIKeysResp
+
+	resp, err = provider.Get().APIKeyService.ListMyAPIKeys(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// RevokeMyAPIKey .
+// @router /api/users/me/api-keys/:keyId [DELETE]
+func RevokeMyAPIKey(c *gin.Context) {
+	var err error
+	var req user.RevokeAPIKeyReq
+	var resp *user.RevokeAPIKeyResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().APIKeyService.RevokeMyAPIKey(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ListUserAPIKeys .
+// @router /api/admin/users/:userId/api-keys [GET]
+func ListUserAPIKeys(c *gin.Context) {
+	var err error
+	var resp *user.ListAPIKeysResp
+
+	if err = setTargetID(c); err != nil {
+		response.PostProcess(c, nil, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().APIKeyService.ListUserAPIKeys(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// RevokeAPIKey .
+// @router /api/admin/api-keys/:keyId [DELETE]
+func RevokeAPIKey(c *gin.Context) {
+	var err error
+	var req user.RevokeAPIKeyReq
+	var resp *user.RevokeAPIKeyResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().APIKeyService.RevokeAPIKey(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/admin"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ListAuditEvents .
+// @router /api/admin/audit-events [GET]
+func ListAuditEvents(c *gin.Context) {
+	var err error
+	var req admin.ListAuditEventsReq
+	var resp *admin.ListAuditEventsResp
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().AuditService.ListAuditEvents(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
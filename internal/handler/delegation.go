@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Delegate .
+// @router /api/users/me/delegations [POST]
+func Delegate(c *gin.Context) {
+	var err error
+	var req user.DelegateReq
+	var resp *user.DelegateResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().DelegationService.Delegate(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// RevokeDelegation .
+// @router /api/users/me/delegations [DELETE]
+func RevokeDelegation(c *gin.Context) {
+	var err error
+	var resp *user.RevokeDelegationResp
+
+	resp, err = provider.Get().DelegationService.RevokeDelegation(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// ListMyDelegations .
+// @router /api/users/me/delegations [GET]
+func ListMyDelegations(c *gin.Context) {
+	var err error
+	var resp *user.ListMyDelegationsResp
+
+	resp, err = provider.Get().DelegationService.ListMyDelegations(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// ListDelegators .
+// @router /api/users/me/delegations/incoming [GET]
+func ListDelegators(c *gin.Context) {
+	var err error
+	var req user.ListDelegatorsReq
+	var resp *user.ListDelegatorsResp
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().DelegationService.ListDelegators(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
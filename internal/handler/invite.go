@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateInviteCode .
+// @router /api/users/me/invites [POST]
+func CreateInviteCode(c *gin.Context) {
+	var err error
+	var req user.CreateInviteCodeReq
+	var resp *user.CreateInviteCodeResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().InviteService.CreateInviteCode(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ListMyInviteCodes .
+// @router /api/users/me/invites [GET]
+func ListMyInviteCodes(c *gin.Context) {
+	var err error
+	var resp *user.ListInviteCodesResp
+
+	resp, err = provider.Get().InviteService.ListMyInviteCodes(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// RevokeInviteCode .
+// @router /api/users/me/invites/:code [DELETE]
+func RevokeInviteCode(c *gin.Context) {
+	var err error
+	var req user.RevokeInviteCodeReq
+	var resp *user.RevokeInviteCodeResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().InviteService.RevokeInviteCode(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// GetMyInviteTree .
+// @router /api/users/me/invites/tree [GET]
+func GetMyInviteTree(c *gin.Context) {
+	var err error
+	var req user.GetInviteTreeReq
+	var resp *user.GetInviteTreeResp
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().InviteService.GetMyInviteTree(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// GetUserInviteTree .
+// @router /api/admin/users/:userId/invites/tree [GET]
+func GetUserInviteTree(c *gin.Context) {
+	var err error
+	var req user.GetInviteTreeReq
+	var resp *user.GetInviteTreeResp
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	if err = setTargetID(c); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().InviteService.GetUserInviteTree(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS 公开当前可用于验签的公钥, 格式遵循 RFC 7517, 不经过 PostProcess 包装
+// @router /.well-known/jwks.json [GET]
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, provider.Get().TokenManager.JWKS())
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/membership"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// PublishMerkleRoot .
+// @router /api/admin/membership/roots [POST]
+func PublishMerkleRoot(c *gin.Context) {
+	var err error
+	var resp *membership.PublishMerkleRootResp
+
+	resp, err = provider.Get().MembershipService.PublishMerkleRoot(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// GetMerkleRoot .
+// @router /api/membership/root [GET]
+func GetMerkleRoot(c *gin.Context) {
+	var err error
+	var resp *membership.GetMerkleRootResp
+
+	resp, err = provider.Get().MembershipService.GetMerkleRoot(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// GetMembershipProof .
+// @router /api/users/me/membership-proof [GET]
+func GetMembershipProof(c *gin.Context) {
+	var err error
+	var resp *membership.GetMembershipProofResp
+
+	resp, err = provider.Get().MembershipService.GetMembershipProof(c)
+	response.PostProcess(c, nil, resp, err)
+}
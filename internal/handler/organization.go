@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/organization"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateOrganization .
+// @router /api/organizations [POST]
+func CreateOrganization(c *gin.Context) {
+	var err error
+	var req organization.CreateOrganizationReq
+	var resp *organization.CreateOrganizationResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().OrganizationService.CreateOrganization(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// GetOrganization .
+// @router /api/organizations/:slug [GET]
+func GetOrganization(c *gin.Context) {
+	var err error
+	var req organization.GetOrganizationReq
+	var resp *organization.GetOrganizationResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().OrganizationService.GetOrganization(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// UpdateOrganization .
+// @router /api/organizations/:slug [PATCH]
+func UpdateOrganization(c *gin.Context) {
+	var err error
+	var req organization.UpdateOrganizationReq
+	var resp *organization.UpdateOrganizationResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().OrganizationService.UpdateOrganization(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ArchiveOrganization .
+// @router /api/organizations/:slug/archive [POST]
+func ArchiveOrganization(c *gin.Context) {
+	var err error
+	var req organization.ArchiveOrganizationReq
+	var resp *organization.ArchiveOrganizationResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().OrganizationService.ArchiveOrganization(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ListOrganizations .
+// @router /api/organizations [GET]
+func ListOrganizations(c *gin.Context) {
+	var err error
+	var req organization.ListOrganizationsReq
+	var resp *organization.ListOrganizationsResp
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().OrganizationService.ListOrganizations(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/poll"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// CreatePoll .
+// @router /api/polls [POST]
+func CreatePoll(c *gin.Context) {
+	var err error
+	var req poll.CreatePollReq
+	var resp *poll.CreatePollResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().PollService.CreatePoll(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// GetPoll .
+// @router /api/polls/:id [GET]
+func GetPoll(c *gin.Context) {
+	var err error
+	var req poll.GetPollReq
+	var resp *poll.GetPollResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().PollService.GetPoll(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// CastBallot .
+// @router /api/polls/:id/ballot [PUT]
+func CastBallot(c *gin.Context) {
+	var err error
+	var req poll.CastBallotReq
+	var resp *poll.CastBallotResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().PollService.CastBallot(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// GetMyBallot .
+// @router /api/polls/:id/ballot [GET]
+func GetMyBallot(c *gin.Context) {
+	var err error
+	var req poll.GetMyBallotReq
+	var resp *poll.GetMyBallotResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().PollService.GetMyBallot(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// GetPollResult .
+// @router /api/polls/:id/result [GET]
+func GetPollResult(c *gin.Context) {
+	var err error
+	var req poll.GetPollResultReq
+	var resp *poll.GetPollResultResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().PollService.GetPollResult(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
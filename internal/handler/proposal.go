@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/proposal"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateProposal .
+// @router /api/proposals [POST]
+func CreateProposal(c *gin.Context) {
+	var err error
+	var req proposal.CreateProposalReq
+	var resp *proposal.CreateProposalResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().ProposalService.CreateProposal(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// GetProposal .
+// @router /api/proposals/:id [GET]
+func GetProposal(c *gin.Context) {
+	var err error
+	var req proposal.GetProposalReq
+	var resp *proposal.GetProposalResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().ProposalService.GetProposal(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// UpdateProposal .
+// @router /api/proposals/:id [PATCH]
+func UpdateProposal(c *gin.Context) {
+	var err error
+	var req proposal.UpdateProposalReq
+	var resp *proposal.UpdateProposalResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().ProposalService.UpdateProposal(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ActivateProposal .
+// @router /api/proposals/:id/activate [POST]
+func ActivateProposal(c *gin.Context) {
+	var err error
+	var req proposal.ActivateProposalReq
+	var resp *proposal.TransitionProposalResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().ProposalService.ActivateProposal(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// CloseProposal .
+// @router /api/proposals/:id/close [POST]
+func CloseProposal(c *gin.Context) {
+	var err error
+	var req proposal.CloseProposalReq
+	var resp *proposal.TransitionProposalResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().ProposalService.CloseProposal(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ExecuteProposal .
+// @router /api/proposals/:id/execute [POST]
+func ExecuteProposal(c *gin.Context) {
+	var err error
+	var req proposal.ExecuteProposalReq
+	var resp *proposal.TransitionProposalResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().ProposalService.ExecuteProposal(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// CancelProposal .
+// @router /api/proposals/:id/cancel [POST]
+func CancelProposal(c *gin.Context) {
+	var err error
+	var req proposal.CancelProposalReq
+	var resp *proposal.TransitionProposalResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().ProposalService.CancelProposal(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ListProposals .
+// @router /api/proposals [GET]
+func ListProposals(c *gin.Context) {
+	var err error
+	var req proposal.ListProposalsReq
+	var resp *proposal.ListProposalsResp
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().ProposalService.ListProposals(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ListSessions .
+// @router /api/users/me/sessions [GET]
+func ListSessions(c *gin.Context) {
+	var err error
+	var req user.ListSessionsReq
+	var resp *user.ListSessionsResp
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().SessionService.ListSessions(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// RevokeSession .
+// @router /api/users/me/sessions/:sessionId [DELETE]
+func RevokeSession(c *gin.Context) {
+	var err error
+	var req user.RevokeSessionReq
+	var resp *user.RevokeSessionResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().SessionService.RevokeSession(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// RevokeOtherSessions .
+// @router /api/users/me/sessions [DELETE]
+func RevokeOtherSessions(c *gin.Context) {
+	var err error
+	var resp *user.RevokeOtherSessionsResp
+
+	resp, err = provider.Get().SessionService.RevokeOtherSessions(c)
+	response.PostProcess(c, nil, resp, err)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/admin"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSnapshot .
+// @router /api/admin/snapshots [POST]
+func CreateSnapshot(c *gin.Context) {
+	var err error
+	var req admin.CreateSnapshotReq
+	var resp *admin.CreateSnapshotResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().SnapshotService.CreateSnapshot(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ListSnapshots .
+// @router /api/admin/snapshots [GET]
+func ListSnapshots(c *gin.Context) {
+	var err error
+	var req admin.ListSnapshotsReq
+	var resp *admin.ListSnapshotsResp
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().SnapshotService.ListSnapshots(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// GetSnapshot .
+// @router /api/admin/snapshots/:id [GET]
+func GetSnapshot(c *gin.Context) {
+	var err error
+	var req admin.GetSnapshotReq
+	var resp *admin.GetSnapshotResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().SnapshotService.GetSnapshot(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ListSnapshotMembers .
+// @router /api/admin/snapshots/:id/members [GET]
+func ListSnapshotMembers(c *gin.Context) {
+	var err error
+	var req admin.ListSnapshotMembersReq
+	var resp *admin.ListSnapshotMembersResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().SnapshotService.ListSnapshotMembers(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// CheckSnapshotMember .
+// @router /api/admin/snapshots/:id/members/:userId [GET]
+func CheckSnapshotMember(c *gin.Context) {
+	var err error
+	var req admin.CheckSnapshotMemberReq
+	var resp *admin.CheckSnapshotMemberResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().SnapshotService.CheckSnapshotMember(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// DiffSnapshots .
+// @router /api/admin/snapshots/:id/diff [GET]
+func DiffSnapshots(c *gin.Context) {
+	var err error
+	var req admin.DiffSnapshotsReq
+	var resp *admin.DiffSnapshotsResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().SnapshotService.DiffSnapshots(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
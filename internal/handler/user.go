@@ -1,14 +1,10 @@
 package handler
 
 import (
-	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
 	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
-	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
-	"github.com/NoANameGroup/DAOld-Backend/internal/jwt"
 	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
 	"github.com/NoANameGroup/DAOld-Backend/internal/response"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 // Register .
@@ -43,13 +39,128 @@ func Login(c *gin.Context) {
 	response.PostProcess(c, &req, resp, err)
 }
 
+// LoginTwoFactor .
+// @router /api/users/login/2fa [POST]
+func LoginTwoFactor(c *gin.Context) {
+	var err error
+	var req user.LoginTwoFactorReq
+	var resp *user.LoginResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().UserService.LoginTwoFactor(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// SIWENonce .
+// @router /api/users/siwe/nonce [POST]
+func SIWENonce(c *gin.Context) {
+	var err error
+	var resp *user.SIWENonceResp
+
+	resp, err = provider.Get().SIWEService.Nonce(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// SIWELogin .
+// @router /api/users/siwe/login [POST]
+func SIWELogin(c *gin.Context) {
+	var err error
+	var req user.SIWELoginReq
+	var resp *user.LoginResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().SIWEService.Login(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// RefreshToken .
+// @router /api/users/token/refresh [POST]
+func RefreshToken(c *gin.Context) {
+	var err error
+	var req user.RefreshTokenReq
+	var resp *user.RefreshTokenResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().UserService.RefreshToken(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// VerifyEmail .
+// @router /api/users/email/verify [POST]
+func VerifyEmail(c *gin.Context) {
+	var err error
+	var req user.VerifyEmailReq
+	var resp *user.VerifyEmailResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().VerificationService.VerifyEmail(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ResendVerificationEmail .
+// @router /api/users/email/verify/resend [POST]
+func ResendVerificationEmail(c *gin.Context) {
+	var err error
+	var resp *user.ResendVerificationEmailResp
+
+	resp, err = provider.Get().VerificationService.ResendVerificationEmail(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// ForgotPassword .
+// @router /api/users/password/forgot [POST]
+func ForgotPassword(c *gin.Context) {
+	var err error
+	var req user.ForgotPasswordReq
+	var resp *user.ForgotPasswordResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().PasswordService.ForgotPassword(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ResetPassword .
+// @router /api/users/password/reset [POST]
+func ResetPassword(c *gin.Context) {
+	var err error
+	var req user.ResetPasswordReq
+	var resp *user.ResetPasswordResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().PasswordService.ResetPassword(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
 // GetMyProfile .
 // @router /api/users/me [GET]
 func GetMyProfile(c *gin.Context) {
 	var err error
 	var resp *user.GetMyProfileResp
 
-	c.Set(consts.ContextUserID, jwt.ExtractUserIDFromContext(c))
 	resp, err = provider.Get().UserService.GetMyProfile(c)
 	response.PostProcess(c, nil, resp, err)
 }
@@ -66,7 +177,6 @@ func UpdateMyProfile(c *gin.Context) {
 		return
 	}
 
-	c.Set(consts.ContextUserID, jwt.ExtractUserIDFromContext(c))
 	resp, err = provider.Get().UserService.UpdateMyProfile(c, &req)
 	response.PostProcess(c, &req, resp, err)
 }
@@ -83,7 +193,6 @@ func ChangePassword(c *gin.Context) {
 		return
 	}
 
-	c.Set(consts.ContextUserID, jwt.ExtractUserIDFromContext(c))
 	resp, err = provider.Get().UserService.ChangePassword(c, &req)
 	response.PostProcess(c, &req, resp, err)
 }
@@ -100,7 +209,6 @@ func DeleteAccount(c *gin.Context) {
 		return
 	}
 
-	c.Set(consts.ContextUserID, jwt.ExtractUserIDFromContext(c))
 	resp, err = provider.Get().UserService.DeleteAccount(c, &req)
 	response.PostProcess(c, &req, resp, err)
 }
@@ -111,7 +219,17 @@ func Logout(c *gin.Context) {
 	var err error
 	var resp *user.LogoutResp
 
-	resp, err = provider.Get().UserService.Logout()
+	resp, err = provider.Get().UserService.Logout(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// LogoutAll .
+// @router /api/users/logout/all [POST]
+func LogoutAll(c *gin.Context) {
+	var err error
+	var resp *user.LogoutAllResp
+
+	resp, err = provider.Get().UserService.LogoutAll(c)
 	response.PostProcess(c, nil, resp, err)
 }
 
@@ -127,14 +245,69 @@ func UpdateUserRole(c *gin.Context) {
 		return
 	}
 
-	targetId, err := bson.ObjectIDFromHex(c.Param("userId"))
-	if err != nil {
-		response.PostProcess(c, &req, resp, errorx.New(10007, "invalid user id format"))
+	if err = setTargetID(c); err != nil {
+		response.PostProcess(c, &req, resp, err)
 		return
 	}
 
-	c.Set(consts.ContextUserID, jwt.ExtractUserIDFromContext(c))
-	c.Set(consts.ContextTargetID, targetId)
 	resp, err = provider.Get().UserService.UpdateUserRole(c, &req)
 	response.PostProcess(c, &req, resp, err)
 }
+
+// SetupTOTP .
+// @router /api/users/me/2fa/totp [POST]
+func SetupTOTP(c *gin.Context) {
+	var err error
+	var resp *user.SetupTOTPResp
+
+	resp, err = provider.Get().TwoFactorService.SetupTOTP(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// ConfirmTOTP .
+// @router /api/users/me/2fa/totp/confirm [POST]
+func ConfirmTOTP(c *gin.Context) {
+	var err error
+	var req user.ConfirmTOTPReq
+	var resp *user.ConfirmTOTPResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().TwoFactorService.ConfirmTOTP(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// DisableTOTP .
+// @router /api/users/me/2fa/totp [DELETE]
+func DisableTOTP(c *gin.Context) {
+	var err error
+	var req user.DisableTOTPReq
+	var resp *user.DisableTOTPResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().TwoFactorService.DisableTOTP(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// RegenerateRecoveryCodes .
+// @router /api/users/me/2fa/recovery-codes [POST]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var err error
+	var req user.RegenerateRecoveryCodesReq
+	var resp *user.RegenerateRecoveryCodesResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().TwoFactorService.RegenerateRecoveryCodes(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
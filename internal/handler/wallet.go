@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// WalletChallenge .
+// @router /api/users/me/wallets/challenge [POST]
+func WalletChallenge(c *gin.Context) {
+	var err error
+	var req user.WalletChallengeReq
+	var resp *user.WalletChallengeResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().WalletService.Challenge(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// AddWallet .
+// @router /api/users/me/wallets [POST]
+func AddWallet(c *gin.Context) {
+	var err error
+	var req user.AddWalletReq
+	var resp *user.AddWalletResp
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().WalletService.AddWallet(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ListWallets .
+// @router /api/users/me/wallets [GET]
+func ListWallets(c *gin.Context) {
+	var err error
+	var resp *user.ListWalletsResp
+
+	resp, err = provider.Get().WalletService.ListWallets(c)
+	response.PostProcess(c, nil, resp, err)
+}
+
+// SetPrimaryWallet .
+// @router /api/users/me/wallets/:address/primary [POST]
+func SetPrimaryWallet(c *gin.Context) {
+	var err error
+	var req user.SetPrimaryWalletReq
+	var resp *user.SetPrimaryWalletResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().WalletService.SetPrimaryWallet(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// RemoveWallet .
+// @router /api/users/me/wallets/:address [DELETE]
+func RemoveWallet(c *gin.Context) {
+	var err error
+	var req user.RemoveWalletReq
+	var resp *user.RemoveWalletResp
+
+	if err = c.ShouldBindUri(&req); err != nil {
+		response.PostProcess(c, &req, resp, err)
+		return
+	}
+
+	resp, err = provider.Get().WalletService.RemoveWallet(c, &req)
+	response.PostProcess(c, &req, resp, err)
+}
+
+// ReauthChallenge .
+// @router /api/users/me/reauth/challenge [POST]
+func ReauthChallenge(c *gin.Context) {
+	var err error
+	var resp *user.ReauthChallengeResp
+
+	resp, err = provider.Get().WalletService.ReauthChallenge(c)
+	response.PostProcess(c, nil, resp, err)
+}
@@ -1,86 +1,138 @@
 package jwt
 
 import (
+	"context"
 	"errors"
-	"strings"
+	"fmt"
 	"time"
 
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
 	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
 	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
-	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
-// GenerateToken generates a JWT token for a given UserID.
-func GenerateToken(userId bson.ObjectID) (string, error) {
-	claims := jwt.MapClaims{
-		"userId": userId.Hex(),
-		"exp":    time.Now().Add(24 * time.Hour).Unix(),
-	}
-	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(consts.JWTSecret))
-	if err != nil {
-		log.Error("GenerateToken failed for user %s: %v", userId.Hex(), err)
-		return "", err
-	}
-	return tokenString, nil
+// ErrTokenRevoked is returned when a token has been revoked by logout.
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// Claims are the claims carried by a DAOld access token.
+// RegisteredClaims.ID is the unique token ID (jti) used for revocation.
+// FamilyID links the token to the refresh token family of the login it belongs to.
+// IssuedAtMs is the issue time in milliseconds, since iat only has second precision.
+type Claims struct {
+	UserID     string `json:"userId"`
+	FamilyID   string `json:"fid,omitempty"`
+	IssuedAtMs int64  `json:"iatMs,omitempty"`
+	jwt.RegisteredClaims
 }
 
-// ParseToken parses a JWT token and returns the claims.
-func ParseToken(tokenStr string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return []byte(consts.JWTSecret), nil
-	})
+// Manager issues, parses and revokes access tokens.
+type Manager struct {
+	store        kv.Store
+	accessExpire time.Duration
+	signingKey   *signingKey
+	verifyKeys   map[string]*verifyKey
+}
 
+func NewManager(config *config.Config, store kv.Store) (*Manager, error) {
+	signing, verify, err := loadKeys(config.Auth)
 	if err != nil {
-		log.Error("ParseToken error: %v", err)
+		log.Error("failed to load jwt keys: %v", err)
 		return nil, err
 	}
 
-	return token, nil
+	accessExpire := config.Auth.AccessExpire
+	if accessExpire <= 0 {
+		accessExpire = consts.DefaultAccessExpire
+	}
+	return &Manager{
+		store:        store,
+		accessExpire: time.Duration(accessExpire) * time.Second,
+		signingKey:   signing,
+		verifyKeys:   verify,
+	}, nil
+}
+
+// AccessExpire returns the lifetime of issued access tokens.
+func (m *Manager) AccessExpire() time.Duration {
+	return m.accessExpire
 }
 
-// ExtractUserID extracts the user ID from a JWT token.
-func ExtractUserID(tokenStr string) (bson.ObjectID, error) {
-	token, err := ParseToken(tokenStr)
-	if err != nil || !token.Valid {
-		return bson.NilObjectID, err
+// GenerateToken generates a JWT token for a given UserID within a refresh token family
+// and returns it together with its token ID.
+func (m *Manager) GenerateToken(userId bson.ObjectID, familyId string) (string, string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:     userId.Hex(),
+		FamilyID:   familyId,
+		IssuedAtMs: now.UnixMilli(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userId.Hex(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.accessExpire)),
+		},
+	}
+	token := jwt.NewWithClaims(m.signingKey.method, claims)
+	token.Header["kid"] = m.signingKey.kid
+	tokenString, err := token.SignedString(m.signingKey.private)
+	if err != nil {
+		log.Error("GenerateToken failed for user %s: %v", userId.Hex(), err)
+		return "", "", err
 	}
+	return tokenString, claims.ID, nil
+}
 
-	claims := token.Claims.(jwt.MapClaims)
-	userIdStr, ok := claims["userId"].(string)
-	if !ok {
-		err = errors.New("invalid userId in token")
-		log.Error("ExtractUserID error: %v", err)
-		return bson.NilObjectID, err
+// ParseToken parses a JWT token, rejects revoked tokens and returns the claims.
+func (m *Manager) ParseToken(ctx context.Context, tokenStr string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, m.keyFunc)
+	if err != nil {
+		log.CtxError(ctx, "ParseToken error: %v", err)
+		return nil, err
+	}
+	if !token.Valid || claims.ID == "" {
+		log.CtxError(ctx, "ParseToken error: invalid token")
+		return nil, jwt.ErrTokenMalformed
 	}
 
-	objectId, err := bson.ObjectIDFromHex(userIdStr)
+	revoked, err := m.isRevoked(ctx, claims)
 	if err != nil {
-		log.Error("ExtractUserID invalid ObjectID: %v", err)
-		return bson.NilObjectID, err
+		log.CtxError(ctx, "ParseToken failed to check revocation: %v", err)
+		return nil, err
+	}
+	if revoked {
+		log.CtxInfo(ctx, "ParseToken rejected revoked token %s", claims.ID)
+		return nil, ErrTokenRevoked
 	}
 
-	return objectId, nil
+	return claims, nil
 }
 
-// ExtractUserIDFromContext 从 gin.Context 中提取用户ID
-func ExtractUserIDFromContext(c *gin.Context) bson.ObjectID {
-	authHeader := c.GetHeader("Authorization")
-	if !strings.HasPrefix(authHeader, "Bearer ") {
-		err := errors.New("missing or malformed authorization header")
-		log.CtxError(c.Request.Context(), "ExtractUserIDFromContext: %v", err)
-		return bson.NilObjectID
+// keyFunc selects the verification key by the kid header and rejects algorithm mismatches.
+func (m *Manager) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.verifyKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown jwt key id %q", kid)
 	}
-
-	userId, err := ExtractUserID(strings.TrimPrefix(authHeader, "Bearer "))
-	if err != nil {
-		log.CtxInfo(c.Request.Context(), "Failed to extract user ID from token: %v", err)
-		return bson.NilObjectID
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
 	}
+	return key.public, nil
+}
 
-	return userId
+// JWKS returns the public keys currently accepted for verification.
+func (m *Manager) JWKS() *JWKSet {
+	set := &JWKSet{Keys: make([]JWK, 0, len(m.verifyKeys))}
+	set.Keys = append(set.Keys, toJWK(&m.signingKey.verifyKey))
+	for kid, key := range m.verifyKeys {
+		if kid != m.signingKey.kid {
+			set.Keys = append(set.Keys, toJWK(key))
+		}
+	}
+	return set
 }
@@ -0,0 +1,196 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// verifyKey is a public key accepted when verifying tokens.
+type verifyKey struct {
+	kid    string
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+// signingKey is the private key used to sign new tokens.
+type signingKey struct {
+	verifyKey
+	private crypto.PrivateKey
+}
+
+// loadKeys loads the active signing key and every verification key from config.
+// The active key is always accepted for verification; Auth.VerifyKeys keeps
+// retired keys valid until the tokens they signed have expired.
+func loadKeys(auth config.Auth) (*signingKey, map[string]*verifyKey, error) {
+	signing, err := loadSigningKey(auth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := map[string]*verifyKey{signing.kid: &signing.verifyKey}
+	for _, k := range auth.VerifyKeys {
+		key, err := loadVerifyKey(k.KeyID, k.Algorithm, k.PublicKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := keys[key.kid]; ok {
+			return nil, nil, fmt.Errorf("duplicate jwt key id %q", key.kid)
+		}
+		keys[key.kid] = key
+	}
+
+	return signing, keys, nil
+}
+
+func loadSigningKey(auth config.Auth) (*signingKey, error) {
+	privatePEM, err := readPEM(auth.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("read jwt private key: %w", err)
+	}
+
+	key := &signingKey{}
+	switch auth.Algorithm {
+	case AlgorithmRS256:
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("parse jwt rsa private key: %w", err)
+		}
+		key.method, key.private, key.public = jwt.SigningMethodRS256, private, &private.PublicKey
+	case AlgorithmEdDSA:
+		private, err := jwt.ParseEdPrivateKeyFromPEM(privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("parse jwt ed25519 private key: %w", err)
+		}
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, private, private.(ed25519.PrivateKey).Public()
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", auth.Algorithm)
+	}
+
+	// 若同时配置了公钥, 校验其与私钥匹配
+	if auth.PublicKey != "" {
+		public, err := loadVerifyKey(auth.KeyID, auth.Algorithm, auth.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		if !public.public.(interface{ Equal(crypto.PublicKey) bool }).Equal(key.public) {
+			return nil, fmt.Errorf("jwt public key does not match private key")
+		}
+	}
+
+	if key.kid = auth.KeyID; key.kid == "" {
+		key.kid = thumbprint(key.public)
+	}
+	return key, nil
+}
+
+func loadVerifyKey(kid, algorithm, publicKey string) (*verifyKey, error) {
+	publicPEM, err := readPEM(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("read jwt public key: %w", err)
+	}
+
+	key := &verifyKey{}
+	switch algorithm {
+	case AlgorithmRS256:
+		if key.public, err = jwt.ParseRSAPublicKeyFromPEM(publicPEM); err != nil {
+			return nil, fmt.Errorf("parse jwt rsa public key: %w", err)
+		}
+		key.method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		if key.public, err = jwt.ParseEdPublicKeyFromPEM(publicPEM); err != nil {
+			return nil, fmt.Errorf("parse jwt ed25519 public key: %w", err)
+		}
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", algorithm)
+	}
+
+	if key.kid = kid; key.kid == "" {
+		key.kid = thumbprint(key.public)
+	}
+	return key, nil
+}
+
+// readPEM accepts either an inline PEM block or a path to a PEM file.
+func readPEM(value string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return []byte(value), nil
+	}
+	if value == "" {
+		return nil, fmt.Errorf("key is not configured")
+	}
+	return os.ReadFile(value)
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func toJWK(key *verifyKey) JWK {
+	jwk := publicJWK(key.public)
+	jwk.Use = "sig"
+	jwk.Alg = key.method.Alg()
+	jwk.Kid = key.kid
+	return jwk
+}
+
+// publicJWK returns the required members of the JWK for a public key.
+func publicJWK(public crypto.PublicKey) JWK {
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k),
+		}
+	}
+	return JWK{}
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint, used as the default key ID.
+func thumbprint(public crypto.PublicKey) string {
+	jwk := publicJWK(public)
+	var members []byte
+	if jwk.Kty == "RSA" {
+		members, _ = json.Marshal(map[string]string{"e": jwk.E, "kty": jwk.Kty, "n": jwk.N})
+	} else {
+		members, _ = json.Marshal(map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X})
+	}
+	sum := sha256.Sum256(members)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,65 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &config.Config{Auth: config.Auth{
+		Algorithm:    AlgorithmEdDSA,
+		SecretKey:    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		AccessExpire: 60,
+	}}
+	m, err := NewManager(conf, kv.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestRevokeAllKeepsLaterTokensInSameSecond(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	userId := bson.NewObjectID()
+
+	before, _, err := m.GenerateToken(userId, "family")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if err = m.RevokeAll(ctx, userId); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	after, _, err := m.GenerateToken(userId, "family")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = m.ParseToken(ctx, before); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("token issued before RevokeAll: got %v, want ErrTokenRevoked", err)
+	}
+	if _, err = m.ParseToken(ctx, after); err != nil {
+		t.Fatalf("token issued after RevokeAll: %v", err)
+	}
+}
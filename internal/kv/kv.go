@@ -0,0 +1,45 @@
+package kv
+
+import (
+	"context"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// Store 是带过期时间的键值存储, 用于令牌吊销等需要跨实例共享的短期状态
+// 配置了 Redis 时使用 Redis, 否则退化为进程内存储(仅适用于单实例部署与测试)
+type Store interface {
+	// Get 获取键对应的值, 键不存在或已过期时 ok 为 false
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+	// Set 设置键值并指定过期时间
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// Del 删除键
+	Del(ctx context.Context, key string) error
+	// Incr 将计数加一并返回新值, 键不存在时创建并设置过期时间(固定窗口计数)
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// SetNX 键不存在时设置键值并指定过期时间, 返回是否设置成功
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// CompareAndDel 键的当前值等于 value 时删除, 返回是否删除
+	CompareAndDel(ctx context.Context, key, value string) (bool, error)
+}
+
+// NewStore 根据配置创建 Store
+func NewStore(config *config.Config) Store {
+	if config.Redis == nil || config.Redis.Host == "" {
+		log.Info("未配置 Redis, 使用内存键值存储")
+		return NewMemoryStore()
+	}
+	return NewRedisStore(redis.MustNewRedis(*config.Redis))
+}
+
+// ttlSeconds 将过期时间向上取整为秒, 至少为 1 秒
+func ttlSeconds(ttl time.Duration) int {
+	seconds := int((ttl + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,121 @@
+package kv
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sweepInterval 进程内存储清理过期键的间隔
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	value    string
+	expireAt time.Time
+}
+
+// MemoryStore 是 Store 的进程内实现
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:   make(map[string]memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return "", false, nil
+	}
+	if time.Now().After(entry.expireAt) {
+		delete(s.entries, key)
+		return "", false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	s.entries[key] = memoryEntry{value: value, expireAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Del(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	entry, ok := s.entries[key]
+	if !ok || now.After(entry.expireAt) {
+		entry = memoryEntry{value: "0", expireAt: now.Add(ttl)}
+	}
+	n, err := strconv.ParseInt(entry.value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	n++
+	entry.value = strconv.FormatInt(n, 10)
+	s.entries[key] = entry
+	return n, nil
+}
+
+func (s *MemoryStore) SetNX(_ context.Context, key, value string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	if entry, ok := s.entries[key]; ok && !now.After(entry.expireAt) {
+		return false, nil
+	}
+	s.entries[key] = memoryEntry{value: value, expireAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryStore) CompareAndDel(_ context.Context, key, value string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expireAt) || entry.value != value {
+		return false, nil
+	}
+	delete(s.entries, key)
+	return true, nil
+}
+
+// sweep 每隔 sweepInterval 清理一次已过期的键, 避免每次写入都遍历全部键, 调用方需持有锁
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if now.After(entry.expireAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package kv
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreSetNXCompareAndDel(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	if ok, _ := s.SetNX(ctx, "lock", "a", time.Minute); !ok {
+		t.Fatal("SetNX() on a free key = false")
+	}
+	if ok, _ := s.SetNX(ctx, "lock", "b", time.Minute); ok {
+		t.Fatal("SetNX() on a held key = true")
+	}
+
+	// 其他持有者的令牌不能删除锁
+	if ok, _ := s.CompareAndDel(ctx, "lock", "b"); ok {
+		t.Fatal("CompareAndDel() with another token = true")
+	}
+	if ok, _ := s.CompareAndDel(ctx, "lock", "a"); !ok {
+		t.Fatal("CompareAndDel() with the holder's token = false")
+	}
+	if _, ok, _ := s.Get(ctx, "lock"); ok {
+		t.Fatal("Get() after CompareAndDel() found the key")
+	}
+
+	// 过期后可以重新获取, 原持有者不能再删除
+	if ok, _ := s.SetNX(ctx, "lock", "a", time.Millisecond); !ok {
+		t.Fatal("SetNX() after release = false")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if ok, _ := s.SetNX(ctx, "lock", "b", time.Minute); !ok {
+		t.Fatal("SetNX() after expiry = false")
+	}
+	if ok, _ := s.CompareAndDel(ctx, "lock", "a"); ok {
+		t.Fatal("CompareAndDel() by the expired holder = true")
+	}
+	if value, ok, _ := s.Get(ctx, "lock"); !ok || value != "b" {
+		t.Fatalf("Get() = %q, %v, want b, true", value, ok)
+	}
+}
@@ -0,0 +1,81 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// incrScript 自增并在首次创建时设置过期时间, 保证两步操作的原子性
+const incrScript = `local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("EXPIRE", KEYS[1], ARGV[1])
+end
+return n`
+
+// compareAndDelScript 值匹配时才删除, 避免删除已被其他持有者重新设置的键
+const compareAndDelScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`
+
+// RedisStore 是 Store 的 Redis 实现
+type RedisStore struct {
+	rds *redis.Redis
+}
+
+func NewRedisStore(rds *redis.Redis) *RedisStore {
+	return &RedisStore{
+		rds: rds,
+	}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := s.rds.GetCtx(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+	if value == "" {
+		return "", false, nil
+	}
+	return value, true, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.rds.SetexCtx(ctx, key, value, ttlSeconds(ttl))
+}
+
+func (s *RedisStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	res, err := s.rds.EvalCtx(ctx, incrScript, []string{key}, ttlSeconds(ttl))
+	if err != nil {
+		return 0, err
+	}
+	n, ok := res.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected incr result %v", res)
+	}
+	return n, nil
+}
+
+func (s *RedisStore) Del(ctx context.Context, key string) error {
+	_, err := s.rds.DelCtx(ctx, key)
+	return err
+}
+
+func (s *RedisStore) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	return s.rds.SetnxExCtx(ctx, key, value, ttlSeconds(ttl))
+}
+
+func (s *RedisStore) CompareAndDel(ctx context.Context, key, value string) (bool, error) {
+	res, err := s.rds.EvalCtx(ctx, compareAndDelScript, []string{key}, value)
+	if err != nil {
+		return false, err
+	}
+	n, ok := res.(int64)
+	if !ok {
+		return false, fmt.Errorf("unexpected del result %v", res)
+	}
+	return n == 1, nil
+}
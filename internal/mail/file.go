@@ -0,0 +1,56 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/pkg/lib"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+)
+
+// FileSender 将邮件写入目录中的 .eml 文件, 用于本地开发
+type FileSender struct {
+	dir string
+}
+
+func NewFileSender(dir string) *FileSender {
+	if dir == "" {
+		dir = "mail"
+	}
+	return &FileSender{
+		dir: dir,
+	}
+}
+
+func (s *FileSender) Send(ctx context.Context, msg *Message) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		log.CtxError(ctx, "failed to create mail dir: %v", err)
+		return err
+	}
+
+	content := fmt.Sprintf("To: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n",
+		msg.To, encodeHeader(msg.Subject), time.Now().Format(time.RFC1123Z), msg.Body)
+	path := filepath.Join(s.dir, lib.NewUID()+".eml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		log.CtxError(ctx, "failed to write mail file: %v", err)
+		return err
+	}
+
+	log.CtxInfo(ctx, "mail to %s written to %s", msg.To, path)
+	return nil
+}
+
+// LogSender 将邮件输出到日志, 用于本地开发
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg *Message) error {
+	log.CtxInfo(ctx, "[mail] to=%s, subject=%s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
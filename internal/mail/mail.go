@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"context"
+	"mime"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+)
+
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+// Message 一封纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender 邮件发送器, 生产环境使用 SMTP, 本地开发可以使用文件或日志
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewSender 根据 Config.Mail.Driver 创建发送器, 未配置时输出到日志
+func NewSender(config *config.Config) Sender {
+	switch config.Mail.Driver {
+	case DriverSMTP:
+		return NewSMTPSender(config.Mail)
+	case DriverFile:
+		return NewFileSender(config.Mail.Dir)
+	default:
+		log.Info("未配置邮件发送, 邮件将输出到日志")
+		return NewLogSender()
+	}
+}
+
+// encodeHeader 对含非 ASCII 字符的邮件头做 RFC 2047 编码
+func encodeHeader(value string) string {
+	return mime.QEncoding.Encode("utf-8", value)
+}
@@ -0,0 +1,56 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+)
+
+// SMTPSender 通过 SMTP 服务器发送邮件, 服务器支持时使用 STARTTLS
+type SMTPSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPSender(conf config.Mail) *SMTPSender {
+	var auth smtp.Auth
+	if conf.Username != "" {
+		auth = smtp.PlainAuth("", conf.Username, conf.Password, conf.Host)
+	}
+	return &SMTPSender{
+		addr: net.JoinHostPort(conf.Host, strconv.Itoa(conf.Port)),
+		from: conf.From,
+		auth: auth,
+	}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, s.build(msg)); err != nil {
+		log.CtxError(ctx, "failed to send mail to %s: %v", msg.To, err)
+		return err
+	}
+	return nil
+}
+
+// build 构造 RFC 5322 格式的邮件内容
+func (s *SMTPSender) build(msg *Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", encodeHeader(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticate 校验请求携带的访问令牌或 API Key, 并将认证主体写入上下文
+// 令牌缺失、过期或无效时返回 401; API Key 的 scope 不允许当前请求方法时返回 403
+func Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := provider.Get().AuthService.Authenticate(c, c.GetHeader("Authorization"))
+		if err != nil {
+			response.Abort(c, authStatusCode(err), err)
+			return
+		}
+
+		if !methodAllowed(principal, c.Request.Method) {
+			response.Abort(c, http.StatusForbidden, errorx.ErrAPIKeyScopeInsufficient)
+			return
+		}
+
+		c.Set(consts.ContextPrincipal, principal)
+		c.Next()
+	}
+}
+
+// DenyAPIKey 拒绝使用 API Key 访问, 用于登录会话、两步验证与 API Key 管理等账号安全接口
+// 需在 Authenticate 之后使用
+func DenyAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := auth.GetPrincipal(c)
+		if err != nil {
+			response.Abort(c, http.StatusUnauthorized, errorx.ErrTokenMissing)
+			return
+		}
+
+		if principal.IsAPIKey() {
+			response.Abort(c, http.StatusForbidden, errorx.ErrAPIKeyNotAllowed)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail 要求当前用户已验证邮箱, 未启用邮箱验证时不做限制, 需在 Authenticate 之后使用
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := auth.GetPrincipal(c)
+		if err != nil {
+			response.Abort(c, http.StatusUnauthorized, errorx.ErrTokenMissing)
+			return
+		}
+
+		if !principal.EmailVerified && provider.Get().VerificationService.Enabled() {
+			response.Abort(c, http.StatusForbidden, errorx.ErrEmailNotVerified)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireTwoFactor 角色策略要求两步验证时, 要求当前用户已开启, 需在 Authenticate 之后使用
+func RequireTwoFactor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := auth.GetPrincipal(c)
+		if err != nil {
+			response.Abort(c, http.StatusUnauthorized, errorx.ErrTokenMissing)
+			return
+		}
+
+		if !principal.TwoFactor && provider.Get().Authorizer.RequiresTwoFactor(principal.Role) {
+			response.Abort(c, http.StatusForbidden, errorx.ErrTwoFactorRequired)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// methodAllowed 只读请求要求 read 或 write scope, 其余请求要求 write scope
+func methodAllowed(principal *auth.Principal, method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return principal.HasScope(auth.ScopeRead) || principal.HasScope(auth.ScopeWrite)
+	default:
+		return principal.HasScope(auth.ScopeWrite)
+	}
+}
+
+// authStatusCode 被暂停或封禁的账号与不在允许列表中的 IP 返回 403, 其余认证失败返回 401
+func authStatusCode(err error) int {
+	if ex, ok := errorx.As(err); ok {
+		switch ex.Code {
+		case errorx.ErrUserSuspended.Code, errorx.ErrUserBanned.Code, errorx.ErrAPIKeyIPNotAllowed.Code:
+			return http.StatusForbidden
+		}
+	}
+	return http.StatusUnauthorized
+}
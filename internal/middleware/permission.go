@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 要求当前用户的角色拥有全部给定权限, 使用 API Key 时还要求 scope 覆盖这些权限
+// 需在 Authenticate 之后使用
+func RequirePermission(perms ...auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := auth.GetPrincipal(c)
+		if err != nil {
+			response.Abort(c, http.StatusUnauthorized, errorx.ErrTokenMissing)
+			return
+		}
+
+		if !provider.Get().Authorizer.HasPermission(principal.Role, perms...) {
+			response.Abort(c, http.StatusForbidden, errorx.ErrUserPermissionsInsufficient)
+			return
+		}
+		if !principal.HasScope(perms...) {
+			response.Abort(c, http.StatusForbidden, errorx.ErrAPIKeyScopeInsufficient)
+			return
+		}
+
+		c.Next()
+	}
+}
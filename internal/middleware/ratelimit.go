@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/ratelimit"
+	"github.com/NoANameGroup/DAOld-Backend/internal/response"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/security"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// RateLimit 按名称引用的限流规则限制请求频率, 规则未启用时直接放行
+// 按用户计数的规则需在 Authenticate 之后使用, 未认证的请求按 IP 计数
+// 超限时返回 429, 并设置 Retry-After 与 RateLimit-* 响应头
+func RateLimit(rule string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, ok := provider.Get().RateLimiter.Get(rule)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		// 限流存储不可用时放行, 避免影响正常请求
+		result, err := policy.Allow(c, rateLimitKey(c, policy.KeyBy))
+		if err != nil {
+			log.CtxError(c, "rate limit %s failed: %v", rule, err)
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, policy, result)
+		if !result.Allowed {
+			response.Abort(c, http.StatusTooManyRequests, errorx.WithRetryAfter(errorx.ErrTooManyRequests, result.RetryAfter))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitKey 计数键, 取不到 API Key 时退化为用户, 取不到用户时退化为 IP
+func rateLimitKey(c *gin.Context, keyBy string) string {
+	switch keyBy {
+	case ratelimit.KeyByAPIKey:
+		if key, ok := strings.CutPrefix(c.GetHeader("Authorization"), "ApiKey "); ok && key != "" {
+			return "key:" + security.HashToken(key)
+		}
+		fallthrough
+	case ratelimit.KeyByUser:
+		if userId, err := auth.GetUserID(c); err == nil {
+			return "user:" + userId.Hex()
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// setRateLimitHeaders 多条规则同时生效时, 响应头保留剩余次数最少的一条
+func setRateLimitHeaders(c *gin.Context, policy *ratelimit.Policy, result *ratelimit.Result) {
+	header := c.Writer.Header()
+	if current := header.Get(HeaderRateLimitRemaining); current != "" {
+		if remaining, err := strconv.ParseInt(current, 10, 64); err == nil && remaining <= result.Remaining {
+			return
+		}
+	}
+
+	header.Set(HeaderRateLimitLimit, strconv.FormatInt(result.Limit, 10))
+	header.Set(HeaderRateLimitRemaining, strconv.FormatInt(result.Remaining, 10))
+	header.Set(HeaderRateLimitReset, strconv.FormatInt(int64(math.Ceil(result.Reset.Seconds())), 10))
+	header.Set(HeaderRateLimitPolicy, strconv.FormatInt(policy.Limit, 10)+";w="+strconv.FormatInt(policy.Period, 10))
+}
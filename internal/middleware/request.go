@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxRequestIDLen = 64
+
+// RequestContext 将请求ID、客户端 IP 与 User-Agent 写入上下文
+// 客户端传入合法的 X-Request-ID 时沿用, 否则生成新的请求ID, 并通过响应头返回
+func RequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(consts.HeaderRequestID)
+		if !isValidRequestID(requestId) {
+			requestId = uuid.NewString()
+		}
+		c.Header(consts.HeaderRequestID, requestId)
+
+		c.Set(consts.ContextRequestID, requestId)
+		c.Set(consts.ContextClientIP, c.ClientIP())
+		c.Set(consts.ContextUserAgent, c.Request.UserAgent())
+		c.Next()
+	}
+}
+
+// isValidRequestID 请求ID会写入日志与审计记录, 只接受有限长度的字母、数字与 -_.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// APIKey 个人 API Key, 只保存密钥的哈希, 明文只在创建时返回一次
+type APIKey struct {
+	ID         bson.ObjectID `bson:"_id"`
+	UserID     bson.ObjectID `bson:"userId"`
+	Name       string        `bson:"name"`
+	Prefix     string        `bson:"prefix"` // 密钥的开头几位, 用于辨认
+	KeyHash    string        `bson:"keyHash"`
+	Scopes     []string      `bson:"scopes"`
+	AllowedIPs []string      `bson:"allowedIps"` // 允许的 IP 或 CIDR, 为空时不限制
+	ExpiresAt  time.Time     `bson:"expiresAt"`
+	Revoked    bool          `bson:"revoked"`
+	RevokedAt  time.Time     `bson:"revokedAt"`
+	RevokedBy  bson.ObjectID `bson:"revokedBy"` // 吊销者, 可能是本人或管理员
+	LastUsedAt time.Time     `bson:"lastUsedAt"`
+	LastUsedIP string        `bson:"lastUsedIp"`
+	CreatedAt  time.Time     `bson:"createdAt"`
+}
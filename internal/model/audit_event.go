@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// AuditEvent 审计事件, 只追加不修改
+// Seq 从 1 开始连续递增, Hash 由 PrevHash 与事件内容计算, 形成哈希链
+type AuditEvent struct {
+	ID         bson.ObjectID `bson:"_id"`
+	Seq        int64         `bson:"seq"`
+	PrevHash   string        `bson:"prevHash"`
+	Hash       string        `bson:"hash"`
+	ActorID    bson.ObjectID `bson:"actorId"`
+	Action     string        `bson:"action"`
+	TargetType string        `bson:"targetType"`
+	TargetID   string        `bson:"targetId"`
+	Changes    []AuditChange `bson:"changes"`
+	IP         string        `bson:"ip"`
+	UserAgent  string        `bson:"userAgent"`
+	RequestID  string        `bson:"requestId"`
+	CreatedAt  time.Time     `bson:"createdAt"`
+}
+
+// AuditChange 单个字段的变更, 敏感字段只记录是否变更
+type AuditChange struct {
+	Field  string `bson:"field" json:"field"`
+	Before string `bson:"before" json:"before"`
+	After  string `bson:"after" json:"after"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Delegation 用户把自己的投票权委托给另一个用户, 每个用户同时只能委托给一人
+type Delegation struct {
+	ID          bson.ObjectID `bson:"_id"`
+	DelegatorID bson.ObjectID `bson:"delegatorId"` // 委托人
+	DelegateID  bson.ObjectID `bson:"delegateId"`  // 受托人
+	CreatedAt   time.Time     `bson:"createdAt"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// EmailVerification 邮箱验证令牌的发放记录, 用于保证令牌只能使用一次以及限制发送频率
+type EmailVerification struct {
+	ID        bson.ObjectID `bson:"_id"`
+	UserID    bson.ObjectID `bson:"userId"`
+	Email     string        `bson:"email"`
+	NonceHash string        `bson:"nonceHash"`
+	Used      bool          `bson:"used"`
+	UsedAt    time.Time     `bson:"usedAt"`
+	ExpiresAt time.Time     `bson:"expiresAt"`
+	CreatedAt time.Time     `bson:"createdAt"`
+}
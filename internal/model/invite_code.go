@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// InviteCode 邀请码, 由管理员或成员生成
+type InviteCode struct {
+	ID        bson.ObjectID `bson:"_id"`
+	Code      string        `bson:"code"`
+	CreatorID bson.ObjectID `bson:"creatorId"`
+	Role      enum.UserRole `bson:"role"`      // 使用邀请码注册后的角色, 零值表示普通用户
+	MaxUses   int64         `bson:"maxUses"`   // 最多可使用次数, 零值表示不限
+	UsedCount int64         `bson:"usedCount"` // 已使用次数
+	ExpiresAt time.Time     `bson:"expiresAt"` // 零值表示永不过期
+	Revoked   bool          `bson:"revoked"`
+	CreatedAt time.Time     `bson:"createdAt"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MerkleRoot 发布的成员承诺
+// Data 为按成员ID升序排列的叶子原文经 gzip 压缩的结果, 每条 32 字节: 12 字节成员ID + 20 字节主钱包地址(没有时全为 0)
+type MerkleRoot struct {
+	ID        bson.ObjectID `bson:"_id"`
+	Root      string        `bson:"root"`
+	LeafCount int64         `bson:"leafCount"`
+	Data      []byte        `bson:"data"`
+	CreatorID bson.ObjectID `bson:"creatorId"`
+	CreatedAt time.Time     `bson:"createdAt"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Organization 是平台上的一个 DAO
+type Organization struct {
+	ID          bson.ObjectID           `bson:"_id"`
+	Name        string                  `bson:"name"`
+	Slug        string                  `bson:"slug"` // 全局唯一, 用于 URL
+	Description string                  `bson:"description"`
+	Avatar      string                  `bson:"avatar"`
+	Governance  Governance              `bson:"governance"`
+	CreatorID   bson.ObjectID           `bson:"creatorId"`
+	Status      enum.OrganizationStatus `bson:"status"`
+	ArchivedAt  time.Time               `bson:"archivedAt"`
+	CreatedAt   time.Time               `bson:"createdAt"`
+	UpdatedAt   time.Time               `bson:"updatedAt"`
+}
+
+// Governance DAO 的治理参数
+type Governance struct {
+	VotingStrategy    string `bson:"votingStrategy"`    // 计票方式
+	VotingPeriod      int64  `bson:"votingPeriod"`      // 投票期(秒)
+	QuorumPercent     int    `bson:"quorumPercent"`     // 法定人数, 参与投票的成员占比(%)
+	PassPercent       int    `bson:"passPercent"`       // 通过所需的赞成票占比(%)
+	ProposalThreshold int64  `bson:"proposalThreshold"` // 发起提案所需的最低投票权
+}
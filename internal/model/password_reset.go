@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// PasswordReset 密码重置令牌, 只保存令牌的哈希, 使用一次后失效
+type PasswordReset struct {
+	ID        bson.ObjectID `bson:"_id"`
+	UserID    bson.ObjectID `bson:"userId"`
+	Email     string        `bson:"email"`
+	TokenHash string        `bson:"tokenHash"`
+	RequestIP string        `bson:"requestIp"`
+	Used      bool          `bson:"used"`
+	UsedAt    time.Time     `bson:"usedAt"`
+	ExpiresAt time.Time     `bson:"expiresAt"`
+	CreatedAt time.Time     `bson:"createdAt"`
+}
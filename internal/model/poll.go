@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Poll 投票, 计票方式见 pkg/tally
+type Poll struct {
+	ID          bson.ObjectID `bson:"_id"`
+	Title       string        `bson:"title"`
+	Description string        `bson:"description"`
+	CreatorID   bson.ObjectID `bson:"creatorId"`
+	Options     []string      `bson:"options"`
+	Strategy    string        `bson:"strategy"` // 计票方式
+	Credits     int64         `bson:"credits"`  // 二次方计票中每人可分配的积分
+	Deadline    time.Time     `bson:"deadline"` // 截止后不能再投票或改票
+	CreatedAt   time.Time     `bson:"createdAt"`
+	UpdatedAt   time.Time     `bson:"updatedAt"`
+}
+
+// PollBallot 一个用户在一次投票中的选票, 截止前可以修改
+type PollBallot struct {
+	ID        bson.ObjectID    `bson:"_id"`
+	PollID    bson.ObjectID    `bson:"pollId"`
+	VoterID   bson.ObjectID    `bson:"voterId"`
+	Choices   []string         `bson:"choices"`
+	Credits   map[string]int64 `bson:"credits"` // 二次方计票中分配给各选项的积分
+	CreatedAt time.Time        `bson:"createdAt"`
+	UpdatedAt time.Time        `bson:"updatedAt"`
+}
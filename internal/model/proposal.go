@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Proposal 平台治理提案
+type Proposal struct {
+	ID            bson.ObjectID        `bson:"_id"`
+	Title         string               `bson:"title"`
+	Description   string               `bson:"description"`
+	ProposerID    bson.ObjectID        `bson:"proposerId"`
+	Status        enum.ProposalStatus  `bson:"status"`
+	VotingPeriod  int64                `bson:"votingPeriod"`  // 投票期(秒), 激活时据此计算投票截止时间
+	VotingStartAt time.Time            `bson:"votingStartAt"` // 激活时间
+	VotingEndAt   time.Time            `bson:"votingEndAt"`   // 投票截止时间, 之后才能结算
+	Transitions   []ProposalTransition `bson:"transitions"`   // 状态流转记录, 按时间顺序追加
+	CreatedAt     time.Time            `bson:"createdAt"`
+	UpdatedAt     time.Time            `bson:"updatedAt"`
+}
+
+// ProposalTransition 一次状态流转
+type ProposalTransition struct {
+	From    enum.ProposalStatus `bson:"from"` // 创建时为 0
+	To      enum.ProposalStatus `bson:"to"`
+	ActorID bson.ObjectID       `bson:"actorId"`
+	Reason  string              `bson:"reason"`
+	At      time.Time           `bson:"at"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// RefreshToken 刷新令牌, 每次使用后轮换
+// 同一次登录派生出的刷新令牌属于同一个 FamilyID, 已使用的令牌被再次提交时吊销整个家族
+type RefreshToken struct {
+	ID        bson.ObjectID `bson:"_id"`
+	UserID    bson.ObjectID `bson:"userId"`
+	FamilyID  string        `bson:"familyId"`
+	TokenHash string        `bson:"tokenHash"`
+	Used      bool          `bson:"used"`
+	UsedAt    time.Time     `bson:"usedAt"`
+	Revoked   bool          `bson:"revoked"`
+	ExpiresAt time.Time     `bson:"expiresAt"`
+	CreatedAt time.Time     `bson:"createdAt"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Session 登录会话, 每次登录创建一条, 与该次登录的刷新令牌家族一一对应
+type Session struct {
+	ID         bson.ObjectID `bson:"_id"`
+	UserID     bson.ObjectID `bson:"userId"`
+	FamilyID   string        `bson:"familyId"`
+	TokenID    string        `bson:"tokenId"` // 最近一次签发的访问令牌 ID
+	Device     string        `bson:"device"`  // 由 User-Agent 解析出的浏览器与操作系统
+	UserAgent  string        `bson:"userAgent"`
+	IP         string        `bson:"ip"`
+	Revoked    bool          `bson:"revoked"`
+	RevokedAt  time.Time     `bson:"revokedAt"`
+	ExpiresAt  time.Time     `bson:"expiresAt"` // 刷新令牌的过期时间, 每次刷新后延长
+	LastSeenAt time.Time     `bson:"lastSeenAt"`
+	CreatedAt  time.Time     `bson:"createdAt"`
+}
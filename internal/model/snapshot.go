@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Snapshot 某一时刻符合条件的成员名单
+// Data 为按字节升序排列的成员ID(每个 12 字节)拼接后经 gzip 压缩的结果, ContentHash 是压缩前内容的 SHA-256
+type Snapshot struct {
+	ID          bson.ObjectID  `bson:"_id"`
+	Name        string         `bson:"name"`
+	Filter      SnapshotFilter `bson:"filter"`
+	MemberCount int64          `bson:"memberCount"`
+	ContentHash string         `bson:"contentHash"`
+	Data        []byte         `bson:"data"`
+	CreatorID   bson.ObjectID  `bson:"creatorId"`
+	CreatedAt   time.Time      `bson:"createdAt"` // 快照时间
+}
+
+// SnapshotFilter 快照的成员条件, 零值字段不参与过滤
+type SnapshotFilter struct {
+	Status           enum.UserStatus `bson:"status"`
+	Role             enum.UserRole   `bson:"role"`
+	RegisteredBefore time.Time       `bson:"registeredBefore"` // 在此之前注册的用户
+}
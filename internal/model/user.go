@@ -8,21 +8,31 @@ import (
 )
 
 type User struct {
-	ID          bson.ObjectID   `bson:"_id"`
-	Username    string          `bson:"username"`
-	FirstName   string          `bson:"firstName"`
-	LastName    string          `bson:"lastName"`
-	Email       string          `bson:"email"`
-	Password    string          `bson:"password"`
-	Phone       string          `bson:"phone"`
-	Avatar      string          `bson:"avatar"`
-	Address     string          `bson:"address"`
-	Role        enum.UserRole   `bson:"role"`
-	Status      enum.UserStatus `bson:"status"`
-	Gender      enum.UserGender `bson:"gender"`
-	Birthday    time.Time       `bson:"birthday"`
-	Bio         string          `bson:"bio"`
-	LastLoginAt time.Time       `bson:"lastLoginAt"`
-	CreatedAt   time.Time       `bson:"createdAt"`
-	UpdatedAt   time.Time       `bson:"updatedAt"`
+	ID              bson.ObjectID   `bson:"_id"`
+	Username        string          `bson:"username"`
+	FirstName       string          `bson:"firstName"`
+	LastName        string          `bson:"lastName"`
+	Email           string          `bson:"email"`
+	EmailUnverified bool            `bson:"emailUnverified"` // 新注册账号在验证邮箱前为 true, 历史账号缺省视为已验证
+	Password        string          `bson:"password"`
+	Phone           string          `bson:"phone"`
+	Avatar          string          `bson:"avatar"`
+	Address         string          `bson:"address"`
+	Role            enum.UserRole   `bson:"role"`
+	Status          enum.UserStatus `bson:"status"`
+	StatusReason    string          `bson:"statusReason"`   // 暂停或封禁的原因
+	SuspendedUntil  time.Time       `bson:"suspendedUntil"` // 暂停的结束时间
+	Gender          enum.UserGender `bson:"gender"`
+	Birthday        time.Time       `bson:"birthday"`
+	Bio             string          `bson:"bio"`
+	LastLoginAt     time.Time       `bson:"lastLoginAt"`
+	TOTPEnabled     bool            `bson:"totpEnabled"`       // 是否已开启 TOTP 两步验证
+	TOTPSecret      string          `bson:"totpSecret"`        // 已启用的 TOTP 密钥
+	TOTPPending     string          `bson:"totpPendingSecret"` // 已生成但尚未用首个验证码确认的密钥
+	TOTPLastStep    int64           `bson:"totpLastStep"`      // 最近一次通过校验的时间步, 用于拒绝验证码重放
+	RecoveryCodes   []string        `bson:"recoveryCodes"`     // 一次性恢复码的哈希
+	ReferredBy      bson.ObjectID   `bson:"referredBy"`        // 邀请人, 未使用邀请码注册时为零值
+	InviteCodeID    bson.ObjectID   `bson:"inviteCodeId"`      // 注册时使用的邀请码
+	CreatedAt       time.Time       `bson:"createdAt"`
+	UpdatedAt       time.Time       `bson:"updatedAt"`
 }
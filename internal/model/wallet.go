@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Wallet 用户绑定的区块链地址, 每个地址只能属于一个账号
+type Wallet struct {
+	ID        bson.ObjectID `bson:"_id"`
+	UserID    bson.ObjectID `bson:"userId"`
+	Address   string        `bson:"address"` // EIP-55 校验和形式
+	Primary   bool          `bson:"primary"`
+	CreatedAt time.Time     `bson:"createdAt"`
+}
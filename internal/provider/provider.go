@@ -3,7 +3,12 @@
 package provider
 
 import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
 	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/jwt"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
+	"github.com/NoANameGroup/DAOld-Backend/internal/mail"
+	"github.com/NoANameGroup/DAOld-Backend/internal/ratelimit"
 	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
 	"github.com/NoANameGroup/DAOld-Backend/internal/service"
 	"github.com/google/wire"
@@ -25,20 +30,83 @@ func Get() *Provider {
 
 // Provider 提供controller依赖的对象
 type Provider struct {
-	Config      *config.Config
-	UserService service.UserService
+	Config              *config.Config
+	TokenManager        *jwt.Manager
+	Authorizer          *auth.Authorizer
+	RateLimiter         *ratelimit.Registry
+	AuthService         service.AuthService
+	UserService         service.UserService
+	AdminService        service.AdminService
+	VerificationService service.VerificationService
+	PasswordService     service.PasswordService
+	TwoFactorService    service.TwoFactorService
+	SIWEService         service.SIWEService
+	WalletService       service.WalletService
+	OrganizationService service.OrganizationService
+	InviteService       service.InviteService
+	ProposalService     service.ProposalService
+	PollService         service.PollService
+	DelegationService   service.DelegationService
+	SnapshotService     service.SnapshotService
+	MembershipService   service.MembershipService
+	AuditService        service.AuditService
+	LockoutService      service.LockoutService
+	SessionService      service.SessionService
+	APIKeyService       service.APIKeyService
 }
 
 var ServiceSet = wire.NewSet(
+	service.AuthServiceSet,
 	service.UserServiceSet,
+	service.AdminServiceSet,
+	service.VerificationServiceSet,
+	service.PasswordServiceSet,
+	service.TwoFactorServiceSet,
+	service.SIWEServiceSet,
+	service.WalletServiceSet,
+	service.OrganizationServiceSet,
+	service.InviteServiceSet,
+	service.ProposalServiceSet,
+	service.PollServiceSet,
+	service.DelegationServiceSet,
+	service.SnapshotServiceSet,
+	service.MembershipServiceSet,
+	service.AuditServiceSet,
+	service.LockoutServiceSet,
+	service.SessionServiceSet,
+	service.APIKeyServiceSet,
 )
 
 var RepositorySet = wire.NewSet(
 	config.NewConfig,
 	repository.NewUserRepository,
+	repository.NewRefreshTokenRepository,
+	repository.NewEmailVerificationRepository,
+	repository.NewPasswordResetRepository,
+	repository.NewWalletRepository,
+	repository.NewOrganizationRepository,
+	repository.NewInviteCodeRepository,
+	repository.NewProposalRepository,
+	repository.NewPollRepository,
+	repository.NewPollBallotRepository,
+	repository.NewDelegationRepository,
+	repository.NewSnapshotRepository,
+	repository.NewMerkleRootRepository,
+	repository.NewAuditEventRepository,
+	repository.NewSessionRepository,
+	repository.NewAPIKeyRepository,
+)
+
+var ComponentSet = wire.NewSet(
+	kv.NewStore,
+	jwt.NewManager,
+	auth.NewAuthorizer,
+	mail.NewSender,
+	ratelimit.NewRegistry,
 )
 
 var AllProvider = wire.NewSet(
 	ServiceSet,
 	RepositorySet,
+	ComponentSet,
 )
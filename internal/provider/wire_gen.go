@@ -7,7 +7,12 @@
 package provider
 
 import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
 	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/jwt"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
+	"github.com/NoANameGroup/DAOld-Backend/internal/mail"
+	"github.com/NoANameGroup/DAOld-Backend/internal/ratelimit"
 	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
 	"github.com/NoANameGroup/DAOld-Backend/internal/service"
 )
@@ -19,13 +24,249 @@ func NewProvider() (*Provider, error) {
 	if err != nil {
 		return nil, err
 	}
+	store := kv.NewStore(configConfig)
+	manager, err := jwt.NewManager(configConfig, store)
+	if err != nil {
+		return nil, err
+	}
+	authorizer := auth.NewAuthorizer(configConfig)
+	registry := ratelimit.NewRegistry(configConfig)
 	userRepository := repository.NewUserRepository(configConfig)
+	sessionRepository := repository.NewSessionRepository(configConfig)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(configConfig)
+	sender := mail.NewSender(configConfig)
+	sessionService := &service.SessionService{
+		SessionRepository:      sessionRepository,
+		RefreshTokenRepository: refreshTokenRepository,
+		TokenManager:           manager,
+		MailSender:             sender,
+		Store:                  store,
+	}
+	apiKeyRepository := repository.NewAPIKeyRepository(configConfig)
+	auditEventRepository := repository.NewAuditEventRepository(configConfig)
+	auditService := &service.AuditService{
+		AuditEventRepository: auditEventRepository,
+	}
+	apiKeyService := &service.APIKeyService{
+		Config:           configConfig,
+		APIKeyRepository: apiKeyRepository,
+		UserRepository:   userRepository,
+		Authorizer:       authorizer,
+		AuditService:     auditService,
+		Store:            store,
+	}
+	authService := service.AuthService{
+		UserRepository: userRepository,
+		TokenManager:   manager,
+		SessionService: sessionService,
+		APIKeyService:  apiKeyService,
+	}
+	walletRepository := repository.NewWalletRepository(configConfig)
+	delegationRepository := repository.NewDelegationRepository(configConfig)
+	emailVerificationRepository := repository.NewEmailVerificationRepository(configConfig)
+	verificationService := &service.VerificationService{
+		Config:                      configConfig,
+		UserRepository:              userRepository,
+		EmailVerificationRepository: emailVerificationRepository,
+		MailSender:                  sender,
+	}
+	lockoutService := &service.LockoutService{
+		Config:         configConfig,
+		Store:          store,
+		UserRepository: userRepository,
+		AuditService:   auditService,
+	}
+	walletService := &service.WalletService{
+		Config:           configConfig,
+		Store:            store,
+		UserRepository:   userRepository,
+		WalletRepository: walletRepository,
+	}
+	twoFactorService := &service.TwoFactorService{
+		Config:         configConfig,
+		UserRepository: userRepository,
+		Authorizer:     authorizer,
+		Store:          store,
+		LockoutService: lockoutService,
+		WalletService:  walletService,
+	}
+	inviteCodeRepository := repository.NewInviteCodeRepository(configConfig)
+	inviteService := &service.InviteService{
+		Config:               configConfig,
+		InviteCodeRepository: inviteCodeRepository,
+		UserRepository:       userRepository,
+		Authorizer:           authorizer,
+	}
 	userService := service.UserService{
+		Config:                 configConfig,
+		UserRepository:         userRepository,
+		RefreshTokenRepository: refreshTokenRepository,
+		SessionRepository:      sessionRepository,
+		WalletRepository:       walletRepository,
+		DelegationRepository:   delegationRepository,
+		APIKeyRepository:       apiKeyRepository,
+		TokenManager:           manager,
+		Authorizer:             authorizer,
+		VerificationService:    verificationService,
+		TwoFactorService:       twoFactorService,
+		InviteService:          inviteService,
+		AuditService:           auditService,
+		LockoutService:         lockoutService,
+		SessionService:         sessionService,
+	}
+	adminService := service.AdminService{
+		UserRepository:         userRepository,
+		RefreshTokenRepository: refreshTokenRepository,
+		SessionRepository:      sessionRepository,
+		TokenManager:           manager,
+		Authorizer:             authorizer,
+		AuditService:           auditService,
+	}
+	serviceVerificationService := service.VerificationService{
+		Config:                      configConfig,
+		UserRepository:              userRepository,
+		EmailVerificationRepository: emailVerificationRepository,
+		MailSender:                  sender,
+	}
+	passwordResetRepository := repository.NewPasswordResetRepository(configConfig)
+	passwordService := service.PasswordService{
+		Config:                  configConfig,
+		UserRepository:          userRepository,
+		RefreshTokenRepository:  refreshTokenRepository,
+		SessionRepository:       sessionRepository,
+		PasswordResetRepository: passwordResetRepository,
+		TokenManager:            manager,
+		MailSender:              sender,
+		Store:                   store,
+		AuditService:            auditService,
+		LockoutService:          lockoutService,
+	}
+	serviceTwoFactorService := service.TwoFactorService{
+		Config:         configConfig,
 		UserRepository: userRepository,
+		Authorizer:     authorizer,
+		Store:          store,
+		LockoutService: lockoutService,
+		WalletService:  walletService,
+	}
+	serviceUserService := &service.UserService{
+		Config:                 configConfig,
+		UserRepository:         userRepository,
+		RefreshTokenRepository: refreshTokenRepository,
+		SessionRepository:      sessionRepository,
+		WalletRepository:       walletRepository,
+		DelegationRepository:   delegationRepository,
+		APIKeyRepository:       apiKeyRepository,
+		TokenManager:           manager,
+		Authorizer:             authorizer,
+		VerificationService:    verificationService,
+		TwoFactorService:       twoFactorService,
+		InviteService:          inviteService,
+		AuditService:           auditService,
+		LockoutService:         lockoutService,
+		SessionService:         sessionService,
+	}
+	siweService := service.SIWEService{
+		Config:           configConfig,
+		Store:            store,
+		UserRepository:   userRepository,
+		WalletRepository: walletRepository,
+		WalletService:    walletService,
+		InviteService:    inviteService,
+		UserService:      serviceUserService,
+	}
+	serviceWalletService := service.WalletService{
+		Config:           configConfig,
+		Store:            store,
+		UserRepository:   userRepository,
+		WalletRepository: walletRepository,
+	}
+	organizationRepository := repository.NewOrganizationRepository(configConfig)
+	organizationService := service.OrganizationService{
+		OrganizationRepository: organizationRepository,
+		Authorizer:             authorizer,
+	}
+	serviceInviteService := service.InviteService{
+		Config:               configConfig,
+		InviteCodeRepository: inviteCodeRepository,
+		UserRepository:       userRepository,
+		Authorizer:           authorizer,
+	}
+	proposalRepository := repository.NewProposalRepository(configConfig)
+	proposalService := service.ProposalService{
+		ProposalRepository: proposalRepository,
+		Authorizer:         authorizer,
+	}
+	pollRepository := repository.NewPollRepository(configConfig)
+	pollBallotRepository := repository.NewPollBallotRepository(configConfig)
+	pollService := service.PollService{
+		PollRepository:       pollRepository,
+		PollBallotRepository: pollBallotRepository,
+	}
+	delegationService := service.DelegationService{
+		UserRepository:       userRepository,
+		DelegationRepository: delegationRepository,
+		Store:                store,
+	}
+	snapshotRepository := repository.NewSnapshotRepository(configConfig)
+	snapshotService := service.SnapshotService{
+		UserRepository:     userRepository,
+		SnapshotRepository: snapshotRepository,
+	}
+	merkleRootRepository := repository.NewMerkleRootRepository(configConfig)
+	membershipService := service.MembershipService{
+		UserRepository:       userRepository,
+		WalletRepository:     walletRepository,
+		MerkleRootRepository: merkleRootRepository,
+	}
+	serviceAuditService := service.AuditService{
+		AuditEventRepository: auditEventRepository,
+	}
+	serviceLockoutService := service.LockoutService{
+		Config:         configConfig,
+		Store:          store,
+		UserRepository: userRepository,
+		AuditService:   auditService,
+	}
+	serviceSessionService := service.SessionService{
+		SessionRepository:      sessionRepository,
+		RefreshTokenRepository: refreshTokenRepository,
+		TokenManager:           manager,
+		MailSender:             sender,
+		Store:                  store,
+	}
+	serviceAPIKeyService := service.APIKeyService{
+		Config:           configConfig,
+		APIKeyRepository: apiKeyRepository,
+		UserRepository:   userRepository,
+		Authorizer:       authorizer,
+		AuditService:     auditService,
+		Store:            store,
 	}
 	providerProvider := &Provider{
-		Config:      configConfig,
-		UserService: userService,
+		Config:              configConfig,
+		TokenManager:        manager,
+		Authorizer:          authorizer,
+		RateLimiter:         registry,
+		AuthService:         authService,
+		UserService:         userService,
+		AdminService:        adminService,
+		VerificationService: serviceVerificationService,
+		PasswordService:     passwordService,
+		TwoFactorService:    serviceTwoFactorService,
+		SIWEService:         siweService,
+		WalletService:       serviceWalletService,
+		OrganizationService: organizationService,
+		InviteService:       serviceInviteService,
+		ProposalService:     proposalService,
+		PollService:         pollService,
+		DelegationService:   delegationService,
+		SnapshotService:     snapshotService,
+		MembershipService:   membershipService,
+		AuditService:        serviceAuditService,
+		LockoutService:      serviceLockoutService,
+		SessionService:      serviceSessionService,
+		APIKeyService:       serviceAPIKeyService,
 	}
 	return providerProvider, nil
 }
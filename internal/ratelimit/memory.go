@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval 进程内限流器清理闲置键的间隔
+const sweepInterval = time.Minute
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryTokenBucket 进程内令牌桶, 容量为 limit, 每个 period 补满一次
+type MemoryTokenBucket struct {
+	mu        sync.Mutex
+	capacity  int64
+	rate      float64 // 每纳秒补充的令牌数
+	period    time.Duration
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func NewMemoryTokenBucket(limit int64, period time.Duration) *MemoryTokenBucket {
+	return &MemoryTokenBucket{
+		capacity:  limit,
+		rate:      float64(limit) / float64(period),
+		period:    period,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *MemoryTokenBucket) Allow(_ context.Context, key string) (*Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(l.capacity), last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = min(float64(l.capacity), bucket.tokens+float64(now.Sub(bucket.last))*l.rate)
+	bucket.last = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+	return tokenBucketResult(allowed, bucket.tokens, l.capacity, l.rate), nil
+}
+
+// sweep 清理一个周期内没有请求的桶, 它们此时已经补满, 调用方需持有锁
+func (l *MemoryTokenBucket) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) > l.period {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+type slidingWindow struct {
+	start time.Time
+	prev  int64
+	cur   int64
+}
+
+// MemorySlidingWindow 进程内滑动窗口计数, 任意 period 长度的区间内最多放行约 limit 次
+type MemorySlidingWindow struct {
+	mu        sync.Mutex
+	limit     int64
+	period    time.Duration
+	windows   map[string]*slidingWindow
+	lastSweep time.Time
+}
+
+func NewMemorySlidingWindow(limit int64, period time.Duration) *MemorySlidingWindow {
+	return &MemorySlidingWindow{
+		limit:     limit,
+		period:    period,
+		windows:   make(map[string]*slidingWindow),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *MemorySlidingWindow) Allow(_ context.Context, key string) (*Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	// 滚动到当前固定窗口
+	start := now.Truncate(l.period)
+	window, ok := l.windows[key]
+	switch {
+	case !ok:
+		window = &slidingWindow{start: start}
+		l.windows[key] = window
+	case start.Sub(window.start) == l.period:
+		window.start, window.prev, window.cur = start, window.cur, 0
+	case start.Sub(window.start) > l.period:
+		window.start, window.prev, window.cur = start, 0, 0
+	}
+
+	elapsed := now.Sub(start)
+	allowed := slidingWindowEstimate(window.prev, window.cur, elapsed, l.period)+1 <= float64(l.limit)
+	if allowed {
+		window.cur++
+	}
+	return slidingWindowResult(allowed, window.prev, window.cur, l.limit, elapsed, l.period), nil
+}
+
+// sweep 清理两个周期内没有请求的窗口, 调用方需持有锁
+func (l *MemorySlidingWindow) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, window := range l.windows {
+		if now.Sub(window.start) > 2*l.period {
+			delete(l.windows, key)
+		}
+	}
+}
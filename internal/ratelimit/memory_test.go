@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// within 判断 got 与 want 的差不超过 1 毫秒, 用于吸收浮点取整与测试运行耗时
+func within(got, want time.Duration) bool {
+	d := got - want
+	return d >= -time.Millisecond && d <= time.Millisecond
+}
+
+func TestMemoryTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryTokenBucket(3, time.Hour)
+
+	for i := int64(1); i <= 3; i++ {
+		res, err := l.Allow(ctx, "a")
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		if !res.Allowed || res.Limit != 3 || res.Remaining != 3-i {
+			t.Fatalf("Allow() #%d = %+v, want allowed with %d remaining", i, res, 3-i)
+		}
+	}
+
+	res, _ := l.Allow(ctx, "a")
+	if res.Allowed || res.Remaining != 0 {
+		t.Fatalf("Allow() over limit = %+v, want rejected", res)
+	}
+	// 每 20 分钟补充一个令牌
+	if res.RetryAfter <= 0 || res.RetryAfter > 20*time.Minute {
+		t.Errorf("RetryAfter = %v, want (0, 20m]", res.RetryAfter)
+	}
+
+	// 不同的键独立计数
+	if res, _ = l.Allow(ctx, "b"); !res.Allowed || res.Remaining != 2 {
+		t.Errorf("Allow(b) = %+v, want allowed with 2 remaining", res)
+	}
+
+	// 过去 20 分钟后补充一个令牌
+	l.buckets["a"].last = l.buckets["a"].last.Add(-20 * time.Minute)
+	if res, _ = l.Allow(ctx, "a"); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("Allow() after refill = %+v, want allowed with 0 remaining", res)
+	}
+	if res, _ = l.Allow(ctx, "a"); res.Allowed {
+		t.Errorf("Allow() after refill used = %+v, want rejected", res)
+	}
+
+	// 闲置超过一个周期后桶补满
+	l.buckets["a"].last = l.buckets["a"].last.Add(-2 * time.Hour)
+	if res, _ = l.Allow(ctx, "a"); !res.Allowed || res.Remaining != 2 {
+		t.Errorf("Allow() after idle = %+v, want allowed with 2 remaining", res)
+	}
+}
+
+func TestMemorySlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemorySlidingWindow(3, time.Hour)
+
+	for i := int64(1); i <= 3; i++ {
+		res, err := l.Allow(ctx, "a")
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		if !res.Allowed || res.Limit != 3 {
+			t.Fatalf("Allow() #%d = %+v, want allowed", i, res)
+		}
+	}
+
+	res, _ := l.Allow(ctx, "a")
+	if res.Allowed || res.Remaining != 0 {
+		t.Fatalf("Allow() over limit = %+v, want rejected", res)
+	}
+	if res.RetryAfter <= 0 || res.RetryAfter > time.Hour {
+		t.Errorf("RetryAfter = %v, want (0, 1h]", res.RetryAfter)
+	}
+
+	// 不同的键独立计数
+	if res, _ = l.Allow(ctx, "b"); !res.Allowed {
+		t.Errorf("Allow(b) = %+v, want allowed", res)
+	}
+
+	// 两个周期以前的计数不再计入
+	l.windows["a"].start = l.windows["a"].start.Add(-2 * time.Hour)
+	if res, _ = l.Allow(ctx, "a"); !res.Allowed || res.Remaining != 2 {
+		t.Errorf("Allow() after two periods = %+v, want allowed with 2 remaining", res)
+	}
+}
+
+func TestSlidingWindowResult(t *testing.T) {
+	const period = time.Minute
+	tests := []struct {
+		name       string
+		allowed    bool
+		prev, cur  int64
+		elapsed    time.Duration
+		remaining  int64
+		retryAfter time.Duration
+	}{
+		{"empty window", true, 0, 1, 0, 9, 0},
+		{"half of previous window counted", true, 10, 2, 30 * time.Second, 3, 0},
+		// 估计值为 5 + 5 = 10, 上一窗口的权重降到 0.4 后为 4 + 5 = 9, 可以再放行一次
+		{"wait for previous window to decay", false, 10, 5, 30 * time.Second, 0, 6 * time.Second},
+		{"current window full", false, 10, 10, 20 * time.Second, 0, 40 * time.Second},
+		{"no previous window", false, 0, 10, 45 * time.Second, 0, 15 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := slidingWindowResult(tt.allowed, tt.prev, tt.cur, 10, tt.elapsed, period)
+			if res.Remaining != tt.remaining {
+				t.Errorf("Remaining = %d, want %d", res.Remaining, tt.remaining)
+			}
+			if res.Reset != period-tt.elapsed {
+				t.Errorf("Reset = %v, want %v", res.Reset, period-tt.elapsed)
+			}
+			if !within(res.RetryAfter, tt.retryAfter) {
+				t.Errorf("RetryAfter = %v, want %v", res.RetryAfter, tt.retryAfter)
+			}
+		})
+	}
+}
+
+func TestTokenBucketResult(t *testing.T) {
+	rate := 10 / float64(time.Minute)
+
+	res := tokenBucketResult(true, 4.5, 10, rate)
+	if res.Remaining != 4 || !within(res.Reset, 33*time.Second) || res.RetryAfter != 0 {
+		t.Errorf("tokenBucketResult(allowed) = %+v, want 4 remaining, reset 33s", res)
+	}
+
+	res = tokenBucketResult(false, 0.5, 10, rate)
+	if res.Remaining != 0 || !within(res.Reset, 57*time.Second) || !within(res.RetryAfter, 3*time.Second) {
+		t.Errorf("tokenBucketResult(rejected) = %+v, want 0 remaining, reset 57s, retry after 3s", res)
+	}
+}
@@ -0,0 +1,159 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+const (
+	AlgorithmTokenBucket   = "token_bucket"
+	AlgorithmSlidingWindow = "sliding_window"
+
+	KeyByIP     = "ip"
+	KeyByUser   = "user"
+	KeyByAPIKey = "api_key"
+)
+
+// 内置规则的名称, 路由组按名称引用
+const (
+	RuleGlobal    = "global"    // 全部请求, 按 IP
+	RuleAuth      = "auth"      // 注册、登录等未认证接口, 按 IP
+	RuleUser      = "user"      // 已认证接口, 按用户
+	RuleSensitive = "sensitive" // 修改密码等需要 bcrypt 校验的接口, 按用户
+)
+
+// defaultRules 未在 Config.RateLimit.Rules 中覆盖时使用的规则
+var defaultRules = []config.RateLimitRule{
+	{Name: RuleGlobal, Algorithm: AlgorithmTokenBucket, KeyBy: KeyByIP, Limit: 600, Period: 60},
+	{Name: RuleAuth, Algorithm: AlgorithmSlidingWindow, KeyBy: KeyByIP, Limit: 20, Period: 60},
+	{Name: RuleUser, Algorithm: AlgorithmTokenBucket, KeyBy: KeyByUser, Limit: 300, Period: 60},
+	{Name: RuleSensitive, Algorithm: AlgorithmSlidingWindow, KeyBy: KeyByUser, Limit: 5, Period: 60},
+}
+
+// Result 一次限流判断的结果
+type Result struct {
+	Allowed    bool
+	Limit      int64
+	Remaining  int64
+	Reset      time.Duration // 配额完全恢复(令牌桶)或当前窗口结束(滑动窗口)的剩余时间
+	RetryAfter time.Duration // 被拒绝时需要等待的时间
+}
+
+// Limiter 判断某个键的请求是否放行
+type Limiter interface {
+	Allow(ctx context.Context, key string) (*Result, error)
+}
+
+// Policy 一条限流规则及其限流器
+type Policy struct {
+	config.RateLimitRule
+	Limiter
+}
+
+// Registry 按规则名称管理限流器
+type Registry struct {
+	policies map[string]*Policy
+}
+
+// NewRegistry 根据配置创建限流器, 配置了 Redis 时多实例共享计数, 否则使用进程内计数
+func NewRegistry(config *config.Config) *Registry {
+	r := &Registry{policies: make(map[string]*Policy)}
+	if config.RateLimit.Disabled {
+		log.Info("限流已关闭")
+		return r
+	}
+
+	var rds *redis.Redis
+	if config.Redis != nil && config.Redis.Host != "" {
+		rds = redis.MustNewRedis(*config.Redis)
+	}
+
+	for name, rule := range mergeRules(config.RateLimit.Rules) {
+		if rule.Limit <= 0 || rule.Period <= 0 {
+			log.Info("限流规则 %s 未启用", name)
+			continue
+		}
+		r.policies[name] = &Policy{RateLimitRule: rule, Limiter: newLimiter(rule, rds)}
+		log.Info("加载限流规则 %s: %s, 按 %s, %d 次/%d 秒", name, rule.Algorithm, rule.KeyBy, rule.Limit, rule.Period)
+	}
+	return r
+}
+
+// mergeRules 配置中的规则按名称覆盖内置规则
+func mergeRules(configured []config.RateLimitRule) map[string]config.RateLimitRule {
+	rules := make(map[string]config.RateLimitRule, len(defaultRules)+len(configured))
+	for _, rule := range defaultRules {
+		rules[rule.Name] = rule
+	}
+	for _, rule := range configured {
+		rules[rule.Name] = rule
+	}
+	return rules
+}
+
+// Get 获取规则, 规则不存在或未启用时 ok 为 false
+func (r *Registry) Get(name string) (*Policy, bool) {
+	policy, ok := r.policies[name]
+	return policy, ok
+}
+
+func newLimiter(rule config.RateLimitRule, rds *redis.Redis) Limiter {
+	period := time.Duration(rule.Period) * time.Second
+	keyPrefix := "ratelimit:" + rule.Name + ":"
+	switch {
+	case rule.Algorithm == AlgorithmSlidingWindow && rds != nil:
+		return NewRedisSlidingWindow(rds, keyPrefix, rule.Limit, period)
+	case rule.Algorithm == AlgorithmSlidingWindow:
+		return NewMemorySlidingWindow(rule.Limit, period)
+	case rds != nil:
+		return NewRedisTokenBucket(rds, keyPrefix, rule.Limit, period)
+	default:
+		return NewMemoryTokenBucket(rule.Limit, period)
+	}
+}
+
+// tokenBucketResult 根据桶内剩余令牌计算结果, rate 为每纳秒补充的令牌数
+func tokenBucketResult(allowed bool, tokens float64, capacity int64, rate float64) *Result {
+	result := &Result{
+		Allowed:   allowed,
+		Limit:     capacity,
+		Remaining: int64(math.Floor(tokens)),
+		Reset:     time.Duration(math.Ceil((float64(capacity) - tokens) / rate)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration(math.Ceil((1 - tokens) / rate))
+	}
+	return result
+}
+
+// slidingWindowEstimate 用上一窗口按剩余比例加权后的计数与当前窗口计数之和估计滑动窗口内的请求数
+func slidingWindowEstimate(prev, cur int64, elapsed, period time.Duration) float64 {
+	weight := 1 - float64(elapsed)/float64(period)
+	return float64(prev)*weight + float64(cur)
+}
+
+// slidingWindowResult 根据窗口计数计算结果, cur 已包含本次放行的请求
+func slidingWindowResult(allowed bool, prev, cur, limit int64, elapsed, period time.Duration) *Result {
+	estimate := slidingWindowEstimate(prev, cur, elapsed, period)
+	result := &Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: max(limit-int64(math.Ceil(estimate)), 0),
+		Reset:     period - elapsed,
+	}
+	if !allowed {
+		// 当前窗口已满时要等到下一窗口, 否则等待上一窗口的权重衰减到足够放行一次
+		if cur+1 > limit || prev == 0 {
+			result.RetryAfter = period - elapsed
+		} else {
+			need := 1 - float64(limit-1-cur)/float64(prev)
+			result.RetryAfter = max(time.Duration(need*float64(period))-elapsed, time.Millisecond)
+		}
+	}
+	return result
+}
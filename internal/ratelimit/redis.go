@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// tokenBucketScript 补充令牌并尝试取出一个, 返回 {是否放行, 剩余令牌}
+// 剩余令牌为小数, 以字符串返回避免被 Redis 截断为整数
+const tokenBucketScript = `local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return {allowed, tostring(tokens)}`
+
+// slidingWindowScript KEYS 为当前与上一固定窗口的计数, 估计值未超限时当前窗口计数加一
+// 返回 {是否放行, 当前窗口计数, 上一窗口计数}
+const slidingWindowScript = `local cur = tonumber(redis.call("GET", KEYS[1]) or "0")
+local prev = tonumber(redis.call("GET", KEYS[2]) or "0")
+local limit = tonumber(ARGV[1])
+local weight = tonumber(ARGV[2])
+if prev * weight + cur + 1 > limit then
+	return {0, cur, prev}
+end
+cur = redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return {1, cur, prev}`
+
+// RedisTokenBucket 多实例共享的令牌桶
+type RedisTokenBucket struct {
+	rds       *redis.Redis
+	keyPrefix string
+	capacity  int64
+	period    time.Duration
+}
+
+func NewRedisTokenBucket(rds *redis.Redis, keyPrefix string, limit int64, period time.Duration) *RedisTokenBucket {
+	return &RedisTokenBucket{
+		rds:       rds,
+		keyPrefix: keyPrefix,
+		capacity:  limit,
+		period:    period,
+	}
+}
+
+func (l *RedisTokenBucket) Allow(ctx context.Context, key string) (*Result, error) {
+	ratePerMs := float64(l.capacity) / float64(l.period.Milliseconds())
+	res, err := l.rds.EvalCtx(ctx, tokenBucketScript, []string{l.keyPrefix + key},
+		l.capacity, strconv.FormatFloat(ratePerMs, 'g', -1, 64), time.Now().UnixMilli(), l.period.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := res.([]any)
+	if !ok || len(values) != 2 {
+		return nil, fmt.Errorf("unexpected token bucket result %v", res)
+	}
+	allowed, _ := values[0].(int64)
+	tokensStr, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected token bucket tokens %v", values[1])
+	}
+	return tokenBucketResult(allowed == 1, tokens, l.capacity, float64(l.capacity)/float64(l.period)), nil
+}
+
+// RedisSlidingWindow 多实例共享的滑动窗口计数
+type RedisSlidingWindow struct {
+	rds       *redis.Redis
+	keyPrefix string
+	limit     int64
+	period    time.Duration
+}
+
+func NewRedisSlidingWindow(rds *redis.Redis, keyPrefix string, limit int64, period time.Duration) *RedisSlidingWindow {
+	return &RedisSlidingWindow{
+		rds:       rds,
+		keyPrefix: keyPrefix,
+		limit:     limit,
+		period:    period,
+	}
+}
+
+func (l *RedisSlidingWindow) Allow(ctx context.Context, key string) (*Result, error) {
+	now := time.Now()
+	index := now.UnixNano() / int64(l.period)
+	elapsed := time.Duration(now.UnixNano() - index*int64(l.period))
+	weight := 1 - float64(elapsed)/float64(l.period)
+
+	// 两个窗口的键使用相同的哈希标签, 保证在集群中位于同一槽位
+	base := l.keyPrefix + "{" + key + "}:"
+	keys := []string{base + strconv.FormatInt(index, 10), base + strconv.FormatInt(index-1, 10)}
+	res, err := l.rds.EvalCtx(ctx, slidingWindowScript, keys,
+		l.limit, strconv.FormatFloat(weight, 'g', -1, 64), (2 * l.period).Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := res.([]any)
+	if !ok || len(values) != 3 {
+		return nil, fmt.Errorf("unexpected sliding window result %v", res)
+	}
+	allowed, _ := values[0].(int64)
+	cur, _ := values[1].(int64)
+	prev, _ := values[2].(int64)
+	return slidingWindowResult(allowed == 1, prev, cur, l.limit, elapsed, l.period), nil
+}
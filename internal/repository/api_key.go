@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	APIKeyCollectionName = "api_key"
+)
+
+type IAPIKeyRepository interface {
+	Insert(ctx context.Context, key *model.APIKey) error
+	FindByID(ctx context.Context, id bson.ObjectID) (*model.APIKey, error)
+	FindByKeyHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	FindByUserID(ctx context.Context, userId bson.ObjectID) ([]*model.APIKey, error)
+	CountActiveByUserID(ctx context.Context, userId bson.ObjectID, now time.Time) (int64, error)
+	Revoke(ctx context.Context, id, operatorId bson.ObjectID, t time.Time) (bool, error)
+	UpdateLastUsed(ctx context.Context, id bson.ObjectID, ip string, t time.Time) error
+	RevokeByUserID(ctx context.Context, userId bson.ObjectID, t time.Time) error
+}
+
+type APIKeyRepository struct {
+	conn *monc.Model
+}
+
+func NewAPIKeyRepository(config *config.Config) *APIKeyRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, APIKeyCollectionName, config.Cache)
+
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: consts.KeyHash, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		log.Error("failed to create api key hash index: %v", err)
+	}
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: consts.UserID, Value: 1}, {Key: consts.CreatedAt, Value: -1}},
+	}); err != nil {
+		log.Error("failed to create api key user index: %v", err)
+	}
+
+	return &APIKeyRepository{
+		conn: conn,
+	}
+}
+
+func (r *APIKeyRepository) Insert(ctx context.Context, key *model.APIKey) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, key); err != nil {
+		log.CtxError(ctx, "failed to insert api key: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) FindByID(ctx context.Context, id bson.ObjectID) (*model.APIKey, error) {
+	key := model.APIKey{}
+	if err := r.conn.FindOneNoCache(ctx, &key, bson.M{consts.ID: id}); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepository) FindByKeyHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	key := model.APIKey{}
+	if err := r.conn.FindOneNoCache(ctx, &key, bson.M{consts.KeyHash: keyHash}); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// FindByUserID 按创建时间倒序获取用户的全部 API Key
+func (r *APIKeyRepository) FindByUserID(ctx context.Context, userId bson.ObjectID) ([]*model.APIKey, error) {
+	keys := make([]*model.APIKey, 0)
+	opts := options.Find().SetSort(bson.D{{Key: consts.CreatedAt, Value: -1}, {Key: consts.ID, Value: -1}})
+	if err := r.conn.Find(ctx, &keys, bson.M{consts.UserID: userId}, opts); err != nil {
+		log.CtxError(ctx, "failed to find api keys of user %s: %v", userId.Hex(), err)
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// CountActiveByUserID 统计用户未吊销且未过期的 API Key
+func (r *APIKeyRepository) CountActiveByUserID(ctx context.Context, userId bson.ObjectID, now time.Time) (int64, error) {
+	n, err := r.conn.CountDocuments(ctx, bson.M{
+		consts.UserID:    userId,
+		consts.Revoked:   false,
+		consts.ExpiresAt: bson.M{"$gt": now},
+	})
+	if err != nil {
+		log.CtxError(ctx, "failed to count api keys of user %s: %v", userId.Hex(), err)
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// Revoke 吊销 API Key, 返回 false 表示密钥已被吊销
+func (r *APIKeyRepository) Revoke(ctx context.Context, id, operatorId bson.ObjectID, t time.Time) (bool, error) {
+	res, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: id, consts.Revoked: false},
+		bson.M{"$set": bson.M{consts.Revoked: true, consts.RevokedAt: t, consts.RevokedBy: operatorId}})
+	if err != nil {
+		log.CtxError(ctx, "failed to revoke api key %s: %v", id.Hex(), err)
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *APIKeyRepository) UpdateLastUsed(ctx context.Context, id bson.ObjectID, ip string, t time.Time) error {
+	if _, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: id},
+		bson.M{"$set": bson.M{consts.LastUsedAt: t, consts.LastUsedIP: ip}}); err != nil {
+		log.CtxError(ctx, "failed to update last used of api key %s: %v", id.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+// RevokeByUserID 吊销用户的全部 API Key, 用于删除账号
+func (r *APIKeyRepository) RevokeByUserID(ctx context.Context, userId bson.ObjectID, t time.Time) error {
+	if _, err := r.conn.UpdateManyNoCache(ctx,
+		bson.M{consts.UserID: userId, consts.Revoked: false},
+		bson.M{"$set": bson.M{consts.Revoked: true, consts.RevokedAt: t, consts.RevokedBy: userId}}); err != nil {
+		log.CtxError(ctx, "failed to revoke api keys of user %s: %v", userId.Hex(), err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	AuditEventCollectionName = "audit_events"
+)
+
+type IAuditEventRepository interface {
+	Insert(ctx context.Context, event *model.AuditEvent) error
+	FindLatest(ctx context.Context) (*model.AuditEvent, error)
+	FindEvents(ctx context.Context, query *AuditEventQuery, skip, limit int64) ([]*model.AuditEvent, error)
+	CountEvents(ctx context.Context, query *AuditEventQuery) (int64, error)
+	ScanEvents(ctx context.Context, fn func(event *model.AuditEvent) error) error
+}
+
+// AuditEventQuery 审计事件查询条件, 零值字段不参与过滤
+type AuditEventQuery struct {
+	ActorID    bson.ObjectID
+	Action     string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+}
+
+type AuditEventRepository struct {
+	conn *monc.Model
+}
+
+func NewAuditEventRepository(config *config.Config) *AuditEventRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, AuditEventCollectionName, config.Cache)
+
+	// 序号唯一, 并发写入时只有一个能接在链尾
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: consts.Seq, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		log.Error("failed to create audit event seq index: %v", err)
+	}
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: consts.ActorID, Value: 1}, {Key: consts.Seq, Value: -1}},
+	}); err != nil {
+		log.Error("failed to create audit event actor index: %v", err)
+	}
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: consts.TargetID, Value: 1}, {Key: consts.Seq, Value: -1}},
+	}); err != nil {
+		log.Error("failed to create audit event target index: %v", err)
+	}
+
+	return &AuditEventRepository{
+		conn: conn,
+	}
+}
+
+// Insert 追加审计事件, 序号已被占用时返回重复键错误
+func (r *AuditEventRepository) Insert(ctx context.Context, event *model.AuditEvent) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, event); err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			log.CtxError(ctx, "failed to insert audit event: %v", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// FindLatest 获取链尾的审计事件
+func (r *AuditEventRepository) FindLatest(ctx context.Context) (*model.AuditEvent, error) {
+	event := model.AuditEvent{}
+	opts := options.FindOne().SetSort(bson.D{{Key: consts.Seq, Value: -1}})
+	if err := r.conn.FindOneNoCache(ctx, &event, bson.M{}, opts); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// FindEvents 按序号倒序分页查询
+func (r *AuditEventRepository) FindEvents(ctx context.Context, query *AuditEventQuery, skip, limit int64) ([]*model.AuditEvent, error) {
+	events := make([]*model.AuditEvent, 0, limit)
+	opts := options.Find().SetSort(bson.D{{Key: consts.Seq, Value: -1}}).SetSkip(skip).SetLimit(limit)
+	if err := r.conn.Find(ctx, &events, query.filter(), opts); err != nil {
+		log.CtxError(ctx, "failed to find audit events: %v", err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *AuditEventRepository) CountEvents(ctx context.Context, query *AuditEventQuery) (int64, error) {
+	count, err := r.conn.CountDocuments(ctx, query.filter())
+	if err != nil {
+		log.CtxError(ctx, "failed to count audit events: %v", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ScanEvents 按序号升序遍历全部审计事件, 用于校验哈希链
+func (r *AuditEventRepository) ScanEvents(ctx context.Context, fn func(event *model.AuditEvent) error) error {
+	cur, err := r.conn.Collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: consts.Seq, Value: 1}}))
+	if err != nil {
+		log.CtxError(ctx, "failed to scan audit events: %v", err)
+		return err
+	}
+	defer func() { _ = cur.Close(ctx) }()
+
+	for cur.Next(ctx) {
+		event := model.AuditEvent{}
+		if err = cur.Decode(&event); err != nil {
+			log.CtxError(ctx, "failed to decode audit event: %v", err)
+			return err
+		}
+		if err = fn(&event); err != nil {
+			return err
+		}
+	}
+
+	return cur.Err()
+}
+
+func (q *AuditEventQuery) filter() bson.M {
+	filter := bson.M{}
+	if !q.ActorID.IsZero() {
+		filter[consts.ActorID] = q.ActorID
+	}
+	if q.Action != "" {
+		filter[consts.Action] = q.Action
+	}
+	if q.TargetType != "" {
+		filter[consts.TargetType] = q.TargetType
+	}
+	if q.TargetID != "" {
+		filter[consts.TargetID] = q.TargetID
+	}
+	if r := timeRange(q.From, q.To); r != nil {
+		filter[consts.CreatedAt] = r
+	}
+	return filter
+}
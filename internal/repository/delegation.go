@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	DelegationCollectionName = "delegation"
+)
+
+type IDelegationRepository interface {
+	Upsert(ctx context.Context, delegation *model.Delegation) error
+	FindByDelegatorID(ctx context.Context, delegatorId bson.ObjectID) (*model.Delegation, error)
+	DeleteByDelegatorID(ctx context.Context, delegatorId bson.ObjectID) (bool, error)
+	FindByDelegateIDs(ctx context.Context, delegateIds []bson.ObjectID) ([]*model.Delegation, error)
+	FindByDelegateID(ctx context.Context, delegateId bson.ObjectID, skip, limit int64) ([]*model.Delegation, error)
+	CountByDelegateID(ctx context.Context, delegateId bson.ObjectID) (int64, error)
+	DeleteByUserID(ctx context.Context, userId bson.ObjectID) error
+}
+
+type DelegationRepository struct {
+	conn *monc.Model
+}
+
+func NewDelegationRepository(config *config.Config) *DelegationRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, DelegationCollectionName, config.Cache)
+
+	// 每个用户同时只能委托给一人
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: consts.DelegatorID, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		log.Error("failed to create delegation delegator index: %v", err)
+	}
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: consts.DelegateID, Value: 1}, {Key: consts.CreatedAt, Value: 1}},
+	}); err != nil {
+		log.Error("failed to create delegation delegate index: %v", err)
+	}
+
+	return &DelegationRepository{
+		conn: conn,
+	}
+}
+
+// Upsert 创建委托, 已有委托时改为委托给新的受托人
+func (r *DelegationRepository) Upsert(ctx context.Context, delegation *model.Delegation) error {
+	_, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.DelegatorID: delegation.DelegatorID},
+		bson.M{
+			"$set": bson.M{
+				consts.DelegateID: delegation.DelegateID,
+				consts.CreatedAt:  delegation.CreatedAt,
+			},
+			"$setOnInsert": bson.M{consts.ID: delegation.ID},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		log.CtxError(ctx, "failed to upsert delegation for user %s: %v", delegation.DelegatorID.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *DelegationRepository) FindByDelegatorID(ctx context.Context, delegatorId bson.ObjectID) (*model.Delegation, error) {
+	delegation := model.Delegation{}
+	if err := r.conn.FindOneNoCache(ctx, &delegation, bson.M{consts.DelegatorID: delegatorId}); err != nil {
+		return nil, err
+	}
+
+	return &delegation, nil
+}
+
+// DeleteByDelegatorID 撤销委托, 返回是否存在委托
+func (r *DelegationRepository) DeleteByDelegatorID(ctx context.Context, delegatorId bson.ObjectID) (bool, error) {
+	n, err := r.conn.DeleteOneNoCache(ctx, bson.M{consts.DelegatorID: delegatorId})
+	if err != nil {
+		log.CtxError(ctx, "failed to delete delegation for user %s: %v", delegatorId.Hex(), err)
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// FindByDelegateIDs 获取委托给任一给定用户的全部委托
+func (r *DelegationRepository) FindByDelegateIDs(ctx context.Context, delegateIds []bson.ObjectID) ([]*model.Delegation, error) {
+	delegations := make([]*model.Delegation, 0)
+	if err := r.conn.Find(ctx, &delegations, bson.M{consts.DelegateID: bson.M{"$in": delegateIds}}); err != nil {
+		log.CtxError(ctx, "failed to find delegations: %v", err)
+		return nil, err
+	}
+
+	return delegations, nil
+}
+
+// FindByDelegateID 按委托时间倒序分页获取委托给该用户的委托
+func (r *DelegationRepository) FindByDelegateID(ctx context.Context, delegateId bson.ObjectID, skip, limit int64) ([]*model.Delegation, error) {
+	delegations := make([]*model.Delegation, 0)
+	opts := options.Find().
+		SetSort(bson.D{{Key: consts.CreatedAt, Value: -1}, {Key: consts.ID, Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+	if err := r.conn.Find(ctx, &delegations, bson.M{consts.DelegateID: delegateId}, opts); err != nil {
+		log.CtxError(ctx, "failed to find delegations to user %s: %v", delegateId.Hex(), err)
+		return nil, err
+	}
+
+	return delegations, nil
+}
+
+func (r *DelegationRepository) CountByDelegateID(ctx context.Context, delegateId bson.ObjectID) (int64, error) {
+	n, err := r.conn.CountDocuments(ctx, bson.M{consts.DelegateID: delegateId})
+	if err != nil {
+		log.CtxError(ctx, "failed to count delegations to user %s: %v", delegateId.Hex(), err)
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// DeleteByUserID 删除用户发出与收到的全部委托, 委托给该用户的成员恢复为自己投票
+func (r *DelegationRepository) DeleteByUserID(ctx context.Context, userId bson.ObjectID) error {
+	if _, err := r.conn.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{consts.DelegatorID: userId},
+		bson.M{consts.DelegateID: userId},
+	}}); err != nil {
+		log.CtxError(ctx, "failed to delete delegations of user %s: %v", userId.Hex(), err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	EmailVerificationCollectionName = "email_verification"
+)
+
+type IEmailVerificationRepository interface {
+	Insert(ctx context.Context, verification *model.EmailVerification) error
+	FindByNonceHash(ctx context.Context, nonceHash string) (*model.EmailVerification, error)
+	FindLatestByUserID(ctx context.Context, userId bson.ObjectID) (*model.EmailVerification, error)
+	CountByUserIDSince(ctx context.Context, userId bson.ObjectID, since time.Time) (int64, error)
+	MarkUsed(ctx context.Context, id bson.ObjectID, t time.Time) (bool, error)
+}
+
+type EmailVerificationRepository struct {
+	conn *monc.Model
+}
+
+func NewEmailVerificationRepository(config *config.Config) *EmailVerificationRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, EmailVerificationCollectionName, config.Cache)
+	return &EmailVerificationRepository{
+		conn: conn,
+	}
+}
+
+func (r *EmailVerificationRepository) Insert(ctx context.Context, verification *model.EmailVerification) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, verification); err != nil {
+		log.CtxError(ctx, "failed to insert email verification: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *EmailVerificationRepository) FindByNonceHash(ctx context.Context, nonceHash string) (*model.EmailVerification, error) {
+	verification := model.EmailVerification{}
+	if err := r.conn.FindOneNoCache(ctx, &verification, bson.M{consts.NonceHash: nonceHash}); err != nil {
+		log.CtxError(ctx, "failed to find email verification: %v", err)
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+func (r *EmailVerificationRepository) FindLatestByUserID(ctx context.Context, userId bson.ObjectID) (*model.EmailVerification, error) {
+	verification := model.EmailVerification{}
+	opts := options.FindOne().SetSort(bson.D{{Key: consts.CreatedAt, Value: -1}})
+	if err := r.conn.FindOneNoCache(ctx, &verification, bson.M{consts.UserID: userId}, opts); err != nil {
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+func (r *EmailVerificationRepository) CountByUserIDSince(ctx context.Context, userId bson.ObjectID, since time.Time) (int64, error) {
+	count, err := r.conn.CountDocuments(ctx, bson.M{consts.UserID: userId, consts.CreatedAt: bson.M{"$gte": since}})
+	if err != nil {
+		log.CtxError(ctx, "failed to count email verifications: %v", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// MarkUsed 将未使用的验证令牌标记为已使用, 返回 false 表示令牌已被使用过
+func (r *EmailVerificationRepository) MarkUsed(ctx context.Context, id bson.ObjectID, t time.Time) (bool, error) {
+	res, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: id, consts.Used: false},
+		bson.M{"$set": bson.M{consts.Used: true, consts.UsedAt: t}})
+	if err != nil {
+		log.CtxError(ctx, "failed to mark email verification %s used: %v", id.Hex(), err)
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	InviteCodeCollectionName = "invite_code"
+)
+
+// ErrInviteCodeExisted 邀请码与已有邀请码重复
+var ErrInviteCodeExisted = errors.New("invite code already exists")
+
+type IInviteCodeRepository interface {
+	Insert(ctx context.Context, invite *model.InviteCode) error
+	FindByCode(ctx context.Context, code string) (*model.InviteCode, error)
+	FindByCreatorID(ctx context.Context, creatorId bson.ObjectID) ([]*model.InviteCode, error)
+	CountActiveByCreatorID(ctx context.Context, creatorId bson.ObjectID, now time.Time) (int64, error)
+	Redeem(ctx context.Context, code string, now time.Time) (*model.InviteCode, error)
+	Release(ctx context.Context, id bson.ObjectID) error
+	Revoke(ctx context.Context, id bson.ObjectID) error
+}
+
+type InviteCodeRepository struct {
+	conn *monc.Model
+}
+
+func NewInviteCodeRepository(config *config.Config) *InviteCodeRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, InviteCodeCollectionName, config.Cache)
+
+	// 邀请码唯一索引
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: consts.Code, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		log.Error("failed to create invite code index: %v", err)
+	}
+
+	return &InviteCodeRepository{
+		conn: conn,
+	}
+}
+
+// Insert 插入邀请码, 邀请码重复时返回 ErrInviteCodeExisted
+func (r *InviteCodeRepository) Insert(ctx context.Context, invite *model.InviteCode) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, invite); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrInviteCodeExisted
+		}
+		log.CtxError(ctx, "failed to insert invite code: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *InviteCodeRepository) FindByCode(ctx context.Context, code string) (*model.InviteCode, error) {
+	invite := model.InviteCode{}
+	if err := r.conn.FindOneNoCache(ctx, &invite, bson.M{consts.Code: code}); err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+// FindByCreatorID 获取用户生成的邀请码, 按生成时间倒序
+func (r *InviteCodeRepository) FindByCreatorID(ctx context.Context, creatorId bson.ObjectID) ([]*model.InviteCode, error) {
+	invites := make([]*model.InviteCode, 0)
+	opts := options.Find().SetSort(bson.D{{Key: consts.CreatedAt, Value: -1}})
+	if err := r.conn.Find(ctx, &invites, bson.M{consts.CreatorID: creatorId}, opts); err != nil {
+		log.CtxError(ctx, "failed to find invite codes of user %s: %v", creatorId.Hex(), err)
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+// CountActiveByCreatorID 统计用户仍可使用的邀请码数量
+func (r *InviteCodeRepository) CountActiveByCreatorID(ctx context.Context, creatorId bson.ObjectID, now time.Time) (int64, error) {
+	filter := usableInviteFilter(now)
+	filter[consts.CreatorID] = creatorId
+	n, err := r.conn.CountDocuments(ctx, filter)
+	if err != nil {
+		log.CtxError(ctx, "failed to count invite codes of user %s: %v", creatorId.Hex(), err)
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// Redeem 原子地占用一次邀请码, 邀请码不存在、已吊销、已过期或已用完时返回 monc.ErrNotFound
+func (r *InviteCodeRepository) Redeem(ctx context.Context, code string, now time.Time) (*model.InviteCode, error) {
+	invite := model.InviteCode{}
+	filter := usableInviteFilter(now)
+	filter[consts.Code] = code
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if err := r.conn.FindOneAndUpdateNoCache(ctx, &invite, filter, bson.M{"$inc": bson.M{consts.UsedCount: 1}}, opts); err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+// Release 归还一次占用, 用于注册失败时回滚
+func (r *InviteCodeRepository) Release(ctx context.Context, id bson.ObjectID) error {
+	if _, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: id, consts.UsedCount: bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{consts.UsedCount: -1}}); err != nil {
+		log.CtxError(ctx, "failed to release invite code %s: %v", id.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *InviteCodeRepository) Revoke(ctx context.Context, id bson.ObjectID) error {
+	if _, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: id},
+		bson.M{"$set": bson.M{consts.Revoked: true}}); err != nil {
+		log.CtxError(ctx, "failed to revoke invite code %s: %v", id.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+// usableInviteFilter 未吊销、未过期且未用完的邀请码
+func usableInviteFilter(now time.Time) bson.M {
+	return bson.M{
+		consts.Revoked: false,
+		"$and": bson.A{
+			bson.M{"$or": bson.A{
+				bson.M{consts.ExpiresAt: time.Time{}},
+				bson.M{consts.ExpiresAt: bson.M{"$gt": now}},
+			}},
+			bson.M{"$or": bson.A{
+				bson.M{consts.MaxUses: 0},
+				bson.M{"$expr": bson.M{"$lt": bson.A{"$" + consts.UsedCount, "$" + consts.MaxUses}}},
+			}},
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	MerkleRootCollectionName = "merkle_root"
+)
+
+type IMerkleRootRepository interface {
+	Insert(ctx context.Context, root *model.MerkleRoot) error
+	FindLatest(ctx context.Context) (*model.MerkleRoot, error)
+}
+
+type MerkleRootRepository struct {
+	conn *monc.Model
+}
+
+func NewMerkleRootRepository(config *config.Config) *MerkleRootRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, MerkleRootCollectionName, config.Cache)
+	return &MerkleRootRepository{
+		conn: conn,
+	}
+}
+
+func (r *MerkleRootRepository) Insert(ctx context.Context, root *model.MerkleRoot) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, root); err != nil {
+		log.CtxError(ctx, "failed to insert merkle root: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindLatest 获取最近发布的成员承诺
+func (r *MerkleRootRepository) FindLatest(ctx context.Context) (*model.MerkleRoot, error) {
+	root := model.MerkleRoot{}
+	opts := options.FindOne().SetSort(bson.D{{Key: consts.CreatedAt, Value: -1}, {Key: consts.ID, Value: -1}})
+	if err := r.conn.FindOneNoCache(ctx, &root, bson.M{}, opts); err != nil {
+		return nil, err
+	}
+
+	return &root, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	OrganizationCollectionName = "organization"
+)
+
+// ErrOrganizationSlugExisted slug 已被其他组织使用
+var ErrOrganizationSlugExisted = errors.New("organization slug already exists")
+
+type IOrganizationRepository interface {
+	Insert(ctx context.Context, org *model.Organization) error
+	FindByID(ctx context.Context, id bson.ObjectID) (*model.Organization, error)
+	FindBySlug(ctx context.Context, slug string) (*model.Organization, error)
+	Update(ctx context.Context, id bson.ObjectID, update bson.M) error
+	FindOrganizations(ctx context.Context, status enum.OrganizationStatus, skip, limit int64) ([]*model.Organization, error)
+	CountOrganizations(ctx context.Context, status enum.OrganizationStatus) (int64, error)
+}
+
+type OrganizationRepository struct {
+	conn *monc.Model
+}
+
+func NewOrganizationRepository(config *config.Config) *OrganizationRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, OrganizationCollectionName, config.Cache)
+
+	// slug 唯一索引
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: consts.Slug, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		log.Error("failed to create organization slug index: %v", err)
+	}
+
+	return &OrganizationRepository{
+		conn: conn,
+	}
+}
+
+// Insert 插入组织, slug 已存在时返回 ErrOrganizationSlugExisted
+func (r *OrganizationRepository) Insert(ctx context.Context, org *model.Organization) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, org); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrOrganizationSlugExisted
+		}
+		log.CtxError(ctx, "failed to insert organization: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *OrganizationRepository) FindByID(ctx context.Context, id bson.ObjectID) (*model.Organization, error) {
+	org := model.Organization{}
+	if err := r.conn.FindOneNoCache(ctx, &org, bson.M{consts.ID: id}); err != nil {
+		return nil, err
+	}
+
+	return &org, nil
+}
+
+func (r *OrganizationRepository) FindBySlug(ctx context.Context, slug string) (*model.Organization, error) {
+	org := model.Organization{}
+	if err := r.conn.FindOneNoCache(ctx, &org, bson.M{consts.Slug: slug}); err != nil {
+		return nil, err
+	}
+
+	return &org, nil
+}
+
+// Update 更新组织, 修改后的 slug 已存在时返回 ErrOrganizationSlugExisted
+func (r *OrganizationRepository) Update(ctx context.Context, id bson.ObjectID, update bson.M) error {
+	if _, err := r.conn.UpdateOneNoCache(ctx, bson.M{consts.ID: id}, bson.M{"$set": update}); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrOrganizationSlugExisted
+		}
+		log.CtxError(ctx, "failed to update organization %s: %v", id.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+// FindOrganizations 按创建时间倒序分页查询, status 为零值时不过滤状态
+func (r *OrganizationRepository) FindOrganizations(ctx context.Context, status enum.OrganizationStatus, skip, limit int64) ([]*model.Organization, error) {
+	orgs := make([]*model.Organization, 0)
+	opts := options.Find().
+		SetSort(bson.D{{Key: consts.CreatedAt, Value: -1}, {Key: consts.ID, Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+	if err := r.conn.Find(ctx, &orgs, organizationFilter(status), opts); err != nil {
+		log.CtxError(ctx, "failed to find organizations: %v", err)
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+func (r *OrganizationRepository) CountOrganizations(ctx context.Context, status enum.OrganizationStatus) (int64, error) {
+	n, err := r.conn.CountDocuments(ctx, organizationFilter(status))
+	if err != nil {
+		log.CtxError(ctx, "failed to count organizations: %v", err)
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func organizationFilter(status enum.OrganizationStatus) bson.M {
+	filter := bson.M{}
+	if status != 0 {
+		filter[consts.Status] = status
+	}
+	return filter
+}
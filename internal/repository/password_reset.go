@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	PasswordResetCollectionName = "password_reset"
+)
+
+type IPasswordResetRepository interface {
+	Insert(ctx context.Context, reset *model.PasswordReset) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*model.PasswordReset, error)
+	MarkUsed(ctx context.Context, id bson.ObjectID, t time.Time) (bool, error)
+	MarkAllUsedByUserID(ctx context.Context, userId bson.ObjectID, t time.Time) error
+}
+
+type PasswordResetRepository struct {
+	conn *monc.Model
+}
+
+func NewPasswordResetRepository(config *config.Config) *PasswordResetRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, PasswordResetCollectionName, config.Cache)
+	return &PasswordResetRepository{
+		conn: conn,
+	}
+}
+
+func (r *PasswordResetRepository) Insert(ctx context.Context, reset *model.PasswordReset) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, reset); err != nil {
+		log.CtxError(ctx, "failed to insert password reset: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *PasswordResetRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*model.PasswordReset, error) {
+	reset := model.PasswordReset{}
+	if err := r.conn.FindOneNoCache(ctx, &reset, bson.M{consts.TokenHash: tokenHash}); err != nil {
+		log.CtxError(ctx, "failed to find password reset: %v", err)
+		return nil, err
+	}
+
+	return &reset, nil
+}
+
+// MarkUsed 将未使用的重置令牌标记为已使用, 返回 false 表示令牌已被使用过
+func (r *PasswordResetRepository) MarkUsed(ctx context.Context, id bson.ObjectID, t time.Time) (bool, error) {
+	res, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: id, consts.Used: false},
+		bson.M{"$set": bson.M{consts.Used: true, consts.UsedAt: t}})
+	if err != nil {
+		log.CtxError(ctx, "failed to mark password reset %s used: %v", id.Hex(), err)
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
+// MarkAllUsedByUserID 使该用户其余未使用的重置令牌全部失效
+func (r *PasswordResetRepository) MarkAllUsedByUserID(ctx context.Context, userId bson.ObjectID, t time.Time) error {
+	if _, err := r.conn.UpdateManyNoCache(ctx,
+		bson.M{consts.UserID: userId, consts.Used: false},
+		bson.M{"$set": bson.M{consts.Used: true, consts.UsedAt: t}}); err != nil {
+		log.CtxError(ctx, "failed to invalidate password resets of user %s: %v", userId.Hex(), err)
+		return err
+	}
+
+	return nil
+}
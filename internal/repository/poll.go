@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	PollCollectionName = "poll"
+)
+
+type IPollRepository interface {
+	Insert(ctx context.Context, poll *model.Poll) error
+	FindByID(ctx context.Context, id bson.ObjectID) (*model.Poll, error)
+}
+
+type PollRepository struct {
+	conn *monc.Model
+}
+
+func NewPollRepository(config *config.Config) *PollRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, PollCollectionName, config.Cache)
+	return &PollRepository{
+		conn: conn,
+	}
+}
+
+func (r *PollRepository) Insert(ctx context.Context, poll *model.Poll) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, poll); err != nil {
+		log.CtxError(ctx, "failed to insert poll: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *PollRepository) FindByID(ctx context.Context, id bson.ObjectID) (*model.Poll, error) {
+	poll := model.Poll{}
+	if err := r.conn.FindOneNoCache(ctx, &poll, bson.M{consts.ID: id}); err != nil {
+		return nil, err
+	}
+
+	return &poll, nil
+}
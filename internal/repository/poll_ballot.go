@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	PollBallotCollectionName = "poll_ballot"
+)
+
+type IPollBallotRepository interface {
+	Upsert(ctx context.Context, ballot *model.PollBallot) error
+	FindByVoter(ctx context.Context, pollId, voterId bson.ObjectID) (*model.PollBallot, error)
+	FindByPollID(ctx context.Context, pollId bson.ObjectID) ([]*model.PollBallot, error)
+}
+
+type PollBallotRepository struct {
+	conn *monc.Model
+}
+
+func NewPollBallotRepository(config *config.Config) *PollBallotRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, PollBallotCollectionName, config.Cache)
+
+	// 每人每次投票只有一张选票
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: consts.PollID, Value: 1}, {Key: consts.VoterID, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		log.Error("failed to create poll ballot index: %v", err)
+	}
+
+	return &PollBallotRepository{
+		conn: conn,
+	}
+}
+
+// Upsert 投票或改票, 已有选票时只更新选择内容
+func (r *PollBallotRepository) Upsert(ctx context.Context, ballot *model.PollBallot) error {
+	_, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.PollID: ballot.PollID, consts.VoterID: ballot.VoterID},
+		bson.M{
+			"$set": bson.M{
+				consts.Choices:   ballot.Choices,
+				consts.Credits:   ballot.Credits,
+				consts.UpdatedAt: ballot.UpdatedAt,
+			},
+			"$setOnInsert": bson.M{
+				consts.ID:        ballot.ID,
+				consts.CreatedAt: ballot.CreatedAt,
+			},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		log.CtxError(ctx, "failed to upsert ballot for poll %s: %v", ballot.PollID.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *PollBallotRepository) FindByVoter(ctx context.Context, pollId, voterId bson.ObjectID) (*model.PollBallot, error) {
+	ballot := model.PollBallot{}
+	if err := r.conn.FindOneNoCache(ctx, &ballot, bson.M{consts.PollID: pollId, consts.VoterID: voterId}); err != nil {
+		return nil, err
+	}
+
+	return &ballot, nil
+}
+
+// FindByPollID 按投票时间顺序返回全部选票, 保证计票输入顺序稳定
+func (r *PollBallotRepository) FindByPollID(ctx context.Context, pollId bson.ObjectID) ([]*model.PollBallot, error) {
+	ballots := make([]*model.PollBallot, 0)
+	opts := options.Find().SetSort(bson.D{{Key: consts.CreatedAt, Value: 1}, {Key: consts.ID, Value: 1}})
+	if err := r.conn.Find(ctx, &ballots, bson.M{consts.PollID: pollId}, opts); err != nil {
+		log.CtxError(ctx, "failed to find ballots for poll %s: %v", pollId.Hex(), err)
+		return nil, err
+	}
+
+	return ballots, nil
+}
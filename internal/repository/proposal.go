@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	ProposalCollectionName = "proposal"
+)
+
+// ErrProposalStatusChanged 提案状态已被并发修改
+var ErrProposalStatusChanged = errors.New("proposal status changed")
+
+type IProposalRepository interface {
+	Insert(ctx context.Context, proposal *model.Proposal) error
+	FindByID(ctx context.Context, id bson.ObjectID) (*model.Proposal, error)
+	UpdateDraft(ctx context.Context, id bson.ObjectID, update bson.M) error
+	Transition(ctx context.Context, id bson.ObjectID, transition model.ProposalTransition, update bson.M) error
+	FindProposals(ctx context.Context, status enum.ProposalStatus, proposerId bson.ObjectID, skip, limit int64) ([]*model.Proposal, error)
+	CountProposals(ctx context.Context, status enum.ProposalStatus, proposerId bson.ObjectID) (int64, error)
+}
+
+type ProposalRepository struct {
+	conn *monc.Model
+}
+
+func NewProposalRepository(config *config.Config) *ProposalRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, ProposalCollectionName, config.Cache)
+	return &ProposalRepository{
+		conn: conn,
+	}
+}
+
+func (r *ProposalRepository) Insert(ctx context.Context, proposal *model.Proposal) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, proposal); err != nil {
+		log.CtxError(ctx, "failed to insert proposal: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *ProposalRepository) FindByID(ctx context.Context, id bson.ObjectID) (*model.Proposal, error) {
+	proposal := model.Proposal{}
+	if err := r.conn.FindOneNoCache(ctx, &proposal, bson.M{consts.ID: id}); err != nil {
+		return nil, err
+	}
+
+	return &proposal, nil
+}
+
+// UpdateDraft 修改草稿, 提案已离开草稿状态时返回 ErrProposalStatusChanged
+func (r *ProposalRepository) UpdateDraft(ctx context.Context, id bson.ObjectID, update bson.M) error {
+	result, err := r.conn.UpdateOneNoCache(ctx, bson.M{consts.ID: id, consts.Status: enum.ProposalDraft}, bson.M{"$set": update})
+	if err != nil {
+		log.CtxError(ctx, "failed to update proposal %s: %v", id.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrProposalStatusChanged
+	}
+
+	return nil
+}
+
+// Transition 以 transition.From 为前置条件修改状态并追加流转记录
+// 状态已不是 transition.From 时返回 ErrProposalStatusChanged
+func (r *ProposalRepository) Transition(ctx context.Context, id bson.ObjectID, transition model.ProposalTransition, update bson.M) error {
+	set := bson.M{consts.Status: transition.To, consts.UpdatedAt: transition.At}
+	for k, v := range update {
+		set[k] = v
+	}
+	result, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: id, consts.Status: transition.From},
+		bson.M{"$set": set, "$push": bson.M{consts.Transitions: transition}},
+	)
+	if err != nil {
+		log.CtxError(ctx, "failed to transition proposal %s: %v", id.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrProposalStatusChanged
+	}
+
+	return nil
+}
+
+// FindProposals 按创建时间倒序分页查询, status 与 proposerId 为零值时不过滤
+func (r *ProposalRepository) FindProposals(ctx context.Context, status enum.ProposalStatus, proposerId bson.ObjectID, skip, limit int64) ([]*model.Proposal, error) {
+	proposals := make([]*model.Proposal, 0)
+	opts := options.Find().
+		SetSort(bson.D{{Key: consts.CreatedAt, Value: -1}, {Key: consts.ID, Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+	if err := r.conn.Find(ctx, &proposals, proposalFilter(status, proposerId), opts); err != nil {
+		log.CtxError(ctx, "failed to find proposals: %v", err)
+		return nil, err
+	}
+
+	return proposals, nil
+}
+
+func (r *ProposalRepository) CountProposals(ctx context.Context, status enum.ProposalStatus, proposerId bson.ObjectID) (int64, error) {
+	n, err := r.conn.CountDocuments(ctx, proposalFilter(status, proposerId))
+	if err != nil {
+		log.CtxError(ctx, "failed to count proposals: %v", err)
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func proposalFilter(status enum.ProposalStatus, proposerId bson.ObjectID) bson.M {
+	filter := bson.M{}
+	if status != 0 {
+		filter[consts.Status] = status
+	}
+	if !proposerId.IsZero() {
+		filter[consts.ProposerID] = proposerId
+	}
+	return filter
+}
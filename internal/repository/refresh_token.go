@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	RefreshTokenCollectionName = "refresh_token"
+)
+
+type IRefreshTokenRepository interface {
+	Insert(ctx context.Context, token *model.RefreshToken) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	MarkUsed(ctx context.Context, id bson.ObjectID, t time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, familyId string) error
+	RevokeByUserID(ctx context.Context, userId bson.ObjectID) error
+}
+
+type RefreshTokenRepository struct {
+	conn *monc.Model
+}
+
+func NewRefreshTokenRepository(config *config.Config) *RefreshTokenRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, RefreshTokenCollectionName, config.Cache)
+	return &RefreshTokenRepository{
+		conn: conn,
+	}
+}
+
+func (r *RefreshTokenRepository) Insert(ctx context.Context, token *model.RefreshToken) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, token); err != nil {
+		log.CtxError(ctx, "failed to insert refresh token: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	token := model.RefreshToken{}
+	if err := r.conn.FindOneNoCache(ctx, &token, bson.M{consts.TokenHash: tokenHash}); err != nil {
+		log.CtxError(ctx, "failed to find refresh token: %v", err)
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkUsed 将未使用的刷新令牌标记为已使用, 返回 false 表示令牌已被使用过(并发重放)
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id bson.ObjectID, t time.Time) (bool, error) {
+	res, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: id, consts.Used: false},
+		bson.M{"$set": bson.M{consts.Used: true, consts.UsedAt: t}})
+	if err != nil {
+		log.CtxError(ctx, "failed to mark refresh token %s used: %v", id.Hex(), err)
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyId string) error {
+	if _, err := r.conn.UpdateManyNoCache(ctx, bson.M{consts.FamilyID: familyId}, bson.M{"$set": bson.M{consts.Revoked: true}}); err != nil {
+		log.CtxError(ctx, "failed to revoke refresh token family %s: %v", familyId, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeByUserID(ctx context.Context, userId bson.ObjectID) error {
+	if _, err := r.conn.UpdateManyNoCache(ctx, bson.M{consts.UserID: userId}, bson.M{"$set": bson.M{consts.Revoked: true}}); err != nil {
+		log.CtxError(ctx, "failed to revoke refresh tokens of user %s: %v", userId.Hex(), err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	SessionCollectionName = "session"
+)
+
+type ISessionRepository interface {
+	Insert(ctx context.Context, session *model.Session) error
+	FindByID(ctx context.Context, userId, id bson.ObjectID) (*model.Session, error)
+	FindByUserID(ctx context.Context, userId bson.ObjectID, activeAt time.Time, skip, limit int64) ([]*model.Session, error)
+	CountByUserID(ctx context.Context, userId bson.ObjectID, activeAt time.Time) (int64, error)
+	CountByDevice(ctx context.Context, userId bson.ObjectID, device string) (int64, error)
+	UpdateOnRefresh(ctx context.Context, familyId, tokenId string, expiresAt, t time.Time) error
+	UpdateLastSeen(ctx context.Context, familyId string, t time.Time) error
+	RevokeByFamilyID(ctx context.Context, familyId string, t time.Time) error
+	RevokeByUserID(ctx context.Context, userId bson.ObjectID, t time.Time) error
+}
+
+type SessionRepository struct {
+	conn *monc.Model
+}
+
+func NewSessionRepository(config *config.Config) *SessionRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, SessionCollectionName, config.Cache)
+
+	// 会话与刷新令牌家族一一对应
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: consts.FamilyID, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		log.Error("failed to create session family index: %v", err)
+	}
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: consts.UserID, Value: 1}, {Key: consts.CreatedAt, Value: -1}},
+	}); err != nil {
+		log.Error("failed to create session user index: %v", err)
+	}
+
+	return &SessionRepository{
+		conn: conn,
+	}
+}
+
+func (r *SessionRepository) Insert(ctx context.Context, session *model.Session) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, session); err != nil {
+		log.CtxError(ctx, "failed to insert session: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindByID 获取用户的指定会话, 会话不属于该用户时返回 monc.ErrNotFound
+func (r *SessionRepository) FindByID(ctx context.Context, userId, id bson.ObjectID) (*model.Session, error) {
+	session := model.Session{}
+	if err := r.conn.FindOneNoCache(ctx, &session, bson.M{consts.ID: id, consts.UserID: userId}); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// FindByUserID 按登录时间倒序分页获取用户的会话
+// activeAt 非零时只返回在该时刻仍有效的会话, limit 为 0 时不限制数量
+func (r *SessionRepository) FindByUserID(ctx context.Context, userId bson.ObjectID, activeAt time.Time, skip, limit int64) ([]*model.Session, error) {
+	sessions := make([]*model.Session, 0)
+	opts := options.Find().
+		SetSort(bson.D{{Key: consts.CreatedAt, Value: -1}, {Key: consts.ID, Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+	if err := r.conn.Find(ctx, &sessions, sessionFilter(userId, activeAt), opts); err != nil {
+		log.CtxError(ctx, "failed to find sessions of user %s: %v", userId.Hex(), err)
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *SessionRepository) CountByUserID(ctx context.Context, userId bson.ObjectID, activeAt time.Time) (int64, error) {
+	n, err := r.conn.CountDocuments(ctx, sessionFilter(userId, activeAt))
+	if err != nil {
+		log.CtxError(ctx, "failed to count sessions of user %s: %v", userId.Hex(), err)
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// CountByDevice 统计用户在指定设备上的历史会话数
+func (r *SessionRepository) CountByDevice(ctx context.Context, userId bson.ObjectID, device string) (int64, error) {
+	n, err := r.conn.CountDocuments(ctx, bson.M{consts.UserID: userId, consts.Device: device})
+	if err != nil {
+		log.CtxError(ctx, "failed to count sessions of user %s on device: %v", userId.Hex(), err)
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// UpdateOnRefresh 刷新令牌后记录新的访问令牌并延长会话有效期
+func (r *SessionRepository) UpdateOnRefresh(ctx context.Context, familyId, tokenId string, expiresAt, t time.Time) error {
+	if _, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.FamilyID: familyId},
+		bson.M{"$set": bson.M{consts.TokenID: tokenId, consts.ExpiresAt: expiresAt, consts.LastSeenAt: t}}); err != nil {
+		log.CtxError(ctx, "failed to update session %s on refresh: %v", familyId, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) UpdateLastSeen(ctx context.Context, familyId string, t time.Time) error {
+	if _, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.FamilyID: familyId, consts.LastSeenAt: bson.M{"$lt": t}},
+		bson.M{"$set": bson.M{consts.LastSeenAt: t}}); err != nil {
+		log.CtxError(ctx, "failed to update last seen of session %s: %v", familyId, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeByFamilyID(ctx context.Context, familyId string, t time.Time) error {
+	if _, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.FamilyID: familyId, consts.Revoked: false},
+		bson.M{"$set": bson.M{consts.Revoked: true, consts.RevokedAt: t}}); err != nil {
+		log.CtxError(ctx, "failed to revoke session %s: %v", familyId, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeByUserID(ctx context.Context, userId bson.ObjectID, t time.Time) error {
+	if _, err := r.conn.UpdateManyNoCache(ctx,
+		bson.M{consts.UserID: userId, consts.Revoked: false},
+		bson.M{"$set": bson.M{consts.Revoked: true, consts.RevokedAt: t}}); err != nil {
+		log.CtxError(ctx, "failed to revoke sessions of user %s: %v", userId.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+// sessionFilter 构造用户会话的查询条件, activeAt 非零时只匹配未吊销且未过期的会话
+func sessionFilter(userId bson.ObjectID, activeAt time.Time) bson.M {
+	filter := bson.M{consts.UserID: userId}
+	if !activeAt.IsZero() {
+		filter[consts.Revoked] = false
+		filter[consts.ExpiresAt] = bson.M{"$gt": activeAt}
+	}
+	return filter
+}
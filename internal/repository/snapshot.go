@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	SnapshotCollectionName = "snapshot"
+)
+
+type ISnapshotRepository interface {
+	Insert(ctx context.Context, snapshot *model.Snapshot) error
+	FindByID(ctx context.Context, id bson.ObjectID) (*model.Snapshot, error)
+	FindSnapshots(ctx context.Context, skip, limit int64) ([]*model.Snapshot, error)
+	CountSnapshots(ctx context.Context) (int64, error)
+}
+
+type SnapshotRepository struct {
+	conn *monc.Model
+}
+
+func NewSnapshotRepository(config *config.Config) *SnapshotRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, SnapshotCollectionName, config.Cache)
+	return &SnapshotRepository{
+		conn: conn,
+	}
+}
+
+func (r *SnapshotRepository) Insert(ctx context.Context, snapshot *model.Snapshot) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, snapshot); err != nil {
+		log.CtxError(ctx, "failed to insert snapshot: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *SnapshotRepository) FindByID(ctx context.Context, id bson.ObjectID) (*model.Snapshot, error) {
+	snapshot := model.Snapshot{}
+	if err := r.conn.FindOneNoCache(ctx, &snapshot, bson.M{consts.ID: id}); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// FindSnapshots 按快照时间倒序分页查询, 不返回成员数据
+func (r *SnapshotRepository) FindSnapshots(ctx context.Context, skip, limit int64) ([]*model.Snapshot, error) {
+	snapshots := make([]*model.Snapshot, 0)
+	opts := options.Find().
+		SetProjection(bson.M{consts.Data: 0}).
+		SetSort(bson.D{{Key: consts.CreatedAt, Value: -1}, {Key: consts.ID, Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+	if err := r.conn.Find(ctx, &snapshots, bson.M{}, opts); err != nil {
+		log.CtxError(ctx, "failed to find snapshots: %v", err)
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+func (r *SnapshotRepository) CountSnapshots(ctx context.Context) (int64, error) {
+	n, err := r.conn.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		log.CtxError(ctx, "failed to count snapshots: %v", err)
+		return 0, err
+	}
+
+	return n, nil
+}
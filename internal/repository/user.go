@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"regexp"
 	"time"
 
 	"github.com/NoANameGroup/DAOld-Backend/internal/config"
@@ -11,6 +12,8 @@ import (
 	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
 	"github.com/zeromicro/go-zero/core/stores/monc"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 const (
@@ -22,20 +25,82 @@ type IUserRepository interface {
 	Insert(ctx context.Context, user *model.User) error
 	FindUserByEmail(ctx context.Context, email string) (*model.User, error)
 	UpdateLastLoginAt(ctx context.Context, userId bson.ObjectID, t time.Time) error
-	FindUserByUserID(ctx context.Context, userId bson.ObjectID)
+	FindUserByUserID(ctx context.Context, userId bson.ObjectID) (*model.User, error)
 	UpdatePassword(ctx context.Context, userId bson.ObjectID, password string) error
 	DeleteUser(ctx context.Context, userId bson.ObjectID) error
 	UpdateUser(ctx context.Context, userId bson.ObjectID, update bson.M) error
-	IsAdmin(ctx context.Context, userId bson.ObjectID) (bool, error)
 	UpdateUserRole(ctx context.Context, userId bson.ObjectID, role enum.UserRole) error
+	UpdateStatus(ctx context.Context, userId bson.ObjectID, status enum.UserStatus, reason string, until time.Time) error
+	ReactivateExpiredSuspension(ctx context.Context, userId bson.ObjectID, now time.Time) (bool, error)
+	MarkEmailVerified(ctx context.Context, userId bson.ObjectID, email string) (bool, error)
+	SetTOTPPendingSecret(ctx context.Context, userId bson.ObjectID, secret string) error
+	EnableTOTP(ctx context.Context, userId bson.ObjectID, secret string, step int64, recoveryCodes []string) (bool, error)
+	DisableTOTP(ctx context.Context, userId bson.ObjectID) error
+	UseTOTPStep(ctx context.Context, userId bson.ObjectID, step int64) (bool, error)
+	ConsumeRecoveryCode(ctx context.Context, userId bson.ObjectID, codeHash string) (bool, error)
+	SetRecoveryCodes(ctx context.Context, userId bson.ObjectID, recoveryCodes []string) error
+	FindUsersByReferrers(ctx context.Context, referrerIds []bson.ObjectID, limit int64) ([]*model.User, error)
+	FindUsersByIDs(ctx context.Context, userIds []bson.ObjectID) ([]*model.User, error)
+	FindUsers(ctx context.Context, query *UserQuery, sort UserSort, after *UserCursor, skip, limit int64) ([]*model.User, error)
+	CountUsers(ctx context.Context, query *UserQuery) (int64, error)
+	ScanUsers(ctx context.Context, query *UserQuery, sort UserSort, fn func(user *model.User) error) error
 }
 
+// UserQuery 用户列表查询条件, 零值字段不参与过滤
+type UserQuery struct {
+	Role          enum.UserRole
+	Status        enum.UserStatus
+	Gender        enum.UserGender
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	LastLoginFrom time.Time
+	LastLoginTo   time.Time
+	Keyword       string
+	TextSearch    bool // true 时使用 $text 全文检索, 否则对用户名、邮箱、姓名做前缀匹配
+}
+
+// UserSort 排序字段与方向, 相同值之间再按 _id 排序以保证顺序稳定
+type UserSort struct {
+	Field string
+	Desc  bool
+}
+
+// UserCursor 游标分页的位置, 指向上一页最后一条记录
+type UserCursor struct {
+	Value any
+	ID    bson.ObjectID
+}
+
+// emailCollation 邮箱按不区分大小写的规则比较, 兼容统一转为小写之前注册的账号
+var emailCollation = &options.Collation{Locale: "en", Strength: 2}
+
 type UserRepository struct {
 	conn *monc.Model
 }
 
 func NewUserRepository(config *config.Config) *UserRepository {
 	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, CollectionName, config.Cache)
+
+	// 按邮箱查询使用与索引相同的排序规则, 钱包注册的账号邮箱为空, 不能建唯一索引
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: consts.Email, Value: 1}},
+		Options: options.Index().SetCollation(emailCollation),
+	}); err != nil {
+		log.Error("failed to create user email index: %v", err)
+	}
+
+	// 用户列表的全文检索依赖文本索引, 一个集合只能有一个文本索引
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: consts.Username, Value: "text"},
+			{Key: consts.Email, Value: "text"},
+			{Key: consts.FirstName, Value: "text"},
+			{Key: consts.LastName, Value: "text"},
+		},
+	}); err != nil {
+		log.Error("failed to create user text index: %v", err)
+	}
+
 	return &UserRepository{
 		conn: conn,
 	}
@@ -44,7 +109,7 @@ func NewUserRepository(config *config.Config) *UserRepository {
 func (r *UserRepository) IsEmailExist(ctx context.Context, email string) (bool, error) {
 	var err error
 	var count int64
-	if count, err = r.conn.CountDocuments(ctx, bson.M{consts.Email: email}); err != nil {
+	if count, err = r.conn.CountDocuments(ctx, bson.M{consts.Email: email}, options.Count().SetCollation(emailCollation)); err != nil {
 		log.CtxError(ctx, "failed to check existing email: %v", err)
 		return false, err
 	}
@@ -67,7 +132,7 @@ func (r *UserRepository) FindUserByEmail(ctx context.Context, email string) (*mo
 	user := model.User{}
 	log.CtxInfo(ctx, "FindUserByEmail in collection=%s, filter=%+v", CollectionName, bson.M{consts.Email: email})
 
-	if err = r.conn.FindOneNoCache(ctx, &user, bson.M{consts.Email: email}); err != nil {
+	if err = r.conn.FindOneNoCache(ctx, &user, bson.M{consts.Email: email}, options.FindOne().SetCollation(emailCollation)); err != nil {
 		log.CtxError(ctx, "failed to find user by email: %v", err)
 		return nil, err
 	}
@@ -124,22 +189,287 @@ func (r *UserRepository) UpdateUser(ctx context.Context, userId bson.ObjectID, u
 	return nil
 }
 
-func (r *UserRepository) IsAdmin(ctx context.Context, userId bson.ObjectID) (bool, error) {
-	var err error
-	user := &model.User{}
-	if user, err = r.FindUserByUserID(ctx, userId); err != nil {
-		log.CtxError(ctx, "failed to find user by userId: %v", err)
+func (r *UserRepository) UpdateUserRole(ctx context.Context, userId bson.ObjectID, role enum.UserRole) error {
+	if _, err := r.conn.UpdateByIDNoCache(ctx, userId, bson.M{"$set": bson.M{consts.Role: role}}); err != nil {
+		log.CtxError(ctx, "failed to update user role %s: %v", userId.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *UserRepository) UpdateStatus(ctx context.Context, userId bson.ObjectID, status enum.UserStatus, reason string, until time.Time) error {
+	update := bson.M{
+		consts.Status:         status,
+		consts.StatusReason:   reason,
+		consts.SuspendedUntil: until,
+		consts.UpdatedAt:      time.Now(),
+	}
+	if _, err := r.conn.UpdateByIDNoCache(ctx, userId, bson.M{"$set": update}); err != nil {
+		log.CtxError(ctx, "failed to update status for user %s: %v", userId.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+// ReactivateExpiredSuspension 将暂停已到期的账号恢复为活跃, 仅当账号仍处于暂停状态时生效, 避免覆盖并发的封禁
+func (r *UserRepository) ReactivateExpiredSuspension(ctx context.Context, userId bson.ObjectID, now time.Time) (bool, error) {
+	filter := bson.M{
+		consts.ID:             userId,
+		consts.Status:         enum.StatusSuspended,
+		consts.SuspendedUntil: bson.M{"$gt": time.Time{}, "$lte": now},
+	}
+	update := bson.M{"$set": bson.M{
+		consts.Status:         enum.StatusActive,
+		consts.StatusReason:   "",
+		consts.SuspendedUntil: time.Time{},
+		consts.UpdatedAt:      now,
+	}}
+	res, err := r.conn.UpdateOneNoCache(ctx, filter, update)
+	if err != nil {
+		log.CtxError(ctx, "failed to reactivate user %s: %v", userId.Hex(), err)
 		return false, err
 	}
 
-	return user.Role == enum.RoleAdmin, nil
+	return res.ModifiedCount == 1, nil
 }
 
-func (r *UserRepository) UpdateUserRole(ctx context.Context, userId bson.ObjectID, role enum.UserRole) error {
-	if _, err := r.conn.UpdateByIDNoCache(ctx, userId, bson.M{"$set": bson.M{consts.Role: role}}); err != nil {
-		log.CtxError(ctx, "failed to update user role %s: %v", userId.Hex(), err)
+// MarkEmailVerified 将邮箱标记为已验证, 仅当用户当前邮箱仍为 email 时生效
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userId bson.ObjectID, email string) (bool, error) {
+	res, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: userId, consts.Email: email},
+		bson.M{"$set": bson.M{consts.EmailUnverified: false, consts.UpdatedAt: time.Now()}})
+	if err != nil {
+		log.CtxError(ctx, "failed to mark email verified for user %s: %v", userId.Hex(), err)
+		return false, err
+	}
+
+	return res.MatchedCount == 1, nil
+}
+
+func (r *UserRepository) SetTOTPPendingSecret(ctx context.Context, userId bson.ObjectID, secret string) error {
+	if _, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: userId},
+		bson.M{"$set": bson.M{consts.TOTPPendingSecret: secret, consts.UpdatedAt: time.Now()}}); err != nil {
+		log.CtxError(ctx, "failed to set totp pending secret for user %s: %v", userId.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+// EnableTOTP 启用待确认的密钥, 密钥已被重新生成或已启用时返回 false
+func (r *UserRepository) EnableTOTP(ctx context.Context, userId bson.ObjectID, secret string, step int64, recoveryCodes []string) (bool, error) {
+	filter := bson.M{
+		consts.ID:                userId,
+		consts.TOTPEnabled:       bson.M{"$ne": true},
+		consts.TOTPPendingSecret: secret,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			consts.TOTPEnabled:   true,
+			consts.TOTPSecret:    secret,
+			consts.TOTPLastStep:  step,
+			consts.RecoveryCodes: recoveryCodes,
+			consts.UpdatedAt:     time.Now(),
+		},
+		"$unset": bson.M{consts.TOTPPendingSecret: ""},
+	}
+	res, err := r.conn.UpdateOneNoCache(ctx, filter, update)
+	if err != nil {
+		log.CtxError(ctx, "failed to enable totp for user %s: %v", userId.Hex(), err)
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *UserRepository) DisableTOTP(ctx context.Context, userId bson.ObjectID) error {
+	update := bson.M{
+		"$set": bson.M{consts.TOTPEnabled: false, consts.UpdatedAt: time.Now()},
+		"$unset": bson.M{
+			consts.TOTPSecret:        "",
+			consts.TOTPPendingSecret: "",
+			consts.TOTPLastStep:      "",
+			consts.RecoveryCodes:     "",
+		},
+	}
+	if _, err := r.conn.UpdateOneNoCache(ctx, bson.M{consts.ID: userId}, update); err != nil {
+		log.CtxError(ctx, "failed to disable totp for user %s: %v", userId.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+// UseTOTPStep 记录通过校验的时间步, 时间步不晚于上次记录时返回 false, 即验证码已被使用
+func (r *UserRepository) UseTOTPStep(ctx context.Context, userId bson.ObjectID, step int64) (bool, error) {
+	res, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: userId, consts.TOTPLastStep: bson.M{"$not": bson.M{"$gte": step}}},
+		bson.M{"$set": bson.M{consts.TOTPLastStep: step}})
+	if err != nil {
+		log.CtxError(ctx, "failed to record totp step for user %s: %v", userId.Hex(), err)
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
+// ConsumeRecoveryCode 删除一个恢复码, 恢复码不存在时返回 false
+func (r *UserRepository) ConsumeRecoveryCode(ctx context.Context, userId bson.ObjectID, codeHash string) (bool, error) {
+	res, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: userId, consts.RecoveryCodes: codeHash},
+		bson.M{"$pull": bson.M{consts.RecoveryCodes: codeHash}})
+	if err != nil {
+		log.CtxError(ctx, "failed to consume recovery code for user %s: %v", userId.Hex(), err)
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *UserRepository) SetRecoveryCodes(ctx context.Context, userId bson.ObjectID, recoveryCodes []string) error {
+	if _, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.ID: userId},
+		bson.M{"$set": bson.M{consts.RecoveryCodes: recoveryCodes, consts.UpdatedAt: time.Now()}}); err != nil {
+		log.CtxError(ctx, "failed to set recovery codes for user %s: %v", userId.Hex(), err)
 		return err
 	}
 
 	return nil
 }
+
+// FindUsersByReferrers 获取由给定用户邀请注册的用户, 按注册时间排序
+func (r *UserRepository) FindUsersByReferrers(ctx context.Context, referrerIds []bson.ObjectID, limit int64) ([]*model.User, error) {
+	users := make([]*model.User, 0)
+	opts := options.Find().SetSort(bson.D{{Key: consts.CreatedAt, Value: 1}, {Key: consts.ID, Value: 1}}).SetLimit(limit)
+	if err := r.conn.Find(ctx, &users, bson.M{consts.ReferredBy: bson.M{"$in": referrerIds}}, opts); err != nil {
+		log.CtxError(ctx, "failed to find referred users: %v", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) FindUsersByIDs(ctx context.Context, userIds []bson.ObjectID) ([]*model.User, error) {
+	users := make([]*model.User, 0, len(userIds))
+	if err := r.conn.Find(ctx, &users, bson.M{consts.ID: bson.M{"$in": userIds}}); err != nil {
+		log.CtxError(ctx, "failed to find users by ids: %v", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) FindUsers(ctx context.Context, query *UserQuery, sort UserSort, after *UserCursor, skip, limit int64) ([]*model.User, error) {
+	filter := query.filter()
+	if after != nil {
+		op := "$gt"
+		if sort.Desc {
+			op = "$lt"
+		}
+		filter = bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+			bson.M{sort.Field: bson.M{op: after.Value}},
+			bson.M{sort.Field: after.Value, consts.ID: bson.M{op: after.ID}},
+		}}}}
+	}
+
+	opts := options.Find().SetSort(sort.document()).SetSkip(skip).SetLimit(limit)
+	users := make([]*model.User, 0, limit)
+	if err := r.conn.Find(ctx, &users, filter, opts); err != nil {
+		log.CtxError(ctx, "failed to find users: %v", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) CountUsers(ctx context.Context, query *UserQuery) (int64, error) {
+	count, err := r.conn.CountDocuments(ctx, query.filter())
+	if err != nil {
+		log.CtxError(ctx, "failed to count users: %v", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ScanUsers 以游标逐条遍历全部匹配的用户, 用于导出
+func (r *UserRepository) ScanUsers(ctx context.Context, query *UserQuery, sort UserSort, fn func(user *model.User) error) error {
+	cur, err := r.conn.Collection.Find(ctx, query.filter(), options.Find().SetSort(sort.document()))
+	if err != nil {
+		log.CtxError(ctx, "failed to scan users: %v", err)
+		return err
+	}
+	defer func() { _ = cur.Close(ctx) }()
+
+	for cur.Next(ctx) {
+		user := model.User{}
+		if err = cur.Decode(&user); err != nil {
+			log.CtxError(ctx, "failed to decode user: %v", err)
+			return err
+		}
+		if err = fn(&user); err != nil {
+			return err
+		}
+	}
+
+	return cur.Err()
+}
+
+func (q *UserQuery) filter() bson.M {
+	filter := bson.M{}
+	if q.Role != 0 {
+		filter[consts.Role] = q.Role
+	}
+	if q.Status != 0 {
+		filter[consts.Status] = q.Status
+	}
+	if q.Gender != 0 {
+		filter[consts.Gender] = q.Gender
+	}
+	if r := timeRange(q.CreatedFrom, q.CreatedTo); r != nil {
+		filter[consts.CreatedAt] = r
+	}
+	if r := timeRange(q.LastLoginFrom, q.LastLoginTo); r != nil {
+		filter[consts.LastLoginAt] = r
+	}
+	if q.Keyword != "" {
+		if q.TextSearch {
+			filter["$text"] = bson.M{"$search": q.Keyword}
+		} else {
+			prefix := bson.Regex{Pattern: "^" + regexp.QuoteMeta(q.Keyword), Options: "i"}
+			filter["$or"] = bson.A{
+				bson.M{consts.Username: prefix},
+				bson.M{consts.Email: prefix},
+				bson.M{consts.FirstName: prefix},
+				bson.M{consts.LastName: prefix},
+			}
+		}
+	}
+	return filter
+}
+
+func (s UserSort) document() bson.D {
+	direction := 1
+	if s.Desc {
+		direction = -1
+	}
+	return bson.D{{Key: s.Field, Value: direction}, {Key: consts.ID, Value: direction}}
+}
+
+// timeRange 构造 [from, to) 区间条件, 两端均为零值时返回 nil
+func timeRange(from, to time.Time) bson.M {
+	r := bson.M{}
+	if !from.IsZero() {
+		r["$gte"] = from
+	}
+	if !to.IsZero() {
+		r["$lt"] = to
+	}
+	if len(r) == 0 {
+		return nil
+	}
+	return r
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	WalletCollectionName = "wallet"
+)
+
+// ErrWalletAddressExisted 地址已绑定到某个账号
+var ErrWalletAddressExisted = errors.New("wallet address already linked")
+
+type IWalletRepository interface {
+	Insert(ctx context.Context, wallet *model.Wallet) error
+	FindByAddress(ctx context.Context, address string) (*model.Wallet, error)
+	FindByUserID(ctx context.Context, userId bson.ObjectID) ([]*model.Wallet, error)
+	FindPrimaryWallets(ctx context.Context) ([]*model.Wallet, error)
+	CountByUserID(ctx context.Context, userId bson.ObjectID) (int64, error)
+	SetPrimary(ctx context.Context, userId bson.ObjectID, address string) error
+	Delete(ctx context.Context, userId bson.ObjectID, address string) (bool, error)
+	DeleteByUserID(ctx context.Context, userId bson.ObjectID) error
+}
+
+type WalletRepository struct {
+	conn *monc.Model
+}
+
+func NewWalletRepository(config *config.Config) *WalletRepository {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, WalletCollectionName, config.Cache)
+
+	// 地址唯一索引保证每个地址只属于一个账号
+	if _, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: consts.Address, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		log.Error("failed to create wallet address index: %v", err)
+	}
+
+	return &WalletRepository{
+		conn: conn,
+	}
+}
+
+// Insert 插入钱包, 地址已被绑定时返回 ErrWalletAddressExisted
+func (r *WalletRepository) Insert(ctx context.Context, wallet *model.Wallet) error {
+	if _, err := r.conn.InsertOneNoCache(ctx, wallet); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrWalletAddressExisted
+		}
+		log.CtxError(ctx, "failed to insert wallet: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *WalletRepository) FindByAddress(ctx context.Context, address string) (*model.Wallet, error) {
+	wallet := model.Wallet{}
+	if err := r.conn.FindOneNoCache(ctx, &wallet, bson.M{consts.Address: address}); err != nil {
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
+// FindByUserID 获取用户绑定的全部钱包, 按绑定时间排序
+func (r *WalletRepository) FindByUserID(ctx context.Context, userId bson.ObjectID) ([]*model.Wallet, error) {
+	wallets := make([]*model.Wallet, 0)
+	opts := options.Find().SetSort(bson.D{{Key: consts.CreatedAt, Value: 1}, {Key: consts.ID, Value: 1}})
+	if err := r.conn.Find(ctx, &wallets, bson.M{consts.UserID: userId}, opts); err != nil {
+		log.CtxError(ctx, "failed to find wallets of user %s: %v", userId.Hex(), err)
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+// FindPrimaryWallets 获取全部用户的主钱包
+func (r *WalletRepository) FindPrimaryWallets(ctx context.Context) ([]*model.Wallet, error) {
+	wallets := make([]*model.Wallet, 0)
+	if err := r.conn.Find(ctx, &wallets, bson.M{consts.Primary: true}); err != nil {
+		log.CtxError(ctx, "failed to find primary wallets: %v", err)
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+func (r *WalletRepository) CountByUserID(ctx context.Context, userId bson.ObjectID) (int64, error) {
+	n, err := r.conn.CountDocuments(ctx, bson.M{consts.UserID: userId})
+	if err != nil {
+		log.CtxError(ctx, "failed to count wallets of user %s: %v", userId.Hex(), err)
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// SetPrimary 将用户的指定钱包设为主钱包, 其余钱包取消主钱包标记
+func (r *WalletRepository) SetPrimary(ctx context.Context, userId bson.ObjectID, address string) error {
+	if _, err := r.conn.UpdateManyNoCache(ctx,
+		bson.M{consts.UserID: userId, consts.Address: bson.M{"$ne": address}, consts.Primary: true},
+		bson.M{"$set": bson.M{consts.Primary: false}}); err != nil {
+		log.CtxError(ctx, "failed to clear primary wallet of user %s: %v", userId.Hex(), err)
+		return err
+	}
+	if _, err := r.conn.UpdateOneNoCache(ctx,
+		bson.M{consts.UserID: userId, consts.Address: address},
+		bson.M{"$set": bson.M{consts.Primary: true}}); err != nil {
+		log.CtxError(ctx, "failed to set primary wallet of user %s: %v", userId.Hex(), err)
+		return err
+	}
+
+	return nil
+}
+
+// Delete 删除用户的指定钱包, 钱包不属于该用户时返回 false
+func (r *WalletRepository) Delete(ctx context.Context, userId bson.ObjectID, address string) (bool, error) {
+	n, err := r.conn.DeleteOneNoCache(ctx, bson.M{consts.UserID: userId, consts.Address: address})
+	if err != nil {
+		log.CtxError(ctx, "failed to delete wallet %s: %v", address, err)
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// DeleteByUserID 删除用户的全部钱包, 删除账号后地址可以重新登录或绑定
+func (r *WalletRepository) DeleteByUserID(ctx context.Context, userId bson.ObjectID) error {
+	if _, err := r.conn.DeleteMany(ctx, bson.M{consts.UserID: userId}); err != nil {
+		log.CtxError(ctx, "failed to delete wallets of user %s: %v", userId.Hex(), err)
+		return err
+	}
+
+	return nil
+}
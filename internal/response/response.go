@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"reflect"
+	"strconv"
 
 	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
 	"github.com/NoANameGroup/DAOld-Backend/pkg/lib"
@@ -25,13 +26,9 @@ func PostProcess(c *gin.Context, req, resp any, err error) {
 		return
 	}
 
-	var ex errorx.Errorx
-	if errors.As(err, &ex) { // errorx错误
+	if ex, ok := errorx.As(err); ok { // errorx错误
 		StatusCode := http.StatusOK
-		c.JSON(StatusCode, &errorx.Errorx{
-			Code: ex.Code,
-			Msg:  ex.Msg,
-		})
+		c.JSON(StatusCode, makeErrorBody(c, err, ex))
 	} else { // 常规错误, 状态码500
 		log.CtxError(c, "internal error, err=%s", err.Error())
 		code := http.StatusInternalServerError
@@ -39,6 +36,43 @@ func PostProcess(c *gin.Context, req, resp any, err error) {
 	}
 }
 
+// Abort 中断后续处理并以指定的HTTP状态码返回Errorx, 供中间件使用
+func Abort(c *gin.Context, statusCode int, err error) {
+	log.CtxInfo(c, "[%s] aborted with status=%d, err=%v", c.FullPath(), statusCode, err)
+
+	ex, ok := errorx.As(err)
+	if !ok {
+		log.CtxError(c, "internal error, err=%s", err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.AbortWithStatusJSON(statusCode, makeErrorBody(c, err, ex))
+}
+
+// retryAfterBody 需要等待后重试的错误响应体
+type retryAfterBody struct {
+	Code       int    `json:"code"`
+	Msg        string `json:"msg"`
+	RetryAfter int64  `json:"retryAfter"`
+}
+
+// makeErrorBody 构造错误响应体, 需要等待后重试的错误同时设置 Retry-After 响应头
+func makeErrorBody(c *gin.Context, err error, ex *errorx.Errorx) any {
+	var retry *errorx.RetryAfterError
+	if errors.As(err, &retry) {
+		c.Header("Retry-After", strconv.FormatInt(retry.Seconds(), 10))
+		return &retryAfterBody{
+			Code:       ex.Code,
+			Msg:        ex.Msg,
+			RetryAfter: retry.Seconds(),
+		}
+	}
+	return &errorx.Errorx{
+		Code: ex.Code,
+		Msg:  ex.Msg,
+	}
+}
+
 // makeResponse 通过反射构造嵌套格式的响应体
 func makeResponse(resp any) map[string]any {
 	v := reflect.ValueOf(resp)
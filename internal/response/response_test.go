@@ -0,0 +1,139 @@
+package response
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/gin-gonic/gin"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// captureLogs 收集 fn 执行期间的全部日志输出
+func captureLogs(t *testing.T, fn func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	logx.SetWriter(logx.NewWriter(&buf))
+	defer logx.Reset()
+	fn()
+	return buf.String()
+}
+
+func TestPostProcessRedactsCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name    string
+		req     any
+		resp    any
+		err     error
+		secrets []string
+	}{
+		{
+			name:    "register",
+			req:     &user.RegisterReq{Username: "alice", Email: "alice@example.com", Password: "register-pw-1"},
+			resp:    &user.RegisterResp{Resp: dto.Success()},
+			secrets: []string{"register-pw-1"},
+		},
+		{
+			name: "login",
+			req:  &user.LoginReq{Email: "alice@example.com", Password: "login-pw-1"},
+			resp: &user.LoginResp{
+				Resp:         dto.Success(),
+				AccessToken:  "eyJhbGciOi.access.token",
+				RefreshToken: "opaque-refresh-token",
+				ExpiresIn:    900,
+			},
+			secrets: []string{"login-pw-1", "eyJhbGciOi.access.token", "opaque-refresh-token"},
+		},
+		{
+			name:    "login failed",
+			req:     &user.LoginReq{Email: "alice@example.com", Password: "wrong-pw-1"},
+			err:     errorx.ErrPasswordIncorrect,
+			secrets: []string{"wrong-pw-1"},
+		},
+		{
+			name:    "two factor login",
+			req:     &user.LoginTwoFactorReq{MFAToken: "mfa-pending-token", Code: "123456", RecoveryCode: "abcd-efgh"},
+			resp:    &user.LoginResp{Resp: dto.Success(), MFARequired: true, MFAToken: "mfa-next-token"},
+			secrets: []string{"mfa-pending-token", "123456", "abcd-efgh", "mfa-next-token"},
+		},
+		{
+			name:    "change password",
+			req:     &user.ChangePasswordReq{OldPassword: "old-pw-1", NewPassword: "new-pw-1", ConfirmPassword: "new-pw-1"},
+			resp:    &user.ChangePasswordResp{Resp: dto.Success()},
+			secrets: []string{"old-pw-1", "new-pw-1"},
+		},
+		{
+			name:    "delete account",
+			req:     &user.DeleteAccountReq{Password: "delete-pw-1", Confirmation: "我确认删除账号 alice"},
+			resp:    &user.DeleteAccountResp{Resp: dto.Success()},
+			secrets: []string{"delete-pw-1"},
+		},
+		{
+			name:    "refresh token",
+			req:     &user.RefreshTokenReq{RefreshToken: "old-refresh-token"},
+			resp:    &user.RefreshTokenResp{Resp: dto.Success(), AccessToken: "new.access.token", RefreshToken: "new-refresh-token"},
+			secrets: []string{"old-refresh-token", "new.access.token", "new-refresh-token"},
+		},
+		{
+			name:    "reset password",
+			req:     &user.ResetPasswordReq{Token: "reset-token-1", NewPassword: "reset-pw-1", ConfirmPassword: "reset-pw-1"},
+			resp:    &user.ResetPasswordResp{Resp: dto.Success()},
+			secrets: []string{"reset-token-1", "reset-pw-1"},
+		},
+		{
+			name:    "verify email",
+			req:     &user.VerifyEmailReq{Token: "verify-token-1"},
+			resp:    &user.VerifyEmailResp{Resp: dto.Success()},
+			secrets: []string{"verify-token-1"},
+		},
+		{
+			name:    "totp setup",
+			req:     &struct{}{},
+			resp:    &user.SetupTOTPResp{Resp: dto.Success(), Secret: "JBSWY3DPEHPK3PXP", URI: "otpauth://totp/DAOld:alice?secret=JBSWY3DPEHPK3PXP"},
+			secrets: []string{"JBSWY3DPEHPK3PXP"},
+		},
+		{
+			name:    "totp confirm",
+			req:     &user.ConfirmTOTPReq{Code: "654321"},
+			resp:    &user.ConfirmTOTPResp{Resp: dto.Success(), RecoveryCodes: []string{"rc-1111", "rc-2222"}},
+			secrets: []string{"654321", "rc-1111", "rc-2222"},
+		},
+		{
+			name:    "totp disable",
+			req:     &user.DisableTOTPReq{Password: "disable-pw-1", Code: "112233", RecoveryCode: "rc-3333"},
+			resp:    &user.DisableTOTPResp{Resp: dto.Success()},
+			secrets: []string{"disable-pw-1", "112233", "rc-3333"},
+		},
+		{
+			name:    "untagged body",
+			req:     map[string]any{"email": "alice@example.com", "password": "raw-pw-1"},
+			resp:    &user.LoginResp{Resp: dto.Success()},
+			secrets: []string{"raw-pw-1"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("POST", "/", nil)
+
+			output := captureLogs(t, func() {
+				PostProcess(c, tc.req, tc.resp, tc.err)
+			})
+			if output == "" {
+				t.Fatal("PostProcess() wrote no log output")
+			}
+			for _, secret := range tc.secrets {
+				if strings.Contains(output, secret) {
+					t.Errorf("log output contains credential %q: %s", secret, output)
+				}
+			}
+		})
+	}
+}
@@ -1,25 +1,156 @@
 package router
 
 import (
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
 	"github.com/NoANameGroup/DAOld-Backend/internal/handler"
+	"github.com/NoANameGroup/DAOld-Backend/internal/middleware"
+	"github.com/NoANameGroup/DAOld-Backend/internal/provider"
+	"github.com/NoANameGroup/DAOld-Backend/internal/ratelimit"
 	"github.com/gin-gonic/gin"
 )
 
 func SetupRoutes() *gin.Engine {
-	router := gin.Default()
+	router := newEngine(provider.Get().Config.TrustedProxies)
+	router.Use(middleware.RequestContext(), middleware.RateLimit(ratelimit.RuleGlobal))
+
+	// JWKS
+	router.GET("/.well-known/jwks.json", handler.JWKS)
 
 	// UserApi
 	userGroup := router.Group("/api/users")
+	userPublicGroup := userGroup.Group("", middleware.RateLimit(ratelimit.RuleAuth))
+	{
+		userPublicGroup.POST("/register", handler.Register)
+		userPublicGroup.POST("/login", handler.Login)
+		userPublicGroup.POST("/login/2fa", handler.LoginTwoFactor)
+		userPublicGroup.POST("/siwe/nonce", handler.SIWENonce)
+		userPublicGroup.POST("/siwe/login", handler.SIWELogin)
+		userPublicGroup.POST("/token/refresh", handler.RefreshToken)
+		userPublicGroup.POST("/email/verify", handler.VerifyEmail)
+		userPublicGroup.POST("/password/forgot", handler.ForgotPassword)
+		userPublicGroup.POST("/password/reset", handler.ResetPassword)
+	}
+	userAuthGroup := userGroup.Group("", middleware.Authenticate(), middleware.RateLimit(ratelimit.RuleUser))
+	{
+		userAuthGroup.GET("/me", handler.GetMyProfile)
+		userAuthGroup.PATCH("/me", handler.UpdateMyProfile)
+		userAuthGroup.POST("/email/verify/resend", handler.ResendVerificationEmail)
+		userAuthGroup.GET("/me/wallets", handler.ListWallets)
+		userAuthGroup.POST("/me/wallets", middleware.RequireVerifiedEmail(), handler.AddWallet)
+		userAuthGroup.POST("/me/wallets/challenge", handler.WalletChallenge)
+		userAuthGroup.POST("/me/wallets/:address/primary", handler.SetPrimaryWallet)
+		userAuthGroup.DELETE("/me/wallets/:address", handler.RemoveWallet)
+		userAuthGroup.GET("/me/invites", handler.ListMyInviteCodes)
+		userAuthGroup.POST("/me/invites", middleware.RequireVerifiedEmail(), middleware.RequireTwoFactor(), handler.CreateInviteCode)
+		userAuthGroup.GET("/me/invites/tree", handler.GetMyInviteTree)
+		userAuthGroup.DELETE("/me/invites/:code", handler.RevokeInviteCode)
+		userAuthGroup.GET("/me/delegations", handler.ListMyDelegations)
+		userAuthGroup.POST("/me/delegations", middleware.RequireVerifiedEmail(), handler.Delegate)
+		userAuthGroup.DELETE("/me/delegations", handler.RevokeDelegation)
+		userAuthGroup.GET("/me/delegations/incoming", handler.ListDelegators)
+		userAuthGroup.GET("/me/membership-proof", handler.GetMembershipProof)
+		userAuthGroup.PATCH("/:userId/role", middleware.RequireVerifiedEmail(), middleware.RequireTwoFactor(), middleware.RequirePermission(auth.PermUserRoleUpdate), handler.UpdateUserRole)
+	}
+	// 登录会话、两步验证与 API Key 管理只能使用访问令牌
+	userAccountGroup := userAuthGroup.Group("", middleware.DenyAPIKey())
+	{
+		userAccountGroup.POST("/logout", handler.Logout)
+		userAccountGroup.POST("/logout/all", handler.LogoutAll)
+		userAccountGroup.POST("/me/reauth/challenge", handler.ReauthChallenge)
+		userAccountGroup.POST("/me/2fa/totp", handler.SetupTOTP)
+		userAccountGroup.POST("/me/2fa/totp/confirm", handler.ConfirmTOTP)
+		userAccountGroup.GET("/me/sessions", handler.ListSessions)
+		userAccountGroup.DELETE("/me/sessions", handler.RevokeOtherSessions)
+		userAccountGroup.DELETE("/me/sessions/:sessionId", handler.RevokeSession)
+		userAccountGroup.GET("/me/api-keys", handler.ListMyAPIKeys)
+		userAccountGroup.POST("/me/api-keys", handler.CreateAPIKey)
+		userAccountGroup.DELETE("/me/api-keys/:keyId", handler.RevokeMyAPIKey)
+	}
+	// 需要校验密码或两步验证码的接口
+	userSensitiveGroup := userAccountGroup.Group("", middleware.RateLimit(ratelimit.RuleSensitive))
+	{
+		userSensitiveGroup.PATCH("/me/password", handler.ChangePassword)
+		userSensitiveGroup.DELETE("/me", handler.DeleteAccount)
+		userSensitiveGroup.DELETE("/me/2fa/totp", handler.DisableTOTP)
+		userSensitiveGroup.POST("/me/2fa/recovery-codes", handler.RegenerateRecoveryCodes)
+	}
+
+	// AdminApi
+	adminGroup := router.Group("/api/admin", middleware.Authenticate(), middleware.RateLimit(ratelimit.RuleUser), middleware.RequireVerifiedEmail(), middleware.RequireTwoFactor())
+	{
+		adminGroup.GET("/users", middleware.RequirePermission(auth.PermUserRead), handler.ListUsers)
+		adminGroup.POST("/users/:userId/suspend", middleware.RequirePermission(auth.PermUserSuspend), handler.SuspendUser)
+		adminGroup.POST("/users/:userId/ban", middleware.RequirePermission(auth.PermUserBan), handler.BanUser)
+		adminGroup.POST("/users/:userId/unban", middleware.RequirePermission(auth.PermUserBan), handler.UnbanUser)
+		adminGroup.POST("/users/:userId/unlock", middleware.RequirePermission(auth.PermUserUnlock), handler.UnlockUser)
+		adminGroup.POST("/ips/:ip/unlock", middleware.RequirePermission(auth.PermUserUnlock), handler.UnlockIP)
+		adminGroup.GET("/users/:userId/invites/tree", middleware.RequirePermission(auth.PermUserRead), handler.GetUserInviteTree)
+		adminGroup.GET("/users/:userId/api-keys", middleware.RequirePermission(auth.PermUserRead), handler.ListUserAPIKeys)
+		adminGroup.DELETE("/api-keys/:keyId", middleware.RequirePermission(auth.PermAPIKeyRevoke), handler.RevokeAPIKey)
+		adminGroup.POST("/snapshots", middleware.RequirePermission(auth.PermSnapshotCreate), handler.CreateSnapshot)
+		adminGroup.GET("/snapshots", middleware.RequirePermission(auth.PermUserRead), handler.ListSnapshots)
+		adminGroup.GET("/snapshots/:id", middleware.RequirePermission(auth.PermUserRead), handler.GetSnapshot)
+		adminGroup.GET("/snapshots/:id/members", middleware.RequirePermission(auth.PermUserRead), handler.ListSnapshotMembers)
+		adminGroup.GET("/snapshots/:id/members/:userId", middleware.RequirePermission(auth.PermUserRead), handler.CheckSnapshotMember)
+		adminGroup.GET("/snapshots/:id/diff", middleware.RequirePermission(auth.PermUserRead), handler.DiffSnapshots)
+		adminGroup.POST("/membership/roots", middleware.RequirePermission(auth.PermMerklePublish), handler.PublishMerkleRoot)
+		adminGroup.GET("/audit-events", middleware.RequirePermission(auth.PermAuditRead), handler.ListAuditEvents)
+	}
+
+	// MembershipApi
+	router.GET("/api/membership/root", handler.GetMerkleRoot)
+
+	// OrganizationApi
+	orgGroup := router.Group("/api/organizations")
+	{
+		orgGroup.GET("", handler.ListOrganizations)
+		orgGroup.GET("/:slug", handler.GetOrganization)
+	}
+	orgAuthGroup := orgGroup.Group("", middleware.Authenticate(), middleware.RateLimit(ratelimit.RuleUser), middleware.RequireVerifiedEmail())
+	{
+		orgAuthGroup.POST("", handler.CreateOrganization)
+		orgAuthGroup.PATCH("/:slug", handler.UpdateOrganization)
+		orgAuthGroup.POST("/:slug/archive", handler.ArchiveOrganization)
+	}
+
+	// ProposalApi
+	proposalGroup := router.Group("/api/proposals")
+	{
+		proposalGroup.GET("", handler.ListProposals)
+		proposalGroup.GET("/:id", handler.GetProposal)
+	}
+	proposalAuthGroup := proposalGroup.Group("", middleware.Authenticate(), middleware.RateLimit(ratelimit.RuleUser), middleware.RequireVerifiedEmail())
+	{
+		proposalAuthGroup.POST("", handler.CreateProposal)
+		proposalAuthGroup.PATCH("/:id", handler.UpdateProposal)
+		proposalAuthGroup.POST("/:id/activate", handler.ActivateProposal)
+		proposalAuthGroup.POST("/:id/close", middleware.RequirePermission(auth.PermProposalManage), handler.CloseProposal)
+		proposalAuthGroup.POST("/:id/execute", middleware.RequirePermission(auth.PermProposalManage), handler.ExecuteProposal)
+		proposalAuthGroup.POST("/:id/cancel", handler.CancelProposal)
+	}
+
+	// PollApi
+	pollGroup := router.Group("/api/polls")
+	{
+		pollGroup.GET("/:id", handler.GetPoll)
+		pollGroup.GET("/:id/result", handler.GetPollResult)
+	}
+	pollAuthGroup := pollGroup.Group("", middleware.Authenticate(), middleware.RateLimit(ratelimit.RuleUser))
 	{
-		userGroup.POST("/register", handler.Register)
-		userGroup.POST("/login", handler.Login)
-		userGroup.GET("/me", handler.GetMyProfile)
-		userGroup.PATCH("/me", handler.UpdateMyProfile)
-		userGroup.PATCH("/me/password", handler.ChangePassword)
-		userGroup.DELETE("/me", handler.DeleteAccount)
-		userGroup.POST("/logout", handler.Logout)
-		userGroup.PATCH("/:userId/role", handler.UpdateUserRole)
+		pollAuthGroup.POST("", middleware.RequireVerifiedEmail(), handler.CreatePoll)
+		pollAuthGroup.GET("/:id/ballot", handler.GetMyBallot)
+		pollAuthGroup.PUT("/:id/ballot", middleware.RequireVerifiedEmail(), handler.CastBallot)
 	}
 
 	return router
 }
+
+// newEngine 创建只信任给定代理的 gin 引擎, 其他来源的 X-Forwarded-For 等请求头会被忽略,
+// 客户端 IP 用于限流与登录锁定, 不能由客户端自行指定
+func newEngine(trustedProxies []string) *gin.Engine {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		panic(err)
+	}
+	return router
+}
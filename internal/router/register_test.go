@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func TestClientIPTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		want           string
+	}{
+		{"no trusted proxies", nil, "203.0.113.7:4321", "203.0.113.7"},
+		{"untrusted peer", []string{"10.0.0.0/8"}, "203.0.113.7:4321", "203.0.113.7"},
+		{"trusted proxy", []string{"10.0.0.0/8"}, "10.1.2.3:4321", "198.51.100.9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newEngine(tt.trustedProxies)
+			router.Use(middleware.RequestContext())
+			router.GET("/ip", func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString(consts.ContextClientIP))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "198.51.100.9")
+			req.Header.Set("X-Real-IP", "198.51.100.9")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("client IP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEngineRejectsInvalidProxy(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("newEngine() did not panic on an invalid proxy")
+		}
+	}()
+	newEngine([]string{"not-an-ip"})
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/admin"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/jwt"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type IAdminService interface {
+	SuspendUser(ctx context.Context, req *admin.SuspendUserReq) (*admin.SuspendUserResp, error)
+	BanUser(ctx context.Context, req *admin.BanUserReq) (*admin.BanUserResp, error)
+	UnbanUser(ctx context.Context) (*admin.UnbanUserResp, error)
+	ListUsers(ctx context.Context, req *admin.ListUsersReq) (*admin.ListUsersResp, error)
+	ExportUsers(ctx context.Context, req *admin.ListUsersReq, w io.Writer) error
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+const (
+	ExportFormatJSON  = "json"
+	ExportFormatCSV   = "csv"
+	ExportFormatJSONL = "jsonl"
+)
+
+// userSortFields 允许排序的字段及其取值方式
+var userSortFields = map[string]func(u *model.User) any{
+	consts.CreatedAt:   func(u *model.User) any { return u.CreatedAt },
+	consts.LastLoginAt: func(u *model.User) any { return u.LastLoginAt },
+	consts.Username:    func(u *model.User) any { return u.Username },
+	consts.Email:       func(u *model.User) any { return u.Email },
+}
+
+type AdminService struct {
+	UserRepository         *repository.UserRepository
+	RefreshTokenRepository *repository.RefreshTokenRepository
+	SessionRepository      *repository.SessionRepository
+	TokenManager           *jwt.Manager
+	Authorizer             *auth.Authorizer
+	AuditService           *AuditService
+}
+
+var AdminServiceSet = wire.NewSet(
+	wire.Struct(new(AdminService), "*"),
+	wire.Bind(new(IAdminService), new(*AdminService)),
+)
+
+func (s *AdminService) SuspendUser(ctx context.Context, req *admin.SuspendUserReq) (*admin.SuspendUserResp, error) {
+	// 获取操作者与目标用户
+	operatorId, target, err := s.getOperatorAndTarget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 暂停必须有结束时间
+	if !req.Until.After(time.Now()) {
+		return nil, errorx.ErrSuspendUntilInvalid
+	}
+
+	// 更新状态
+	log.CtxInfo(ctx, "user %s suspends user %s until %s, reason: %s", operatorId.Hex(), target.ID.Hex(), req.Until, req.Reason)
+	if err = s.UserRepository.UpdateStatus(ctx, target.ID, enum.StatusSuspended, req.Reason, req.Until); err != nil {
+		log.CtxError(ctx, "failed to suspend user: %v", err)
+		return nil, err
+	}
+	s.recordStatusChange(ctx, AuditActionUserSuspend, target, enum.StatusSuspended, req.Reason)
+
+	return &admin.SuspendUserResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+func (s *AdminService) BanUser(ctx context.Context, req *admin.BanUserReq) (*admin.BanUserResp, error) {
+	// 获取操作者与目标用户
+	operatorId, target, err := s.getOperatorAndTarget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 更新状态
+	log.CtxInfo(ctx, "user %s bans user %s, reason: %s", operatorId.Hex(), target.ID.Hex(), req.Reason)
+	if err = s.UserRepository.UpdateStatus(ctx, target.ID, enum.StatusBanned, req.Reason, time.Time{}); err != nil {
+		log.CtxError(ctx, "failed to ban user: %v", err)
+		return nil, err
+	}
+	s.recordStatusChange(ctx, AuditActionUserBan, target, enum.StatusBanned, req.Reason)
+
+	// 吊销该用户的全部会话
+	if err = revokeSessions(ctx, s.TokenManager, s.RefreshTokenRepository, s.SessionRepository, target.ID); err != nil {
+		return nil, err
+	}
+
+	return &admin.BanUserResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// UnbanUser 解除封禁或暂停, 恢复为活跃状态
+func (s *AdminService) UnbanUser(ctx context.Context) (*admin.UnbanUserResp, error) {
+	// 获取操作者与目标用户
+	operatorId, target, err := s.getOperatorAndTarget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 更新状态
+	log.CtxInfo(ctx, "user %s reactivates user %s", operatorId.Hex(), target.ID.Hex())
+	if err = s.UserRepository.UpdateStatus(ctx, target.ID, enum.StatusActive, "", time.Time{}); err != nil {
+		log.CtxError(ctx, "failed to unban user: %v", err)
+		return nil, err
+	}
+	s.recordStatusChange(ctx, AuditActionUserUnban, target, enum.StatusActive, "")
+
+	return &admin.UnbanUserResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// getOperatorAndTarget 获取当前操作者与路径中的目标用户
+// 目标用户必须存在, 不能是操作者本人, 且角色必须严格低于操作者
+func (s *AdminService) getOperatorAndTarget(ctx context.Context) (bson.ObjectID, *model.User, error) {
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return bson.NilObjectID, nil, err
+	}
+	operatorId := principal.UserID
+
+	targetId, ok := ctx.Value(consts.ContextTargetID).(bson.ObjectID)
+	if !ok {
+		return bson.NilObjectID, nil, errorx.ErrContextUserIDInvalid
+	}
+	if targetId == operatorId {
+		return bson.NilObjectID, nil, errorx.ErrCannotModifySelf
+	}
+
+	target, err := s.UserRepository.FindUserByUserID(ctx, targetId)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return bson.NilObjectID, nil, errorx.ErrUserNotFound
+		}
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return bson.NilObjectID, nil, err
+	}
+	if !s.Authorizer.Outranks(principal.Role, target.Role) {
+		log.CtxInfo(ctx, "user %s (%s) cannot operate on user %s (%s)", operatorId.Hex(), enum.GetUserRoleDesc(principal.Role), targetId.Hex(), enum.GetUserRoleDesc(target.Role))
+		return bson.NilObjectID, nil, errorx.ErrTargetRoleNotLower
+	}
+
+	return operatorId, target, nil
+}
+
+// recordStatusChange 记录账号状态变更的审计事件
+func (s *AdminService) recordStatusChange(ctx context.Context, action string, target *model.User, status enum.UserStatus, reason string) {
+	s.AuditService.Record(ctx, &AuditEntry{
+		Action:     action,
+		TargetType: AuditTargetUser,
+		TargetID:   target.ID.Hex(),
+		Changes: []model.AuditChange{
+			{Field: consts.Status, Before: enum.GetUserStatusDesc(target.Status), After: enum.GetUserStatusDesc(status)},
+			{Field: consts.StatusReason, Before: target.StatusReason, After: reason},
+		},
+	})
+}
+
+func (s *AdminService) ListUsers(ctx context.Context, req *admin.ListUsersReq) (*admin.ListUsersResp, error) {
+	var err error
+	var total int64
+	var users []*model.User
+	var after *repository.UserCursor
+
+	// 构造查询条件
+	query, sort, err := buildUserQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 计算分页, 有游标时使用游标分页
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	} else if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	skip := int64(0)
+	if req.Cursor != "" {
+		if after, err = decodeUserCursor(req.Cursor, sort.Field); err != nil {
+			return nil, err
+		}
+	} else if req.PageNum > 1 {
+		skip = int64(req.PageNum-1) * int64(pageSize)
+	}
+
+	// 多查一条用于判断是否还有下一页
+	if users, err = s.UserRepository.FindUsers(ctx, query, sort, after, skip, int64(pageSize)+1); err != nil {
+		log.CtxError(ctx, "failed to find users: %v", err)
+		return nil, err
+	}
+	if total, err = s.UserRepository.CountUsers(ctx, query); err != nil {
+		log.CtxError(ctx, "failed to count users: %v", err)
+		return nil, err
+	}
+
+	nextCursor := ""
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		nextCursor = encodeUserCursor(users[pageSize-1], sort.Field)
+	}
+
+	vos := make([]*admin.UserVO, 0, len(users))
+	for _, u := range users {
+		vos = append(vos, &admin.UserVO{ID: u.ID, UserVO: toUserVO(u)})
+	}
+
+	return &admin.ListUsersResp{
+		Resp:       dto.Success(),
+		Total:      total,
+		Users:      vos,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// ExportUsers 将全部匹配的用户以 CSV 或 JSON Lines 格式写入 w, 参数错误时不会写入任何内容
+func (s *AdminService) ExportUsers(ctx context.Context, req *admin.ListUsersReq, w io.Writer) error {
+	// 构造查询条件
+	query, sort, err := buildUserQuery(req)
+	if err != nil {
+		return err
+	}
+
+	operatorId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return err
+	}
+	log.CtxInfo(ctx, "user %s exports users as %s", operatorId.Hex(), req.Format)
+
+	switch req.Format {
+	case ExportFormatCSV:
+		cw := csv.NewWriter(w)
+		if err = cw.Write([]string{"id", "username", "firstName", "lastName", "email", "phone", "role", "status", "gender", "createdAt", "lastLoginAt"}); err != nil {
+			return err
+		}
+		err = s.UserRepository.ScanUsers(ctx, query, sort, func(u *model.User) error {
+			return cw.Write([]string{
+				u.ID.Hex(), u.Username, u.FirstName, u.LastName, u.Email, u.Phone,
+				enum.GetUserRoleDesc(u.Role), enum.GetUserStatusDesc(u.Status), enum.GetUserGenderDesc(u.Gender),
+				u.CreatedAt.Format(time.RFC3339), u.LastLoginAt.Format(time.RFC3339),
+			})
+		})
+		cw.Flush()
+		if err == nil {
+			err = cw.Error()
+		}
+	case ExportFormatJSONL:
+		enc := json.NewEncoder(w)
+		err = s.UserRepository.ScanUsers(ctx, query, sort, func(u *model.User) error {
+			return enc.Encode(&admin.UserVO{ID: u.ID, UserVO: toUserVO(u)})
+		})
+	default:
+		return errorx.ErrExportFormatInvalid
+	}
+	if err != nil {
+		log.CtxError(ctx, "failed to export users: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// buildUserQuery 将请求参数转换为查询条件与排序
+func buildUserQuery(req *admin.ListUsersReq) (*repository.UserQuery, repository.UserSort, error) {
+	query := &repository.UserQuery{
+		CreatedFrom:   req.CreatedFrom,
+		CreatedTo:     req.CreatedTo,
+		LastLoginFrom: req.LastLoginFrom,
+		LastLoginTo:   req.LastLoginTo,
+		Keyword:       strings.TrimSpace(req.Keyword),
+		TextSearch:    req.SearchMode == "text",
+	}
+	sort := repository.UserSort{Field: consts.CreatedAt, Desc: req.SortOrder != "asc"}
+
+	if req.Role != "" {
+		if query.Role = enum.GetUserRoleCode(req.Role); query.Role == 0 {
+			return nil, sort, errorx.ErrUserRoleInvalid
+		}
+	}
+	if req.Status != "" {
+		if query.Status = enum.GetUserStatusCode(req.Status); query.Status == 0 {
+			return nil, sort, errorx.ErrUserStatusInvalid
+		}
+	}
+	if req.Gender != "" {
+		if query.Gender = enum.GetUserGenderCode(req.Gender); query.Gender == 0 {
+			return nil, sort, errorx.ErrUserGenderInvalid
+		}
+	}
+	if req.SortBy != "" {
+		if _, ok := userSortFields[req.SortBy]; !ok {
+			return nil, sort, errorx.ErrSortFieldInvalid
+		}
+		sort.Field = req.SortBy
+	}
+
+	return query, sort, nil
+}
+
+// userCursor 游标的序列化格式, 时间字段以 RFC3339Nano 字符串保存
+type userCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func encodeUserCursor(u *model.User, field string) string {
+	c := userCursor{ID: u.ID.Hex()}
+	switch v := userSortFields[field](u).(type) {
+	case time.Time:
+		c.Value = v.UTC().Format(time.RFC3339Nano)
+	case string:
+		c.Value = v
+	}
+	data, _ := json.Marshal(&c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUserCursor(cursor, field string) (*repository.UserCursor, error) {
+	var c userCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || json.Unmarshal(data, &c) != nil {
+		return nil, errorx.ErrCursorInvalid
+	}
+
+	id, err := bson.ObjectIDFromHex(c.ID)
+	if err != nil {
+		return nil, errorx.ErrCursorInvalid
+	}
+
+	after := &repository.UserCursor{ID: id, Value: c.Value}
+	if field == consts.CreatedAt || field == consts.LastLoginAt {
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, errorx.ErrCursorInvalid
+		}
+		after.Value = t
+	}
+	return after, nil
+}
@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/security"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	apiKeyPrefix          = "daold_"
+	apiKeyDisplayLen      = len(apiKeyPrefix) + 6 // 列表中展示的密钥开头长度
+	apiKeyUsedKeyPrefix   = "api_key_used:"
+	apiKeyUsedInterval    = time.Minute // 最后使用时间的最小更新间隔
+	maxAPIKeyNameLen      = 64
+	maxAPIKeyAllowedIPs   = 20
+	maxAPIKeyScopeEntries = 32
+)
+
+// apiKeyScopePattern scope 为 "*"、read、write 或权限名, 权限名可以以 ".*" 结尾表示通配
+var apiKeyScopePattern = regexp.MustCompile(`^(\*|[a-z]+(\.[a-z]+)*(\.\*)?)$`)
+
+type IAPIKeyService interface {
+	CreateAPIKey(ctx context.Context, req *user.CreateAPIKeyReq) (*user.CreateAPIKeyResp, error)
+	ListMyAPIKeys(ctx context.Context) (*user.ListAPIKeysResp, error)
+	RevokeMyAPIKey(ctx context.Context, req *user.RevokeAPIKeyReq) (*user.RevokeAPIKeyResp, error)
+	ListUserAPIKeys(ctx context.Context) (*user.ListAPIKeysResp, error)
+	RevokeAPIKey(ctx context.Context, req *user.RevokeAPIKeyReq) (*user.RevokeAPIKeyResp, error)
+}
+
+type APIKeyService struct {
+	Config           *config.Config
+	APIKeyRepository *repository.APIKeyRepository
+	UserRepository   *repository.UserRepository
+	Authorizer       *auth.Authorizer
+	AuditService     *AuditService
+	Store            kv.Store
+}
+
+var APIKeyServiceSet = wire.NewSet(
+	wire.Struct(new(APIKeyService), "*"),
+	wire.Bind(new(IAPIKeyService), new(*APIKeyService)),
+)
+
+// CreateAPIKey 创建 API Key, 密钥明文只在响应中返回一次
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, req *user.CreateAPIKeyReq) (*user.CreateAPIKeyResp, error) {
+	var err error
+	var active int64
+	var scopes, allowedIPs []string
+	var key string
+
+	// 获取当前用户
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验参数
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxAPIKeyNameLen {
+		return nil, errorx.ErrAPIKeyParamInvalid
+	}
+	if scopes, err = s.normalizeScopes(principal.Role, req.Scopes); err != nil {
+		return nil, err
+	}
+	if allowedIPs, err = normalizeAllowedIPs(req.AllowedIPs); err != nil {
+		return nil, err
+	}
+	expiresIn := req.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = s.Config.APIKey.DefaultExpire
+	}
+	if expiresIn < 0 || expiresIn > s.Config.APIKey.MaxExpire {
+		return nil, errorx.ErrAPIKeyParamInvalid
+	}
+
+	// 检查数量上限
+	now := time.Now()
+	if active, err = s.APIKeyRepository.CountActiveByUserID(ctx, principal.UserID, now); err != nil {
+		return nil, err
+	}
+	if active >= s.Config.APIKey.MaxPerUser {
+		return nil, errorx.ErrAPIKeyLimitExceeded
+	}
+
+	// 生成密钥, 数据库中只保存哈希
+	if key, err = security.GenerateRandomToken(32); err != nil {
+		log.CtxError(ctx, "failed to generate api key: %v", err)
+		return nil, err
+	}
+	key = apiKeyPrefix + key
+	apiKey := &model.APIKey{
+		ID:         bson.NewObjectID(),
+		UserID:     principal.UserID,
+		Name:       name,
+		Prefix:     key[:apiKeyDisplayLen],
+		KeyHash:    security.HashToken(key),
+		Scopes:     scopes,
+		AllowedIPs: allowedIPs,
+		ExpiresAt:  now.Add(time.Duration(expiresIn) * time.Second),
+		CreatedAt:  now,
+	}
+	if err = s.APIKeyRepository.Insert(ctx, apiKey); err != nil {
+		return nil, err
+	}
+	s.AuditService.Record(ctx, &AuditEntry{
+		Action:     AuditActionAPIKeyCreate,
+		TargetType: AuditTargetAPIKey,
+		TargetID:   apiKey.ID.Hex(),
+		Changes: []model.AuditChange{
+			{Field: consts.Name, After: apiKey.Name},
+			{Field: "scopes", After: strings.Join(apiKey.Scopes, ",")},
+			{Field: "allowedIps", After: strings.Join(apiKey.AllowedIPs, ",")},
+			{Field: consts.ExpiresAt, After: apiKey.ExpiresAt.UTC().Format(time.RFC3339)},
+		},
+	})
+
+	return &user.CreateAPIKeyResp{
+		Resp:     dto.Success(),
+		Key:      key,
+		APIKeyVO: toAPIKeyVO(apiKey, now),
+	}, nil
+}
+
+func (s *APIKeyService) ListMyAPIKeys(ctx context.Context) (*user.ListAPIKeysResp, error) {
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.listAPIKeys(ctx, userId)
+}
+
+// RevokeMyAPIKey 吊销自己的 API Key
+func (s *APIKeyService) RevokeMyAPIKey(ctx context.Context, req *user.RevokeAPIKeyReq) (*user.RevokeAPIKeyResp, error) {
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取密钥, 不属于自己的密钥视为不存在
+	apiKey, err := s.findAPIKey(ctx, req.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.UserID != userId {
+		return nil, errorx.ErrAPIKeyNotFound
+	}
+
+	if err = s.revoke(ctx, userId, apiKey); err != nil {
+		return nil, err
+	}
+	return &user.RevokeAPIKeyResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// ListUserAPIKeys 管理员查看指定用户的 API Key
+func (s *APIKeyService) ListUserAPIKeys(ctx context.Context) (*user.ListAPIKeysResp, error) {
+	// 从路径参数获取用户ID
+	targetId, ok := ctx.Value(consts.ContextTargetID).(bson.ObjectID)
+	if !ok {
+		return nil, errorx.ErrContextUserIDInvalid
+	}
+
+	// 校验用户是否存在
+	if _, err := s.UserRepository.FindUserByUserID(ctx, targetId); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrUserNotFound
+		}
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+
+	return s.listAPIKeys(ctx, targetId)
+}
+
+// RevokeAPIKey 管理员吊销其他用户的 API Key, 密钥所有者的角色必须严格低于自己
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, req *user.RevokeAPIKeyReq) (*user.RevokeAPIKeyResp, error) {
+	// 获取当前用户
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	operatorId := principal.UserID
+
+	// 获取密钥
+	apiKey, err := s.findAPIKey(ctx, req.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验密钥所有者的角色, 所有者已删除时不再校验
+	if apiKey.UserID != operatorId {
+		owner, err := s.UserRepository.FindUserByUserID(ctx, apiKey.UserID)
+		if err != nil && !errors.Is(err, monc.ErrNotFound) {
+			log.CtxError(ctx, "failed to find user: %v", err)
+			return nil, err
+		}
+		if owner != nil && !s.Authorizer.Outranks(principal.Role, owner.Role) {
+			log.CtxInfo(ctx, "user %s (%s) cannot revoke api key of user %s (%s)", operatorId.Hex(), enum.GetUserRoleDesc(principal.Role), owner.ID.Hex(), enum.GetUserRoleDesc(owner.Role))
+			return nil, errorx.ErrTargetRoleNotLower
+		}
+	}
+
+	log.CtxInfo(ctx, "user %s revokes api key %s of user %s", operatorId.Hex(), apiKey.ID.Hex(), apiKey.UserID.Hex())
+	if err = s.revoke(ctx, operatorId, apiKey); err != nil {
+		return nil, err
+	}
+	return &user.RevokeAPIKeyResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// verify 校验 API Key 的状态、有效期与来源 IP, 通过后更新最后使用时间
+func (s *APIKeyService) verify(ctx context.Context, key string) (*model.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, errorx.ErrAPIKeyInvalid
+	}
+
+	// 查找密钥
+	apiKey, err := s.APIKeyRepository.FindByKeyHash(ctx, security.HashToken(key))
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrAPIKeyInvalid
+		}
+		log.CtxError(ctx, "failed to find api key: %v", err)
+		return nil, err
+	}
+
+	// 校验状态与有效期
+	now := time.Now()
+	if apiKey.Revoked || !now.Before(apiKey.ExpiresAt) {
+		log.CtxInfo(ctx, "api key %s revoked or expired", apiKey.ID.Hex())
+		return nil, errorx.ErrAPIKeyInvalid
+	}
+
+	// 校验来源 IP
+	ip, _ := ctx.Value(consts.ContextClientIP).(string)
+	if !ipAllowed(apiKey.AllowedIPs, ip) {
+		log.CtxInfo(ctx, "api key %s rejected from ip %s", apiKey.ID.Hex(), ip)
+		return nil, errorx.ErrAPIKeyIPNotAllowed
+	}
+
+	s.touch(ctx, apiKey.ID, ip, now)
+	return apiKey, nil
+}
+
+// touch 更新最后使用时间与 IP, 同一密钥每分钟最多写一次数据库
+// 失败只记录日志, 不影响请求
+func (s *APIKeyService) touch(ctx context.Context, id bson.ObjectID, ip string, now time.Time) {
+	key := apiKeyUsedKeyPrefix + id.Hex()
+	if _, ok, err := s.Store.Get(ctx, key); err != nil || ok {
+		return
+	}
+	if err := s.Store.Set(ctx, key, "1", apiKeyUsedInterval); err != nil {
+		log.CtxError(ctx, "failed to mark api key used: %v", err)
+		return
+	}
+	if err := s.APIKeyRepository.UpdateLastUsed(ctx, id, ip, now); err != nil {
+		log.CtxError(ctx, "failed to update api key last used: %v", err)
+	}
+}
+
+func (s *APIKeyService) listAPIKeys(ctx context.Context, userId bson.ObjectID) (*user.ListAPIKeysResp, error) {
+	keys, err := s.APIKeyRepository.FindByUserID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	vos := make([]*user.APIKeyVO, 0, len(keys))
+	for _, key := range keys {
+		vos = append(vos, toAPIKeyVO(key, now))
+	}
+	return &user.ListAPIKeysResp{
+		Resp:    dto.Success(),
+		APIKeys: vos,
+	}, nil
+}
+
+func (s *APIKeyService) findAPIKey(ctx context.Context, keyId string) (*model.APIKey, error) {
+	id, err := bson.ObjectIDFromHex(keyId)
+	if err != nil {
+		return nil, errorx.ErrAPIKeyIDInvalid
+	}
+	apiKey, err := s.APIKeyRepository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrAPIKeyNotFound
+		}
+		log.CtxError(ctx, "failed to find api key: %v", err)
+		return nil, err
+	}
+	return apiKey, nil
+}
+
+// revoke 吊销密钥并记录审计事件, 已吊销的密钥直接返回
+func (s *APIKeyService) revoke(ctx context.Context, operatorId bson.ObjectID, apiKey *model.APIKey) error {
+	ok, err := s.APIKeyRepository.Revoke(ctx, apiKey.ID, operatorId, time.Now())
+	if err != nil || !ok {
+		return err
+	}
+	s.AuditService.Record(ctx, &AuditEntry{
+		Action:     AuditActionAPIKeyRevoke,
+		TargetType: AuditTargetAPIKey,
+		TargetID:   apiKey.ID.Hex(),
+		Changes:    []model.AuditChange{{Field: consts.Revoked, Before: "false", After: "true"}},
+	})
+	return nil
+}
+
+// normalizeScopes 校验并去重 scope, 具体的权限名必须是当前角色已拥有的权限
+// 通配 scope 在使用时与角色权限取交集, 因此不会超出角色的权限
+func (s *APIKeyService) normalizeScopes(role enum.UserRole, scopes []string) ([]string, error) {
+	if len(scopes) == 0 || len(scopes) > maxAPIKeyScopeEntries {
+		return nil, errorx.ErrAPIKeyParamInvalid
+	}
+
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !apiKeyScopePattern.MatchString(scope) {
+			return nil, errorx.ErrAPIKeyParamInvalid
+		}
+		perm := auth.Permission(scope)
+		if perm != auth.ScopeRead && perm != auth.ScopeWrite && !strings.HasSuffix(scope, "*") &&
+			!s.Authorizer.HasPermission(role, perm) {
+			return nil, errorx.ErrUserPermissionsInsufficient
+		}
+		if !slices.Contains(result, scope) {
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
+
+// normalizeAllowedIPs 校验 IP 允许列表, 单个 IP 与 CIDR 都统一为规范形式
+func normalizeAllowedIPs(entries []string) ([]string, error) {
+	if len(entries) > maxAPIKeyAllowedIPs {
+		return nil, errorx.ErrAPIKeyParamInvalid
+	}
+
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			entry = addr.Unmap().String()
+		} else if prefix, err := netip.ParsePrefix(entry); err == nil {
+			entry = prefix.Masked().String()
+		} else {
+			return nil, errorx.ErrIPInvalid
+		}
+		if !slices.Contains(result, entry) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// ipAllowed 判断 IP 是否在允许列表中, 列表为空时不限制
+func ipAllowed(allowed []string, ip string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, entry := range allowed {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			if prefix.Contains(addr) {
+				return true
+			}
+		} else if entry == addr.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func toAPIKeyVO(key *model.APIKey, now time.Time) *user.APIKeyVO {
+	vo := &user.APIKeyVO{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		AllowedIPs: key.AllowedIPs,
+		ExpiresAt:  key.ExpiresAt,
+		Revoked:    key.Revoked,
+		Active:     !key.Revoked && now.Before(key.ExpiresAt),
+		LastUsedIP: key.LastUsedIP,
+		CreatedAt:  key.CreatedAt,
+	}
+	if !key.LastUsedAt.IsZero() {
+		vo.LastUsedAt = &key.LastUsedAt
+	}
+	return vo
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/admin"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// 审计动作, 以 "资源.动作" 命名
+const (
+	AuditActionUserRoleUpdate     = "user.role.update"
+	AuditActionUserSuspend        = "user.suspend"
+	AuditActionUserBan            = "user.ban"
+	AuditActionUserUnban          = "user.unban"
+	AuditActionUserDelete         = "user.delete"
+	AuditActionUserPasswordChange = "user.password.change"
+	AuditActionUserPasswordReset  = "user.password.reset"
+	AuditActionUserUnlock         = "user.unlock"
+	AuditActionIPUnlock           = "ip.unlock"
+	AuditActionLoginLocked        = "login.locked"
+	AuditActionLoginIPLocked      = "login.ip.locked"
+	AuditActionTwoFactorLocked    = "login.2fa.locked"
+	AuditActionAPIKeyCreate       = "apikey.create"
+	AuditActionAPIKeyRevoke       = "apikey.revoke"
+)
+
+const (
+	AuditTargetUser   = "user"
+	AuditTargetEmail  = "email" // 目标ID为规范化邮箱的摘要
+	AuditTargetIP     = "ip"
+	AuditTargetAPIKey = "api_key"
+
+	auditRedacted          = "[REDACTED]"
+	maxAuditAppendAttempts = 5
+	maxAuditUserAgentLen   = 512
+)
+
+type IAuditService interface {
+	ListAuditEvents(ctx context.Context, req *admin.ListAuditEventsReq) (*admin.ListAuditEventsResp, error)
+}
+
+type AuditService struct {
+	AuditEventRepository *repository.AuditEventRepository
+}
+
+var AuditServiceSet = wire.NewSet(
+	wire.Struct(new(AuditService), "*"),
+	wire.Bind(new(IAuditService), new(*AuditService)),
+)
+
+// AuditEntry 待记录的审计事件, 操作者、IP、User-Agent 与请求ID 从上下文读取
+type AuditEntry struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	Changes    []model.AuditChange
+}
+
+// AuditChainBreak 哈希链中的一处断裂
+type AuditChainBreak struct {
+	Seq    int64
+	Reason string
+}
+
+// AuditChainReport 哈希链校验结果
+// 删除链尾的事件无法仅靠链本身发现, 应将 LastSeq 与 LastHash 定期记录到外部
+type AuditChainReport struct {
+	Checked  int64
+	LastSeq  int64
+	LastHash string
+	Breaks   []AuditChainBreak
+}
+
+// auditHashContent 参与哈希计算的事件内容, 字段顺序固定
+type auditHashContent struct {
+	ID         string              `json:"id"`
+	Seq        int64               `json:"seq"`
+	PrevHash   string              `json:"prevHash"`
+	ActorID    string              `json:"actorId"`
+	Action     string              `json:"action"`
+	TargetType string              `json:"targetType"`
+	TargetID   string              `json:"targetId"`
+	Changes    []model.AuditChange `json:"changes"`
+	IP         string              `json:"ip"`
+	UserAgent  string              `json:"userAgent"`
+	RequestID  string              `json:"requestId"`
+	CreatedAt  int64               `json:"createdAt"`
+}
+
+// Record 将审计事件追加到哈希链尾部
+// 审计在业务操作完成后记录, 写入失败不影响业务结果, 只记录错误日志
+func (s *AuditService) Record(ctx context.Context, entry *AuditEntry) {
+	var err error
+	var prev *model.AuditEvent
+
+	// 从上下文读取请求信息
+	actorId, _ := auth.GetUserID(ctx)
+	ip, _ := ctx.Value(consts.ContextClientIP).(string)
+	userAgent, _ := ctx.Value(consts.ContextUserAgent).(string)
+	requestId, _ := ctx.Value(consts.ContextRequestID).(string)
+	if len(userAgent) > maxAuditUserAgentLen {
+		userAgent = userAgent[:maxAuditUserAgentLen]
+	}
+	event := &model.AuditEvent{
+		ActorID:    actorId,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Changes:    entry.Changes,
+		IP:         ip,
+		UserAgent:  userAgent,
+		RequestID:  requestId,
+	}
+
+	// 接在链尾写入, 序号冲突说明有并发写入, 重新读取链尾后重试
+	for range maxAuditAppendAttempts {
+		if prev, err = s.AuditEventRepository.FindLatest(ctx); err != nil && !errors.Is(err, monc.ErrNotFound) {
+			log.CtxError(ctx, "failed to find latest audit event: %v", err)
+			break
+		}
+		event.Seq, event.PrevHash = 1, ""
+		if prev != nil {
+			event.Seq, event.PrevHash = prev.Seq+1, prev.Hash
+		}
+		event.ID = bson.NewObjectID()
+		event.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+		event.Hash = hashAuditEvent(event)
+
+		if err = s.AuditEventRepository.Insert(ctx, event); err == nil {
+			return
+		} else if !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+	}
+	log.CtxError(ctx, "failed to record audit event %s on %s %s: %v", entry.Action, entry.TargetType, entry.TargetID, err)
+}
+
+// hashAuditEvent 计算事件哈希, 内容中包含前一事件的哈希
+func hashAuditEvent(event *model.AuditEvent) string {
+	changes := event.Changes
+	if len(changes) == 0 {
+		changes = nil
+	}
+	content, _ := json.Marshal(&auditHashContent{
+		ID:         event.ID.Hex(),
+		Seq:        event.Seq,
+		PrevHash:   event.PrevHash,
+		ActorID:    event.ActorID.Hex(),
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Changes:    changes,
+		IP:         event.IP,
+		UserAgent:  event.UserAgent,
+		RequestID:  event.RequestID,
+		CreatedAt:  event.CreatedAt.UnixMilli(),
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyChain 按序号遍历哈希链, 报告序号缺失、链接断开与内容被修改的事件
+func (s *AuditService) VerifyChain(ctx context.Context) (*AuditChainReport, error) {
+	report := &AuditChainReport{Breaks: make([]AuditChainBreak, 0)}
+	expectedSeq, prevHash := int64(1), ""
+
+	err := s.AuditEventRepository.ScanEvents(ctx, func(event *model.AuditEvent) error {
+		if event.Seq != expectedSeq {
+			report.Breaks = append(report.Breaks, AuditChainBreak{
+				Seq:    event.Seq,
+				Reason: fmt.Sprintf("序号不连续, 期望 %d", expectedSeq),
+			})
+		}
+		if event.PrevHash != prevHash {
+			report.Breaks = append(report.Breaks, AuditChainBreak{
+				Seq:    event.Seq,
+				Reason: "与前一事件的哈希不匹配",
+			})
+		}
+		if hashAuditEvent(event) != event.Hash {
+			report.Breaks = append(report.Breaks, AuditChainBreak{
+				Seq:    event.Seq,
+				Reason: "事件内容与哈希不一致",
+			})
+		}
+
+		report.Checked++
+		report.LastSeq, report.LastHash = event.Seq, event.Hash
+		expectedSeq, prevHash = event.Seq+1, event.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ListAuditEvents 按条件分页查询审计事件, 最新的在前
+func (s *AuditService) ListAuditEvents(ctx context.Context, req *admin.ListAuditEventsReq) (*admin.ListAuditEventsResp, error) {
+	var err error
+	var total int64
+	var events []*model.AuditEvent
+
+	// 解析条件
+	query := &repository.AuditEventQuery{
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		From:       req.From,
+		To:         req.To,
+	}
+	if req.ActorID != "" {
+		if query.ActorID, err = bson.ObjectIDFromHex(req.ActorID); err != nil {
+			return nil, errorx.ErrAuditActorIDInvalid
+		}
+	}
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		return nil, errorx.ErrAuditTimeRangeInvalid
+	}
+
+	// 查询
+	skip, limit := pageBounds(req.PageParam)
+	if events, err = s.AuditEventRepository.FindEvents(ctx, query, skip, limit); err != nil {
+		return nil, err
+	}
+	if total, err = s.AuditEventRepository.CountEvents(ctx, query); err != nil {
+		return nil, err
+	}
+
+	vos := make([]*admin.AuditEventVO, 0, len(events))
+	for _, event := range events {
+		vos = append(vos, toAuditEventVO(event))
+	}
+	return &admin.ListAuditEventsResp{
+		Resp:   dto.Success(),
+		Total:  total,
+		Events: vos,
+	}, nil
+}
+
+func toAuditEventVO(event *model.AuditEvent) *admin.AuditEventVO {
+	changes := make([]*admin.AuditChangeVO, 0, len(event.Changes))
+	for _, change := range event.Changes {
+		changes = append(changes, &admin.AuditChangeVO{
+			Field:  change.Field,
+			Before: change.Before,
+			After:  change.After,
+		})
+	}
+	return &admin.AuditEventVO{
+		ID:         event.ID,
+		Seq:        event.Seq,
+		PrevHash:   event.PrevHash,
+		Hash:       event.Hash,
+		ActorID:    event.ActorID,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Changes:    changes,
+		IP:         event.IP,
+		UserAgent:  event.UserAgent,
+		RequestID:  event.RequestID,
+		CreatedAt:  event.CreatedAt,
+	}
+}
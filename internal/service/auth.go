@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/jwt"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	gjwt "github.com/golang-jwt/jwt/v4"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type IAuthService interface {
+	Authenticate(ctx context.Context, authorization string) (*auth.Principal, error)
+}
+
+type AuthService struct {
+	UserRepository *repository.UserRepository
+	TokenManager   *jwt.Manager
+	SessionService *SessionService
+	APIKeyService  *APIKeyService
+}
+
+var AuthServiceSet = wire.NewSet(
+	wire.Struct(new(AuthService), "*"),
+	wire.Bind(new(IAuthService), new(*AuthService)),
+)
+
+// Authenticate 校验 Authorization 请求头并构造认证主体, 支持 Bearer 访问令牌与 ApiKey 两种方式
+func (s *AuthService) Authenticate(ctx context.Context, authorization string) (*auth.Principal, error) {
+	var err error
+	var claims *jwt.Claims
+	var userModel *model.User
+
+	// 使用 API Key 认证
+	if key, ok := strings.CutPrefix(authorization, "ApiKey "); ok {
+		return s.authenticateAPIKey(ctx, key)
+	}
+
+	// 提取 Bearer 令牌
+	tokenStr, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || tokenStr == "" {
+		return nil, errorx.ErrTokenMissing
+	}
+
+	// 校验令牌
+	if claims, err = s.TokenManager.ParseToken(ctx, tokenStr); err != nil {
+		var validationErr *gjwt.ValidationError
+		switch {
+		case errors.Is(err, gjwt.ErrTokenExpired):
+			return nil, errorx.ErrTokenExpired
+		case errors.As(err, &validationErr), errors.Is(err, gjwt.ErrTokenMalformed), errors.Is(err, jwt.ErrTokenRevoked):
+			return nil, errorx.ErrTokenInvalid
+		default:
+			return nil, err
+		}
+	}
+
+	userId, err := bson.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		return nil, errorx.ErrTokenInvalid
+	}
+
+	// 获取最新的用户角色与状态
+	if userModel, err = s.findActiveUser(ctx, userId, errorx.ErrTokenInvalid); err != nil {
+		return nil, err
+	}
+
+	// 更新会话的最后活跃时间
+	s.SessionService.touch(ctx, claims.FamilyID)
+
+	principal := toPrincipal(userModel)
+	principal.TokenID = claims.ID
+	principal.FamilyID = claims.FamilyID
+	principal.ExpiresAt = claims.ExpiresAt.Time
+	return principal, nil
+}
+
+// authenticateAPIKey 校验 API Key 并构造认证主体, 主体的权限受密钥的 scope 限制
+func (s *AuthService) authenticateAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	var err error
+	var apiKey *model.APIKey
+	var userModel *model.User
+
+	// 校验密钥
+	if key == "" {
+		return nil, errorx.ErrTokenMissing
+	}
+	if apiKey, err = s.APIKeyService.verify(ctx, key); err != nil {
+		return nil, err
+	}
+
+	// 获取最新的用户角色与状态
+	if userModel, err = s.findActiveUser(ctx, apiKey.UserID, errorx.ErrAPIKeyInvalid); err != nil {
+		return nil, err
+	}
+
+	principal := toPrincipal(userModel)
+	principal.APIKeyID = apiKey.ID
+	principal.Scopes = apiKey.Scopes
+	return principal, nil
+}
+
+// findActiveUser 获取用户并校验账号状态, 用户已不存在时返回 notFound
+func (s *AuthService) findActiveUser(ctx context.Context, userId bson.ObjectID, notFound error) (*model.User, error) {
+	userModel, err := s.UserRepository.FindUserByUserID(ctx, userId)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			log.CtxInfo(ctx, "credential subject %s no longer exists", userId.Hex())
+			return nil, notFound
+		}
+		return nil, err
+	}
+
+	if err = checkUserStatus(ctx, s.UserRepository, userModel); err != nil {
+		return nil, err
+	}
+	return userModel, nil
+}
+
+func toPrincipal(userModel *model.User) *auth.Principal {
+	return &auth.Principal{
+		UserID:        userModel.ID,
+		Role:          userModel.Role,
+		Status:        userModel.Status,
+		EmailVerified: !userModel.EmailUnverified,
+		TwoFactor:     userModel.TOTPEnabled,
+	}
+}
+
+// checkUserStatus 校验账号状态, 暂停已到期的账号自动恢复为活跃
+func checkUserStatus(ctx context.Context, userRepository *repository.UserRepository, u *model.User) error {
+	switch u.Status {
+	case enum.StatusBanned:
+		log.CtxInfo(ctx, "banned user %s rejected", u.ID.Hex())
+		return errorx.New(errorx.ErrUserBanned.Code, fmt.Sprintf("%s, 原因: %s", errorx.ErrUserBanned.Msg, u.StatusReason))
+	case enum.StatusSuspended:
+		now := time.Now()
+		if u.SuspendedUntil.IsZero() || now.Before(u.SuspendedUntil) {
+			log.CtxInfo(ctx, "suspended user %s rejected", u.ID.Hex())
+			return errorx.New(errorx.ErrUserSuspended.Code, fmt.Sprintf("%s至 %s, 原因: %s",
+				errorx.ErrUserSuspended.Msg, u.SuspendedUntil.Format(time.DateTime), u.StatusReason))
+		}
+
+		// 暂停已到期, 自动恢复
+		reactivated, err := userRepository.ReactivateExpiredSuspension(ctx, u.ID, now)
+		if err != nil {
+			log.CtxError(ctx, "failed to reactivate user: %v", err)
+			return err
+		}
+		if !reactivated {
+			// 状态已被并发修改, 以数据库中的最新状态为准
+			latest, err := userRepository.FindUserByUserID(ctx, u.ID)
+			if err != nil {
+				return err
+			}
+			if latest.Status != enum.StatusActive {
+				return checkUserStatus(ctx, userRepository, latest)
+			}
+		}
+		log.CtxInfo(ctx, "suspension of user %s expired, reactivated", u.ID.Hex())
+		u.Status, u.StatusReason, u.SuspendedUntil = enum.StatusActive, "", time.Time{}
+	}
+	return nil
+}
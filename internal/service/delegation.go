@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/security"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// maxDelegationDepth 委托链的最大长度, 超过时拒绝新的委托, 计算投票权时也只展开到这一层
+const maxDelegationDepth = 32
+
+const (
+	// delegationLockKey 修改委托关系时持有的全局锁, 循环与链长检查依赖整张委托图, 无法按用户加锁
+	delegationLockKey = "delegation_lock"
+	// delegationLockTTL 锁的过期时间, 持有者异常退出时锁自动释放
+	delegationLockTTL = 10 * time.Second
+	// delegationLockRetries 获取锁失败时的重试次数与间隔
+	delegationLockRetries  = 20
+	delegationLockInterval = 50 * time.Millisecond
+)
+
+// delegationGraph 检查委托链时需要的查询
+type delegationGraph interface {
+	FindByDelegatorID(ctx context.Context, delegatorId bson.ObjectID) (*model.Delegation, error)
+	FindByDelegateIDs(ctx context.Context, delegateIds []bson.ObjectID) ([]*model.Delegation, error)
+}
+
+type IDelegationService interface {
+	Delegate(ctx context.Context, req *user.DelegateReq) (*user.DelegateResp, error)
+	RevokeDelegation(ctx context.Context) (*user.RevokeDelegationResp, error)
+	ListMyDelegations(ctx context.Context) (*user.ListMyDelegationsResp, error)
+	ListDelegators(ctx context.Context, req *user.ListDelegatorsReq) (*user.ListDelegatorsResp, error)
+}
+
+type DelegationService struct {
+	UserRepository       *repository.UserRepository
+	DelegationRepository *repository.DelegationRepository
+	Store                kv.Store
+}
+
+var DelegationServiceSet = wire.NewSet(
+	wire.Struct(new(DelegationService), "*"),
+	wire.Bind(new(IDelegationService), new(*DelegationService)),
+)
+
+// Delegate 把投票权委托给另一个用户, 已有委托时改为委托给新的受托人
+func (s *DelegationService) Delegate(ctx context.Context, req *user.DelegateReq) (*user.DelegateResp, error) {
+	var err error
+	var delegate *model.User
+
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验受托人
+	delegateId, err := bson.ObjectIDFromHex(req.DelegateID)
+	if err != nil {
+		return nil, errorx.ErrUserIDFormatInvalid
+	}
+	if delegateId == userId {
+		return nil, errorx.ErrDelegationSelf
+	}
+	if delegate, err = s.UserRepository.FindUserByUserID(ctx, delegateId); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrUserNotFound
+		}
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+	if delegate.Status != enum.StatusActive {
+		return nil, errorx.ErrDelegateInactive
+	}
+
+	// 检查与保存需要串行执行, 否则并发的委托可能各自通过检查后共同形成循环或超长的链
+	lockToken, err := s.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unlock(ctx, lockToken)
+
+	// 检查新委托是否会形成循环, 以及加入后的委托链是否过长
+	if err = checkDelegation(ctx, s.DelegationRepository, userId, delegateId); err != nil {
+		return nil, err
+	}
+
+	// 保存委托
+	delegation := &model.Delegation{
+		ID:          bson.NewObjectID(),
+		DelegatorID: userId,
+		DelegateID:  delegateId,
+		CreatedAt:   time.Now(),
+	}
+	if err = s.DelegationRepository.Upsert(ctx, delegation); err != nil {
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "user %s delegated to %s", userId.Hex(), delegateId.Hex())
+	return &user.DelegateResp{
+		Resp:         dto.Success(),
+		DelegationVO: toDelegationVO(delegation, delegate),
+	}, nil
+}
+
+func (s *DelegationService) RevokeDelegation(ctx context.Context) (*user.RevokeDelegationResp, error) {
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 撤销委托
+	deleted, err := s.DelegationRepository.DeleteByDelegatorID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, errorx.ErrDelegationNotFound
+	}
+
+	log.CtxInfo(ctx, "user %s revoked delegation", userId.Hex())
+	return &user.RevokeDelegationResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// ListMyDelegations 返回当前用户的委托、有效投票权与直接委托人数
+func (s *DelegationService) ListMyDelegations(ctx context.Context) (*user.ListMyDelegationsResp, error) {
+	var err error
+	var delegation *model.Delegation
+	var delegators int64
+
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &user.ListMyDelegationsResp{Resp: dto.Success()}
+
+	// 获取自己的委托
+	if delegation, err = s.DelegationRepository.FindByDelegatorID(ctx, userId); err == nil {
+		delegate, err := s.UserRepository.FindUserByUserID(ctx, delegation.DelegateID)
+		if err != nil && !errors.Is(err, monc.ErrNotFound) {
+			log.CtxError(ctx, "failed to find user: %v", err)
+			return nil, err
+		}
+		resp.Delegation = toDelegationVO(delegation, delegate)
+	} else if !errors.Is(err, monc.ErrNotFound) {
+		log.CtxError(ctx, "failed to find delegation: %v", err)
+		return nil, err
+	}
+
+	// 计算投票权
+	if resp.EffectiveWeight, err = s.EffectiveWeight(ctx, userId); err != nil {
+		return nil, err
+	}
+	if delegators, err = s.DelegationRepository.CountByDelegateID(ctx, userId); err != nil {
+		return nil, err
+	}
+	resp.Delegators = delegators
+
+	return resp, nil
+}
+
+// ListDelegators 分页查看直接委托给自己的用户
+func (s *DelegationService) ListDelegators(ctx context.Context, req *user.ListDelegatorsReq) (*user.ListDelegatorsResp, error) {
+	var err error
+	var total int64
+	var delegations []*model.Delegation
+
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 计算分页
+	skip, limit := pageBounds(req.PageParam)
+
+	// 查询
+	if delegations, err = s.DelegationRepository.FindByDelegateID(ctx, userId, skip, limit); err != nil {
+		return nil, err
+	}
+	if total, err = s.DelegationRepository.CountByDelegateID(ctx, userId); err != nil {
+		return nil, err
+	}
+	users, err := s.findUsers(ctx, delegatorIDs(delegations))
+	if err != nil {
+		return nil, err
+	}
+
+	vos := make([]*user.DelegatorVO, 0, len(delegations))
+	for _, d := range delegations {
+		vo := &user.DelegatorVO{UserID: d.DelegatorID, CreatedAt: d.CreatedAt}
+		if u, ok := users[d.DelegatorID]; ok {
+			vo.Username = u.Username
+			vo.Active = u.Status == enum.StatusActive
+		}
+		vos = append(vos, vo)
+	}
+	return &user.ListDelegatorsResp{
+		Resp:       dto.Success(),
+		Total:      total,
+		Delegators: vos,
+	}, nil
+}
+
+// EffectiveWeight 计算用户的有效投票权
+// 每个状态正常的用户有 1 票; 委托给状态正常的用户时自己的票数为 0, 由受托人沿委托链逐级累加;
+// 受托人被暂停或封禁时委托不生效, 委托人保留自己的票, 也不会经过该受托人继续传递
+func (s *DelegationService) EffectiveWeight(ctx context.Context, userId bson.ObjectID) (int64, error) {
+	u, err := s.UserRepository.FindUserByUserID(ctx, userId)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return 0, errorx.ErrUserNotFound
+		}
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return 0, err
+	}
+	if u.Status != enum.StatusActive {
+		return 0, nil
+	}
+
+	// 已把投票权委托出去
+	delegation, err := s.DelegationRepository.FindByDelegatorID(ctx, userId)
+	if err == nil {
+		delegate, err := s.UserRepository.FindUserByUserID(ctx, delegation.DelegateID)
+		if err == nil && delegate.Status == enum.StatusActive {
+			return 0, nil
+		}
+		if err != nil && !errors.Is(err, monc.ErrNotFound) {
+			log.CtxError(ctx, "failed to find user: %v", err)
+			return 0, err
+		}
+	} else if !errors.Is(err, monc.ErrNotFound) {
+		log.CtxError(ctx, "failed to find delegation: %v", err)
+		return 0, err
+	}
+
+	// 逐层展开委托给自己的用户, 只经过状态正常的委托人
+	weight := int64(1)
+	visited := map[bson.ObjectID]bool{userId: true}
+	level := []bson.ObjectID{userId}
+	for depth := 0; depth < maxDelegationDepth && len(level) > 0; depth++ {
+		delegations, err := s.DelegationRepository.FindByDelegateIDs(ctx, level)
+		if err != nil {
+			return 0, err
+		}
+		ids := make([]bson.ObjectID, 0, len(delegations))
+		for _, d := range delegations {
+			if !visited[d.DelegatorID] {
+				visited[d.DelegatorID] = true
+				ids = append(ids, d.DelegatorID)
+			}
+		}
+		if len(ids) == 0 {
+			break
+		}
+		users, err := s.findUsers(ctx, ids)
+		if err != nil {
+			return 0, err
+		}
+
+		level = level[:0]
+		for _, id := range ids {
+			if u, ok := users[id]; ok && u.Status == enum.StatusActive {
+				weight++
+				level = append(level, id)
+			}
+		}
+	}
+
+	return weight, nil
+}
+
+// lock 获取修改委托关系的全局锁, 在限定次数内重试, 返回释放锁时需要的持有者令牌
+func (s *DelegationService) lock(ctx context.Context) (string, error) {
+	token, err := security.GenerateRandomToken(16)
+	if err != nil {
+		log.CtxError(ctx, "failed to generate lock token: %v", err)
+		return "", err
+	}
+	for i := 0; i < delegationLockRetries; i++ {
+		ok, err := s.Store.SetNX(ctx, delegationLockKey, token, delegationLockTTL)
+		if err != nil {
+			log.CtxError(ctx, "failed to acquire delegation lock: %v", err)
+			return "", err
+		}
+		if ok {
+			return token, nil
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delegationLockInterval):
+		}
+	}
+	log.CtxInfo(ctx, "delegation lock is busy")
+	return "", errorx.ErrDelegationBusy
+}
+
+// unlock 释放锁, 持有时间超过 delegationLockTTL 后锁可能已被其他请求获取, 此时不会删除
+func (s *DelegationService) unlock(ctx context.Context, token string) {
+	released, err := s.Store.CompareAndDel(ctx, delegationLockKey, token)
+	if err != nil {
+		log.CtxError(ctx, "failed to release delegation lock: %v", err)
+		return
+	}
+	if !released {
+		log.CtxInfo(ctx, "delegation lock expired before release")
+	}
+}
+
+// checkDelegation 检查委托人 delegatorId 委托给 delegateId 后的委托图
+// 受托人向上的链中出现委托人时会形成循环; 委托给委托人的最长链、新委托与受托人向上的链相加即为新的链长
+func checkDelegation(ctx context.Context, graph delegationGraph, delegatorId, delegateId bson.ObjectID) error {
+	// 沿受托人的委托链向上查找, 回到自己说明会形成循环
+	upstream := 0
+	current := delegateId
+	for {
+		next, err := graph.FindByDelegatorID(ctx, current)
+		if err != nil {
+			if errors.Is(err, monc.ErrNotFound) {
+				break
+			}
+			log.CtxError(ctx, "failed to find delegation: %v", err)
+			return err
+		}
+		if next.DelegateID == delegatorId {
+			log.CtxInfo(ctx, "delegation from %s to %s would form a cycle", delegatorId.Hex(), delegateId.Hex())
+			return errorx.ErrDelegationCycle
+		}
+		if upstream++; upstream >= maxDelegationDepth {
+			return errorx.ErrDelegationChainTooLong
+		}
+		current = next.DelegateID
+	}
+
+	// 逐层展开委托给委托人的用户, 得到向下最长的链
+	downstream := 0
+	visited := map[bson.ObjectID]bool{delegatorId: true}
+	level := []bson.ObjectID{delegatorId}
+	for upstream+downstream+1 <= maxDelegationDepth {
+		delegations, err := graph.FindByDelegateIDs(ctx, level)
+		if err != nil {
+			return err
+		}
+		level = level[:0]
+		for _, d := range delegations {
+			if !visited[d.DelegatorID] {
+				visited[d.DelegatorID] = true
+				level = append(level, d.DelegatorID)
+			}
+		}
+		if len(level) == 0 {
+			return nil
+		}
+		downstream++
+	}
+
+	log.CtxInfo(ctx, "delegation from %s to %s exceeds max depth", delegatorId.Hex(), delegateId.Hex())
+	return errorx.ErrDelegationChainTooLong
+}
+
+func (s *DelegationService) findUsers(ctx context.Context, ids []bson.ObjectID) (map[bson.ObjectID]*model.User, error) {
+	users := make(map[bson.ObjectID]*model.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+	found, err := s.UserRepository.FindUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range found {
+		users[u.ID] = u
+	}
+	return users, nil
+}
+
+func delegatorIDs(delegations []*model.Delegation) []bson.ObjectID {
+	ids := make([]bson.ObjectID, 0, len(delegations))
+	for _, d := range delegations {
+		ids = append(ids, d.DelegatorID)
+	}
+	return ids
+}
+
+func toDelegationVO(d *model.Delegation, delegate *model.User) *user.DelegationVO {
+	vo := &user.DelegationVO{
+		DelegateID: d.DelegateID,
+		CreatedAt:  d.CreatedAt,
+	}
+	if delegate != nil {
+		vo.Username = delegate.Username
+		vo.Active = delegate.Status == enum.StatusActive
+	}
+	return vo
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeDelegationGraph 以委托人到受托人的映射表示委托图
+type fakeDelegationGraph map[bson.ObjectID]bson.ObjectID
+
+func (g fakeDelegationGraph) FindByDelegatorID(_ context.Context, delegatorId bson.ObjectID) (*model.Delegation, error) {
+	delegateId, ok := g[delegatorId]
+	if !ok {
+		return nil, monc.ErrNotFound
+	}
+	return &model.Delegation{DelegatorID: delegatorId, DelegateID: delegateId}, nil
+}
+
+func (g fakeDelegationGraph) FindByDelegateIDs(_ context.Context, delegateIds []bson.ObjectID) ([]*model.Delegation, error) {
+	var delegations []*model.Delegation
+	for delegatorId, delegateId := range g {
+		if slices.Contains(delegateIds, delegateId) {
+			delegations = append(delegations, &model.Delegation{DelegatorID: delegatorId, DelegateID: delegateId})
+		}
+	}
+	return delegations, nil
+}
+
+// chain 生成 n 个用户依次委托给下一个用户的链, 返回链上的用户
+func (g fakeDelegationGraph) chain(n int) []bson.ObjectID {
+	ids := make([]bson.ObjectID, n)
+	for i := range ids {
+		ids[i] = bson.NewObjectID()
+		if i > 0 {
+			g[ids[i-1]] = ids[i]
+		}
+	}
+	return ids
+}
+
+func TestCheckDelegation(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// setup 构造委托图, 返回新委托的委托人与受托人
+		setup func(g fakeDelegationGraph) (delegator, delegate bson.ObjectID)
+		want  error
+	}{
+		{"no existing delegations", func(g fakeDelegationGraph) (bson.ObjectID, bson.ObjectID) {
+			return bson.NewObjectID(), bson.NewObjectID()
+		}, nil},
+		{"direct cycle", func(g fakeDelegationGraph) (bson.ObjectID, bson.ObjectID) {
+			ids := g.chain(2)
+			return ids[1], ids[0]
+		}, errorx.ErrDelegationCycle},
+		{"indirect cycle", func(g fakeDelegationGraph) (bson.ObjectID, bson.ObjectID) {
+			ids := g.chain(5)
+			return ids[4], ids[0]
+		}, errorx.ErrDelegationCycle},
+		{"redelegate within own chain", func(g fakeDelegationGraph) (bson.ObjectID, bson.ObjectID) {
+			ids := g.chain(4)
+			return ids[0], ids[3]
+		}, nil},
+		{"upstream at limit", func(g fakeDelegationGraph) (bson.ObjectID, bson.ObjectID) {
+			ids := g.chain(maxDelegationDepth)
+			return bson.NewObjectID(), ids[0]
+		}, nil},
+		{"upstream over limit", func(g fakeDelegationGraph) (bson.ObjectID, bson.ObjectID) {
+			ids := g.chain(maxDelegationDepth + 1)
+			return bson.NewObjectID(), ids[0]
+		}, errorx.ErrDelegationChainTooLong},
+		{"downstream at limit", func(g fakeDelegationGraph) (bson.ObjectID, bson.ObjectID) {
+			ids := g.chain(maxDelegationDepth)
+			return ids[len(ids)-1], bson.NewObjectID()
+		}, nil},
+		{"downstream over limit", func(g fakeDelegationGraph) (bson.ObjectID, bson.ObjectID) {
+			ids := g.chain(maxDelegationDepth + 1)
+			return ids[len(ids)-1], bson.NewObjectID()
+		}, errorx.ErrDelegationChainTooLong},
+		{"joined chains at limit", func(g fakeDelegationGraph) (bson.ObjectID, bson.ObjectID) {
+			below := g.chain(maxDelegationDepth/2 + 1)
+			above := g.chain(maxDelegationDepth - maxDelegationDepth/2)
+			return below[len(below)-1], above[0]
+		}, nil},
+		{"joined chains over limit", func(g fakeDelegationGraph) (bson.ObjectID, bson.ObjectID) {
+			below := g.chain(maxDelegationDepth/2 + 2)
+			above := g.chain(maxDelegationDepth - maxDelegationDepth/2)
+			return below[len(below)-1], above[0]
+		}, errorx.ErrDelegationChainTooLong},
+		{"longest downstream branch counts", func(g fakeDelegationGraph) (bson.ObjectID, bson.ObjectID) {
+			long := g.chain(maxDelegationDepth + 1)
+			short := g.chain(2)
+			g[short[1]] = long[len(long)-1]
+			return long[len(long)-1], bson.NewObjectID()
+		}, errorx.ErrDelegationChainTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := fakeDelegationGraph{}
+			delegator, delegate := tt.setup(g)
+			if err := checkDelegation(ctx, g, delegator, delegate); !errors.Is(err, tt.want) {
+				t.Errorf("checkDelegation() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	inviteCodeLen          = 10
+	inviteCodeAlphabet     = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // 去掉易混淆的 I、O、0、1
+	defaultInviteTreeDepth = 3
+	maxInviteTreeDepth     = 5
+	maxInviteTreeNodes     = 1000
+)
+
+type IInviteService interface {
+	CreateInviteCode(ctx context.Context, req *user.CreateInviteCodeReq) (*user.CreateInviteCodeResp, error)
+	ListMyInviteCodes(ctx context.Context) (*user.ListInviteCodesResp, error)
+	RevokeInviteCode(ctx context.Context, req *user.RevokeInviteCodeReq) (*user.RevokeInviteCodeResp, error)
+	GetMyInviteTree(ctx context.Context, req *user.GetInviteTreeReq) (*user.GetInviteTreeResp, error)
+	GetUserInviteTree(ctx context.Context, req *user.GetInviteTreeReq) (*user.GetInviteTreeResp, error)
+}
+
+type InviteService struct {
+	Config               *config.Config
+	InviteCodeRepository *repository.InviteCodeRepository
+	UserRepository       *repository.UserRepository
+	Authorizer           *auth.Authorizer
+}
+
+var InviteServiceSet = wire.NewSet(
+	wire.Struct(new(InviteService), "*"),
+	wire.Bind(new(IInviteService), new(*InviteService)),
+)
+
+// CreateInviteCode 生成邀请码, 成员生成的邀请码受次数、有效期与数量限制
+func (s *InviteService) CreateInviteCode(ctx context.Context, req *user.CreateInviteCodeReq) (*user.CreateInviteCodeResp, error) {
+	var err error
+	var active int64
+	var role enum.UserRole
+
+	// 获取当前用户
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manager := s.Authorizer.Allows(principal, auth.PermInviteManage)
+
+	// 校验参数
+	if req.MaxUses < 0 || req.ExpiresIn < 0 {
+		return nil, errorx.ErrInviteParamInvalid
+	}
+	if req.Role != "" {
+		// 预设角色等同于授予角色, 要求与修改角色相同的权限与两步验证, 且不能高于自己的角色
+		if !manager || !s.Authorizer.Allows(principal, auth.PermUserRoleUpdate) {
+			return nil, errorx.ErrUserPermissionsInsufficient
+		}
+		if !principal.TwoFactor && s.Authorizer.RequiresTwoFactor(principal.Role) {
+			return nil, errorx.ErrTwoFactorRequired
+		}
+		if role = enum.GetUserRoleCode(req.Role); !s.Authorizer.IsRoleDefined(role) {
+			return nil, errorx.ErrUserRoleInvalid
+		}
+		if !s.Authorizer.Covers(principal.Role, role) {
+			log.CtxInfo(ctx, "user %s cannot preset role %s above own role", principal.UserID.Hex(), req.Role)
+			return nil, errorx.ErrUserPermissionsInsufficient
+		}
+	}
+	maxUses, expiresIn := req.MaxUses, req.ExpiresIn
+	if !manager {
+		limits := s.Config.Invite
+		if maxUses > limits.MemberMaxUses || expiresIn > limits.MemberExpire {
+			return nil, errorx.ErrInviteParamInvalid
+		}
+		if maxUses == 0 {
+			maxUses = limits.MemberMaxUses
+		}
+		if expiresIn == 0 {
+			expiresIn = limits.MemberExpire
+		}
+
+		// 成员同时持有的可用邀请码数量有限
+		if active, err = s.InviteCodeRepository.CountActiveByCreatorID(ctx, principal.UserID, time.Now()); err != nil {
+			return nil, err
+		}
+		if active >= limits.MemberMaxActive {
+			return nil, errorx.ErrInviteCodeLimitExceeded
+		}
+	}
+
+	// 生成邀请码, 重复时重试
+	now := time.Now()
+	invite := &model.InviteCode{
+		ID:        bson.NewObjectID(),
+		CreatorID: principal.UserID,
+		Role:      role,
+		MaxUses:   maxUses,
+		CreatedAt: now,
+	}
+	if expiresIn > 0 {
+		invite.ExpiresAt = now.Add(time.Duration(expiresIn) * time.Second)
+	}
+	for attempt := 0; ; attempt++ {
+		if invite.Code, err = generateInviteCode(); err != nil {
+			log.CtxError(ctx, "failed to generate invite code: %v", err)
+			return nil, err
+		}
+		err = s.InviteCodeRepository.Insert(ctx, invite)
+		if !errors.Is(err, repository.ErrInviteCodeExisted) || attempt >= 3 {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "invite code created by user %s, role=%d, maxUses=%d", principal.UserID.Hex(), role, maxUses)
+	return &user.CreateInviteCodeResp{
+		Resp:         dto.Success(),
+		InviteCodeVO: toInviteCodeVO(invite, now),
+	}, nil
+}
+
+func (s *InviteService) ListMyInviteCodes(ctx context.Context) (*user.ListInviteCodesResp, error) {
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取邀请码
+	invites, err := s.InviteCodeRepository.FindByCreatorID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	vos := make([]*user.InviteCodeVO, 0, len(invites))
+	for _, invite := range invites {
+		vos = append(vos, toInviteCodeVO(invite, now))
+	}
+	return &user.ListInviteCodesResp{
+		Resp:        dto.Success(),
+		InviteCodes: vos,
+	}, nil
+}
+
+// RevokeInviteCode 吊销邀请码, 创建者或拥有 invite.manage 权限的用户可以吊销
+func (s *InviteService) RevokeInviteCode(ctx context.Context, req *user.RevokeInviteCodeReq) (*user.RevokeInviteCodeResp, error) {
+	var err error
+	var invite *model.InviteCode
+
+	// 获取当前用户
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取邀请码并校验权限
+	if invite, err = s.InviteCodeRepository.FindByCode(ctx, normalizeInviteCode(req.Code)); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrInviteCodeNotFound
+		}
+		log.CtxError(ctx, "failed to find invite code: %v", err)
+		return nil, err
+	}
+	if invite.CreatorID != principal.UserID && !s.Authorizer.Allows(principal, auth.PermInviteManage) {
+		return nil, errorx.ErrInviteCodeNotFound
+	}
+
+	// 吊销
+	if err = s.InviteCodeRepository.Revoke(ctx, invite.ID); err != nil {
+		return nil, err
+	}
+
+	return &user.RevokeInviteCodeResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+func (s *InviteService) GetMyInviteTree(ctx context.Context, req *user.GetInviteTreeReq) (*user.GetInviteTreeResp, error) {
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.inviteTree(ctx, userId, req.Depth)
+}
+
+// GetUserInviteTree 管理员查看指定用户的邀请树
+func (s *InviteService) GetUserInviteTree(ctx context.Context, req *user.GetInviteTreeReq) (*user.GetInviteTreeResp, error) {
+	// 从路径参数获取用户ID
+	targetId, ok := ctx.Value(consts.ContextTargetID).(bson.ObjectID)
+	if !ok {
+		return nil, errorx.ErrContextUserIDInvalid
+	}
+
+	// 校验用户是否存在
+	if _, err := s.UserRepository.FindUserByUserID(ctx, targetId); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrUserNotFound
+		}
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+
+	return s.inviteTree(ctx, targetId, req.Depth)
+}
+
+// Redeem 校验并占用一次邀请码, 注册失败时需调用 Release 归还
+func (s *InviteService) Redeem(ctx context.Context, code string) (*model.InviteCode, error) {
+	invite, err := s.InviteCodeRepository.Redeem(ctx, normalizeInviteCode(code), time.Now())
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			log.CtxInfo(ctx, "invite code rejected")
+			return nil, errorx.ErrInviteCodeInvalid
+		}
+		log.CtxError(ctx, "failed to redeem invite code: %v", err)
+		return nil, err
+	}
+	return invite, nil
+}
+
+// Release 归还 Redeem 占用的次数
+func (s *InviteService) Release(ctx context.Context, invite *model.InviteCode) {
+	if err := s.InviteCodeRepository.Release(ctx, invite.ID); err != nil {
+		log.CtxError(ctx, "failed to release invite code: %v", err)
+	}
+}
+
+// RedeemForRegistration 注册时处理邀请码: 配置要求邀请码时必须提供, 未提供且不要求时返回 nil
+func (s *InviteService) RedeemForRegistration(ctx context.Context, code string) (*model.InviteCode, error) {
+	if strings.TrimSpace(code) == "" {
+		if s.Config.Invite.Required {
+			return nil, errorx.ErrInviteCodeRequired
+		}
+		return nil, nil
+	}
+	return s.Redeem(ctx, code)
+}
+
+// ApplyInvite 将邀请码的邀请人与预设角色写入新用户
+func ApplyInvite(u *model.User, invite *model.InviteCode) {
+	if invite == nil {
+		return
+	}
+	u.ReferredBy = invite.CreatorID
+	u.InviteCodeID = invite.ID
+	if invite.Role != 0 {
+		u.Role = invite.Role
+	}
+}
+
+// inviteTree 逐层展开用户的邀请树并统计
+func (s *InviteService) inviteTree(ctx context.Context, userId bson.ObjectID, depth int) (*user.GetInviteTreeResp, error) {
+	if depth <= 0 {
+		depth = defaultInviteTreeDepth
+	} else if depth > maxInviteTreeDepth {
+		depth = maxInviteTreeDepth
+	}
+
+	// 统计邀请码
+	invites, err := s.InviteCodeRepository.FindByCreatorID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	stats := &user.InviteStatsVO{CodesCreated: int64(len(invites))}
+	for _, invite := range invites {
+		stats.Redemptions += invite.UsedCount
+		if inviteUsable(invite, now) {
+			stats.ActiveCodes++
+		}
+	}
+
+	// 逐层查询被邀请人
+	root := &user.InviteNodeVO{UserID: userId}
+	nodes := map[bson.ObjectID]*user.InviteNodeVO{userId: root}
+	level := []bson.ObjectID{userId}
+	total, truncated := 0, false
+	for d := 0; d < depth && len(level) > 0 && !truncated; d++ {
+		remaining := maxInviteTreeNodes - total
+		users, err := s.UserRepository.FindUsersByReferrers(ctx, level, int64(remaining)+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(users) > remaining {
+			users, truncated = users[:remaining], true
+		}
+
+		next := make([]bson.ObjectID, 0, len(users))
+		for _, u := range users {
+			parent, ok := nodes[u.ReferredBy]
+			if !ok {
+				continue
+			}
+			node := &user.InviteNodeVO{UserID: u.ID, Username: u.Username, JoinedAt: u.CreatedAt}
+			parent.Invitees = append(parent.Invitees, node)
+			nodes[u.ID] = node
+			next = append(next, u.ID)
+			if d == 0 {
+				stats.DirectInvitees++
+			} else {
+				stats.IndirectInvitees++
+			}
+		}
+		total += len(users)
+		level = next
+	}
+
+	return &user.GetInviteTreeResp{
+		Resp:      dto.Success(),
+		Stats:     stats,
+		Invitees:  root.Invitees,
+		Truncated: truncated,
+	}, nil
+}
+
+func generateInviteCode() (string, error) {
+	b := make([]byte, inviteCodeLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i, c := range b {
+		b[i] = inviteCodeAlphabet[int(c)%len(inviteCodeAlphabet)]
+	}
+	return string(b), nil
+}
+
+func normalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func inviteUsable(invite *model.InviteCode, now time.Time) bool {
+	return !invite.Revoked &&
+		(invite.ExpiresAt.IsZero() || now.Before(invite.ExpiresAt)) &&
+		(invite.MaxUses == 0 || invite.UsedCount < invite.MaxUses)
+}
+
+func toInviteCodeVO(invite *model.InviteCode, now time.Time) *user.InviteCodeVO {
+	vo := &user.InviteCodeVO{
+		Code:      invite.Code,
+		MaxUses:   invite.MaxUses,
+		UsedCount: invite.UsedCount,
+		Revoked:   invite.Revoked,
+		Usable:    inviteUsable(invite, now),
+		CreatedAt: invite.CreatedAt,
+	}
+	if invite.Role != 0 {
+		vo.Role = enum.GetUserRoleDesc(invite.Role)
+	}
+	if !invite.ExpiresAt.IsZero() {
+		vo.ExpiresAt = &invite.ExpiresAt
+	}
+	return vo
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/admin"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/security"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	loginFailEmailKeyPrefix = "login_fail:email:"
+	loginFailIPKeyPrefix    = "login_fail:ip:"
+	loginLockEmailKeyPrefix = "login_lock:email:"
+	loginLockIPKeyPrefix    = "login_lock:ip:"
+	mfaFailUserKeyPrefix    = "mfa_fail:user:"
+	mfaLockUserKeyPrefix    = "mfa_lock:user:"
+)
+
+type ILockoutService interface {
+	UnlockUser(ctx context.Context) (*admin.UnlockUserResp, error)
+	UnlockIP(ctx context.Context, req *admin.UnlockIPReq) (*admin.UnlockIPResp, error)
+}
+
+// LockoutService 登录失败计数与临时锁定
+type LockoutService struct {
+	Config         *config.Config
+	Store          kv.Store
+	UserRepository *repository.UserRepository
+	AuditService   *AuditService
+}
+
+var LockoutServiceSet = wire.NewSet(
+	wire.Struct(new(LockoutService), "*"),
+	wire.Bind(new(ILockoutService), new(*LockoutService)),
+)
+
+// CheckLogin 账号或 IP 处于锁定期时返回带等待时间的错误
+func (s *LockoutService) CheckLogin(ctx context.Context, email, clientIP string) error {
+	wait, err := s.lockRemaining(ctx, loginLockEmailKeyPrefix+lockoutEmailKey(email))
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return errorx.WithRetryAfter(errorx.ErrLoginLocked, wait)
+	}
+
+	if clientIP == "" {
+		return nil
+	}
+	if wait, err = s.lockRemaining(ctx, loginLockIPKeyPrefix+clientIP); err != nil {
+		return err
+	}
+	if wait > 0 {
+		return errorx.WithRetryAfter(errorx.ErrLoginIPLocked, wait)
+	}
+	return nil
+}
+
+// LoginFailed 记录一次登录失败, 返回应响应给客户端的错误
+// 达到阈值时锁定并返回带等待时间的错误, 否则返回用户名或密码错误; userId 在账号不存在时为零值
+func (s *LockoutService) LoginFailed(ctx context.Context, email, clientIP string, userId bson.ObjectID) error {
+	var lockErr error
+	cfg := s.Config.Lockout
+	window := time.Duration(cfg.Window) * time.Second
+
+	// 按账号计数, 未注册的邮箱同样计数, 避免通过锁定行为判断账号是否存在
+	subject := lockoutEmailKey(email)
+	n, err := s.Store.Incr(ctx, loginFailEmailKeyPrefix+subject, window)
+	if err != nil {
+		log.CtxError(ctx, "failed to count login failures: %v", err)
+		return err
+	}
+	if wait := s.lockDuration(n, cfg.AccountThreshold); wait > 0 {
+		if err = s.lock(ctx, loginLockEmailKeyPrefix+subject, wait); err != nil {
+			return err
+		}
+		log.CtxInfo(ctx, "login locked for account %s after %d failures, duration %s", subject, n, wait)
+		targetType, targetId := AuditTargetEmail, subject
+		if !userId.IsZero() {
+			targetType, targetId = AuditTargetUser, userId.Hex()
+		}
+		s.recordLock(ctx, AuditActionLoginLocked, targetType, targetId, n, wait)
+		lockErr = errorx.WithRetryAfter(errorx.ErrLoginLocked, wait)
+	}
+
+	// 按 IP 计数
+	if clientIP != "" {
+		if n, err = s.Store.Incr(ctx, loginFailIPKeyPrefix+clientIP, window); err != nil {
+			log.CtxError(ctx, "failed to count login failures: %v", err)
+			return err
+		}
+		if wait := s.lockDuration(n, cfg.IPThreshold); wait > 0 {
+			if err = s.lock(ctx, loginLockIPKeyPrefix+clientIP, wait); err != nil {
+				return err
+			}
+			log.CtxInfo(ctx, "login locked for ip %s after %d failures, duration %s", clientIP, n, wait)
+			s.recordLock(ctx, AuditActionLoginIPLocked, AuditTargetIP, clientIP, n, wait)
+			if lockErr == nil {
+				lockErr = errorx.WithRetryAfter(errorx.ErrLoginIPLocked, wait)
+			}
+		}
+	}
+
+	if lockErr != nil {
+		return lockErr
+	}
+	return errorx.ErrUsernameOrPasswordIncorrect
+}
+
+// LoginSucceeded 登录成功后清除账号的失败计数, IP 的计数保留到过期
+func (s *LockoutService) LoginSucceeded(ctx context.Context, email string) {
+	if err := s.clear(ctx, loginFailEmailKeyPrefix, loginLockEmailKeyPrefix, lockoutEmailKey(email)); err != nil {
+		log.CtxError(ctx, "failed to clear login failures: %v", err)
+	}
+}
+
+// CheckTwoFactor 账号的两步验证处于锁定期时返回带等待时间的错误
+func (s *LockoutService) CheckTwoFactor(ctx context.Context, userId bson.ObjectID) error {
+	wait, err := s.lockRemaining(ctx, mfaLockUserKeyPrefix+userId.Hex())
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return errorx.WithRetryAfter(errorx.ErrTwoFactorLocked, wait)
+	}
+	return nil
+}
+
+// TwoFactorFailed 记录一次两步验证失败, 返回应响应给客户端的错误
+// 按用户计数, 重新输入密码获取新的二次验证令牌不会重置计数
+func (s *LockoutService) TwoFactorFailed(ctx context.Context, userId bson.ObjectID) error {
+	cfg := s.Config.Lockout
+	n, err := s.Store.Incr(ctx, mfaFailUserKeyPrefix+userId.Hex(), time.Duration(cfg.Window)*time.Second)
+	if err != nil {
+		log.CtxError(ctx, "failed to count two-factor failures: %v", err)
+		return err
+	}
+	if wait := s.lockDuration(n, cfg.AccountThreshold); wait > 0 {
+		if err = s.lock(ctx, mfaLockUserKeyPrefix+userId.Hex(), wait); err != nil {
+			return err
+		}
+		log.CtxInfo(ctx, "two-factor locked for user %s after %d failures, duration %s", userId.Hex(), n, wait)
+		s.recordLock(ctx, AuditActionTwoFactorLocked, AuditTargetUser, userId.Hex(), n, wait)
+		return errorx.WithRetryAfter(errorx.ErrTwoFactorLocked, wait)
+	}
+	return errorx.ErrTwoFactorCodeInvalid
+}
+
+// TwoFactorSucceeded 两步验证通过后清除账号的失败计数
+func (s *LockoutService) TwoFactorSucceeded(ctx context.Context, userId bson.ObjectID) {
+	if err := s.clear(ctx, mfaFailUserKeyPrefix, mfaLockUserKeyPrefix, userId.Hex()); err != nil {
+		log.CtxError(ctx, "failed to clear two-factor failures: %v", err)
+	}
+}
+
+// UnlockUser 解除账号的登录锁定与两步验证锁定, 并清除失败计数
+func (s *LockoutService) UnlockUser(ctx context.Context) (*admin.UnlockUserResp, error) {
+	var err error
+	var target *model.User
+
+	// 获取操作者与目标用户
+	operatorId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targetId, ok := ctx.Value(consts.ContextTargetID).(bson.ObjectID)
+	if !ok {
+		return nil, errorx.ErrContextUserIDInvalid
+	}
+	if target, err = s.UserRepository.FindUserByUserID(ctx, targetId); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrUserNotFound
+		}
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+
+	// 清除计数与锁定
+	log.CtxInfo(ctx, "user %s unlocks login of user %s", operatorId.Hex(), targetId.Hex())
+	if err = s.clear(ctx, loginFailEmailKeyPrefix, loginLockEmailKeyPrefix, lockoutEmailKey(target.Email)); err != nil {
+		log.CtxError(ctx, "failed to unlock user: %v", err)
+		return nil, err
+	}
+	if err = s.clear(ctx, mfaFailUserKeyPrefix, mfaLockUserKeyPrefix, targetId.Hex()); err != nil {
+		log.CtxError(ctx, "failed to unlock user: %v", err)
+		return nil, err
+	}
+	s.AuditService.Record(ctx, &AuditEntry{
+		Action:     AuditActionUserUnlock,
+		TargetType: AuditTargetUser,
+		TargetID:   targetId.Hex(),
+	})
+
+	return &admin.UnlockUserResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// UnlockIP 解除 IP 的登录锁定并清除失败计数
+func (s *LockoutService) UnlockIP(ctx context.Context, req *admin.UnlockIPReq) (*admin.UnlockIPResp, error) {
+	// 获取当前用户ID
+	operatorId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验 IP
+	ip := net.ParseIP(req.IP)
+	if ip == nil {
+		return nil, errorx.ErrIPInvalid
+	}
+
+	// 清除计数与锁定
+	log.CtxInfo(ctx, "user %s unlocks login of ip %s", operatorId.Hex(), ip.String())
+	if err = s.clear(ctx, loginFailIPKeyPrefix, loginLockIPKeyPrefix, ip.String()); err != nil {
+		log.CtxError(ctx, "failed to unlock ip: %v", err)
+		return nil, err
+	}
+	s.AuditService.Record(ctx, &AuditEntry{
+		Action:     AuditActionIPUnlock,
+		TargetType: AuditTargetIP,
+		TargetID:   ip.String(),
+	})
+
+	return &admin.UnlockIPResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// lockDuration 失败次数达到阈值后, 每多失败一次锁定时长翻倍, 未达到阈值时返回 0
+func (s *LockoutService) lockDuration(failures, threshold int64) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+	wait := time.Duration(s.Config.Lockout.BaseDuration) * time.Second
+	maxDuration := time.Duration(s.Config.Lockout.MaxDuration) * time.Second
+	for range failures - threshold {
+		if wait *= 2; wait >= maxDuration {
+			break
+		}
+	}
+	return min(wait, maxDuration)
+}
+
+// lock 锁定到 now+wait, 值为解锁时间的毫秒时间戳
+func (s *LockoutService) lock(ctx context.Context, key string, wait time.Duration) error {
+	until := time.Now().Add(wait).UnixMilli()
+	if err := s.Store.Set(ctx, key, strconv.FormatInt(until, 10), wait); err != nil {
+		log.CtxError(ctx, "failed to set login lock: %v", err)
+		return err
+	}
+	return nil
+}
+
+// lockRemaining 返回剩余的锁定时长, 未锁定时返回 0
+func (s *LockoutService) lockRemaining(ctx context.Context, key string) (time.Duration, error) {
+	value, ok, err := s.Store.Get(ctx, key)
+	if err != nil {
+		log.CtxError(ctx, "failed to get login lock: %v", err)
+		return 0, err
+	}
+	if !ok {
+		return 0, nil
+	}
+	until, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.CtxError(ctx, "invalid login lock value %q: %v", value, err)
+		return 0, nil
+	}
+	return max(time.Until(time.UnixMilli(until)), 0), nil
+}
+
+func (s *LockoutService) clear(ctx context.Context, failPrefix, lockPrefix, subject string) error {
+	if err := s.Store.Del(ctx, failPrefix+subject); err != nil {
+		return err
+	}
+	return s.Store.Del(ctx, lockPrefix+subject)
+}
+
+// recordLock 记录锁定的安全事件
+func (s *LockoutService) recordLock(ctx context.Context, action, targetType, targetId string, failures int64, wait time.Duration) {
+	s.AuditService.Record(ctx, &AuditEntry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetId,
+		Changes: []model.AuditChange{
+			{Field: "failures", After: strconv.FormatInt(failures, 10)},
+			{Field: "lockedFor", After: wait.String()},
+		},
+	})
+}
+
+// lockoutEmailKey 计数使用规范化邮箱的摘要, 避免在键值存储中保存邮箱原文
+func lockoutEmailKey(email string) string {
+	return security.HashToken(normalizeEmail(email))
+}
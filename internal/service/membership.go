@@ -0,0 +1,260 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/membership"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/eth"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/lib"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/merkle"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	addressLen      = 20
+	merkleRecordLen = objectIDLen + addressLen
+)
+
+// merkleTreeCache 缓存最新发布的树, 避免每次生成证明都重新计算
+var merkleTreeCache struct {
+	sync.Mutex
+	rootId  bson.ObjectID
+	records []byte
+	tree    *merkle.Tree
+}
+
+type IMembershipService interface {
+	PublishMerkleRoot(ctx context.Context) (*membership.PublishMerkleRootResp, error)
+	GetMerkleRoot(ctx context.Context) (*membership.GetMerkleRootResp, error)
+	GetMembershipProof(ctx context.Context) (*membership.GetMembershipProofResp, error)
+}
+
+type MembershipService struct {
+	UserRepository       *repository.UserRepository
+	WalletRepository     *repository.WalletRepository
+	MerkleRootRepository *repository.MerkleRootRepository
+}
+
+var MembershipServiceSet = wire.NewSet(
+	wire.Struct(new(MembershipService), "*"),
+	wire.Bind(new(IMembershipService), new(*MembershipService)),
+)
+
+// PublishMerkleRoot 对当前全部活跃用户(ID 与主钱包地址)构建 Merkle 树并发布树根
+func (s *MembershipService) PublishMerkleRoot(ctx context.Context) (*membership.PublishMerkleRootResp, error) {
+	var err error
+	var wallets []*model.Wallet
+
+	// 获取当前用户ID
+	operatorId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取主钱包
+	if wallets, err = s.WalletRepository.FindPrimaryWallets(ctx); err != nil {
+		return nil, err
+	}
+	addresses := make(map[bson.ObjectID]string, len(wallets))
+	for _, w := range wallets {
+		addresses[w.UserID] = w.Address
+	}
+
+	// 收集活跃用户并编码叶子
+	var records [][]byte
+	query := &repository.UserQuery{Status: enum.StatusActive}
+	if err = s.UserRepository.ScanUsers(ctx, query, repository.UserSort{Field: consts.CreatedAt}, func(u *model.User) error {
+		record, err := encodeMerkleRecord(u.ID, addresses[u.ID])
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	}); err != nil {
+		log.CtxError(ctx, "failed to scan users for merkle root: %v", err)
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errorx.ErrMerkleRootEmpty
+	}
+	slices.SortFunc(records, bytes.Compare)
+	content := lib.BuildBytes(records...)
+
+	// 构建树
+	tree, err := buildMerkleTree(content)
+	if err != nil {
+		log.CtxError(ctx, "failed to build merkle tree: %v", err)
+		return nil, err
+	}
+	data, err := lib.GzipCompress(content)
+	if err != nil {
+		log.CtxError(ctx, "failed to compress merkle leaves: %v", err)
+		return nil, err
+	}
+
+	// 发布
+	root := &model.MerkleRoot{
+		ID:        bson.NewObjectID(),
+		Root:      tree.Root().Hex(),
+		LeafCount: int64(tree.Len()),
+		Data:      data,
+		CreatorID: operatorId,
+		CreatedAt: time.Now(),
+	}
+	if err = s.MerkleRootRepository.Insert(ctx, root); err != nil {
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "user %s published merkle root %s over %d members", operatorId.Hex(), root.Root, root.LeafCount)
+	return &membership.PublishMerkleRootResp{
+		Resp:         dto.Success(),
+		MerkleRootVO: toMerkleRootVO(root),
+	}, nil
+}
+
+// GetMerkleRoot 获取最新发布的树根, 供外部合约与工具校验
+func (s *MembershipService) GetMerkleRoot(ctx context.Context) (*membership.GetMerkleRootResp, error) {
+	root, err := s.findLatest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &membership.GetMerkleRootResp{
+		Resp:         dto.Success(),
+		MerkleRootVO: toMerkleRootVO(root),
+	}, nil
+}
+
+// GetMembershipProof 返回当前用户在最新树中的包含证明
+func (s *MembershipService) GetMembershipProof(ctx context.Context) (*membership.GetMembershipProofResp, error) {
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取最新的树
+	root, err := s.findLatest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, tree, err := s.loadTree(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按成员ID查找叶子并生成证明
+	i, found := slices.BinarySearchFunc(records, userId, func(record []byte, id bson.ObjectID) int {
+		return bytes.Compare(record[:objectIDLen], id[:])
+	})
+	if !found {
+		return nil, errorx.ErrMembershipProofNotFound
+	}
+	leaf := merkle.HashLeaf(records[i])
+	proof, err := tree.Proof(leaf)
+	if err != nil {
+		log.CtxError(ctx, "failed to build proof for user %s: %v", userId.Hex(), err)
+		return nil, errorx.ErrMerkleRootCorrupted
+	}
+
+	resp := &membership.GetMembershipProofResp{
+		Resp:   dto.Success(),
+		Root:   toMerkleRootVO(root),
+		UserID: userId.Hex(),
+		Leaf:   leaf.Hex(),
+		Proof:  make([]string, 0, len(proof)),
+	}
+	if address := records[i][objectIDLen:]; !bytes.Equal(address, make([]byte, addressLen)) {
+		resp.Address, _ = eth.ChecksumAddress("0x" + hex.EncodeToString(address))
+	}
+	for _, p := range proof {
+		resp.Proof = append(resp.Proof, p.Hex())
+	}
+	return resp, nil
+}
+
+func (s *MembershipService) findLatest(ctx context.Context) (*model.MerkleRoot, error) {
+	root, err := s.MerkleRootRepository.FindLatest(ctx)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrMerkleRootNotFound
+		}
+		log.CtxError(ctx, "failed to find merkle root: %v", err)
+		return nil, err
+	}
+	return root, nil
+}
+
+// loadTree 解压叶子并重建树, 树根与发布时不一致时视为数据损坏
+func (s *MembershipService) loadTree(ctx context.Context, root *model.MerkleRoot) ([][]byte, *merkle.Tree, error) {
+	merkleTreeCache.Lock()
+	defer merkleTreeCache.Unlock()
+
+	if merkleTreeCache.rootId != root.ID {
+		content, err := lib.GzipDecompress(root.Data)
+		if err != nil || len(content)%merkleRecordLen != 0 {
+			log.CtxError(ctx, "failed to decompress merkle root %s: %v", root.ID.Hex(), err)
+			return nil, nil, errorx.ErrMerkleRootCorrupted
+		}
+		tree, err := buildMerkleTree(content)
+		if err != nil || tree.Root().Hex() != root.Root {
+			log.CtxError(ctx, "merkle root %s does not match its leaves", root.ID.Hex())
+			return nil, nil, errorx.ErrMerkleRootCorrupted
+		}
+		merkleTreeCache.rootId, merkleTreeCache.records, merkleTreeCache.tree = root.ID, content, tree
+	}
+
+	records := make([][]byte, 0, len(merkleTreeCache.records)/merkleRecordLen)
+	for i := 0; i < len(merkleTreeCache.records); i += merkleRecordLen {
+		records = append(records, merkleTreeCache.records[i:i+merkleRecordLen])
+	}
+	return records, merkleTreeCache.tree, nil
+}
+
+// encodeMerkleRecord 编码叶子原文: 12 字节成员ID + 20 字节钱包地址
+func encodeMerkleRecord(userId bson.ObjectID, address string) ([]byte, error) {
+	record := make([]byte, merkleRecordLen)
+	copy(record, userId[:])
+	if address != "" {
+		b, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+		if err != nil || len(b) != addressLen {
+			return nil, errors.New("invalid wallet address " + address)
+		}
+		copy(record[objectIDLen:], b)
+	}
+	return record, nil
+}
+
+func buildMerkleTree(content []byte) (*merkle.Tree, error) {
+	leaves := make([]merkle.Hash, 0, len(content)/merkleRecordLen)
+	for i := 0; i < len(content); i += merkleRecordLen {
+		leaves = append(leaves, merkle.HashLeaf(content[i:i+merkleRecordLen]))
+	}
+	return merkle.New(leaves)
+}
+
+func toMerkleRootVO(root *model.MerkleRoot) *membership.MerkleRootVO {
+	return &membership.MerkleRootVO{
+		ID:        root.ID,
+		Root:      root.Root,
+		LeafCount: root.LeafCount,
+		CreatedAt: root.CreatedAt,
+	}
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/organization"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/tally"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	maxOrganizationNameLen = 64
+	maxVotingPeriod        = 365 * 24 * 60 * 60
+)
+
+// slugPattern 小写字母、数字与连字符, 首尾不能是连字符, 长度 3-40
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,38}[a-z0-9]$`)
+
+// votingStrategies 组织可选的计票方式, 与 pkg/tally 一致
+var votingStrategies = map[string]bool{
+	tally.OnePersonOneVote: true,
+	tally.Weighted:         true,
+	tally.Quadratic:        true,
+	tally.Approval:         true,
+	tally.InstantRunoff:    true,
+}
+
+// defaultGovernance 创建组织时未指定治理参数使用的默认值
+var defaultGovernance = model.Governance{
+	VotingStrategy:    tally.OnePersonOneVote,
+	VotingPeriod:      7 * 24 * 60 * 60,
+	QuorumPercent:     10,
+	PassPercent:       50,
+	ProposalThreshold: 0,
+}
+
+type IOrganizationService interface {
+	CreateOrganization(ctx context.Context, req *organization.CreateOrganizationReq) (*organization.CreateOrganizationResp, error)
+	GetOrganization(ctx context.Context, req *organization.GetOrganizationReq) (*organization.GetOrganizationResp, error)
+	UpdateOrganization(ctx context.Context, req *organization.UpdateOrganizationReq) (*organization.UpdateOrganizationResp, error)
+	ArchiveOrganization(ctx context.Context, req *organization.ArchiveOrganizationReq) (*organization.ArchiveOrganizationResp, error)
+	ListOrganizations(ctx context.Context, req *organization.ListOrganizationsReq) (*organization.ListOrganizationsResp, error)
+}
+
+type OrganizationService struct {
+	OrganizationRepository *repository.OrganizationRepository
+	Authorizer             *auth.Authorizer
+}
+
+var OrganizationServiceSet = wire.NewSet(
+	wire.Struct(new(OrganizationService), "*"),
+	wire.Bind(new(IOrganizationService), new(*OrganizationService)),
+)
+
+func (s *OrganizationService) CreateOrganization(ctx context.Context, req *organization.CreateOrganizationReq) (*organization.CreateOrganizationResp, error) {
+	var err error
+
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验参数
+	if err = validateOrganizationName(req.Name); err != nil {
+		return nil, err
+	}
+	if !slugPattern.MatchString(req.Slug) {
+		return nil, errorx.ErrOrganizationSlugInvalid
+	}
+	governance := defaultGovernance
+	if req.Governance != nil {
+		if governance, err = toGovernance(req.Governance); err != nil {
+			return nil, err
+		}
+	}
+
+	// 创建组织
+	now := time.Now()
+	org := &model.Organization{
+		ID:          bson.NewObjectID(),
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+		Avatar:      req.Avatar,
+		Governance:  governance,
+		CreatorID:   userId,
+		Status:      enum.OrganizationActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err = s.OrganizationRepository.Insert(ctx, org); err != nil {
+		if errors.Is(err, repository.ErrOrganizationSlugExisted) {
+			return nil, errorx.ErrOrganizationSlugExisted
+		}
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "organization %s created by user %s", org.Slug, userId.Hex())
+	return &organization.CreateOrganizationResp{
+		Resp:           dto.Success(),
+		OrganizationVO: toOrganizationVO(org),
+	}, nil
+}
+
+func (s *OrganizationService) GetOrganization(ctx context.Context, req *organization.GetOrganizationReq) (*organization.GetOrganizationResp, error) {
+	org, err := s.findBySlug(ctx, req.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return &organization.GetOrganizationResp{
+		Resp:           dto.Success(),
+		OrganizationVO: toOrganizationVO(org),
+	}, nil
+}
+
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, req *organization.UpdateOrganizationReq) (*organization.UpdateOrganizationResp, error) {
+	var err error
+	var org *model.Organization
+
+	// 获取组织并校验权限
+	if org, err = s.findManageable(ctx, req.Slug); err != nil {
+		return nil, err
+	}
+	if org.Status == enum.OrganizationArchived {
+		return nil, errorx.ErrOrganizationArchived
+	}
+
+	// 构造更新内容
+	update := bson.M{}
+	if req.Name != "" {
+		if err = validateOrganizationName(req.Name); err != nil {
+			return nil, err
+		}
+		update[consts.Name] = req.Name
+	}
+	if req.NewSlug != "" && req.NewSlug != org.Slug {
+		if !slugPattern.MatchString(req.NewSlug) {
+			return nil, errorx.ErrOrganizationSlugInvalid
+		}
+		update[consts.Slug] = req.NewSlug
+	}
+	if req.Description != "" {
+		update[consts.Description] = req.Description
+	}
+	if req.Avatar != "" {
+		update[consts.Avatar] = req.Avatar
+	}
+	if req.Governance != nil {
+		governance, err := toGovernance(req.Governance)
+		if err != nil {
+			return nil, err
+		}
+		update[consts.Governance] = governance
+	}
+	update[consts.UpdatedAt] = time.Now()
+
+	// 更新组织
+	if err = s.OrganizationRepository.Update(ctx, org.ID, update); err != nil {
+		if errors.Is(err, repository.ErrOrganizationSlugExisted) {
+			return nil, errorx.ErrOrganizationSlugExisted
+		}
+		return nil, err
+	}
+	if org, err = s.OrganizationRepository.FindByID(ctx, org.ID); err != nil {
+		log.CtxError(ctx, "failed to find organization: %v", err)
+		return nil, err
+	}
+
+	return &organization.UpdateOrganizationResp{
+		Resp:           dto.Success(),
+		OrganizationVO: toOrganizationVO(org),
+	}, nil
+}
+
+// ArchiveOrganization 归档组织, 归档后只读
+func (s *OrganizationService) ArchiveOrganization(ctx context.Context, req *organization.ArchiveOrganizationReq) (*organization.ArchiveOrganizationResp, error) {
+	var err error
+	var org *model.Organization
+
+	// 获取组织并校验权限
+	if org, err = s.findManageable(ctx, req.Slug); err != nil {
+		return nil, err
+	}
+	if org.Status == enum.OrganizationArchived {
+		return nil, errorx.ErrOrganizationArchived
+	}
+
+	// 归档
+	now := time.Now()
+	if err = s.OrganizationRepository.Update(ctx, org.ID, bson.M{
+		consts.Status:     enum.OrganizationArchived,
+		consts.ArchivedAt: now,
+		consts.UpdatedAt:  now,
+	}); err != nil {
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "organization %s archived", org.Slug)
+	return &organization.ArchiveOrganizationResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+func (s *OrganizationService) ListOrganizations(ctx context.Context, req *organization.ListOrganizationsReq) (*organization.ListOrganizationsResp, error) {
+	var err error
+	var total int64
+	var orgs []*model.Organization
+
+	// 解析状态, 默认只列出活跃的组织
+	status := enum.OrganizationActive
+	switch req.Status {
+	case "":
+	case "all":
+		status = 0
+	default:
+		if status = enum.GetOrganizationStatusCode(req.Status); status == 0 {
+			return nil, errorx.ErrOrganizationStatusInvalid
+		}
+	}
+
+	// 计算分页
+	skip, limit := pageBounds(req.PageParam)
+
+	// 查询
+	if orgs, err = s.OrganizationRepository.FindOrganizations(ctx, status, skip, limit); err != nil {
+		return nil, err
+	}
+	if total, err = s.OrganizationRepository.CountOrganizations(ctx, status); err != nil {
+		return nil, err
+	}
+
+	vos := make([]*organization.OrganizationVO, 0, len(orgs))
+	for _, org := range orgs {
+		vos = append(vos, toOrganizationVO(org))
+	}
+	return &organization.ListOrganizationsResp{
+		Resp:          dto.Success(),
+		Total:         total,
+		Organizations: vos,
+	}, nil
+}
+
+func (s *OrganizationService) findBySlug(ctx context.Context, slug string) (*model.Organization, error) {
+	org, err := s.OrganizationRepository.FindBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrOrganizationNotFound
+		}
+		log.CtxError(ctx, "failed to find organization: %v", err)
+		return nil, err
+	}
+	return org, nil
+}
+
+// findManageable 获取组织, 只有创建者或拥有 organization.manage 权限的用户可以管理
+func (s *OrganizationService) findManageable(ctx context.Context, slug string) (*model.Organization, error) {
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.findBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if org.CreatorID != principal.UserID && !s.Authorizer.Allows(principal, auth.PermOrganizationManage) {
+		log.CtxInfo(ctx, "user %s cannot manage organization %s", principal.UserID.Hex(), slug)
+		return nil, errorx.ErrUserPermissionsInsufficient
+	}
+	return org, nil
+}
+
+func validateOrganizationName(name string) error {
+	if name == "" || utf8.RuneCountInString(name) > maxOrganizationNameLen {
+		return errorx.ErrOrganizationNameInvalid
+	}
+	return nil
+}
+
+// toGovernance 校验并转换治理参数
+func toGovernance(vo *organization.GovernanceVO) (model.Governance, error) {
+	if !votingStrategies[vo.VotingStrategy] ||
+		vo.VotingPeriod <= 0 || vo.VotingPeriod > maxVotingPeriod ||
+		vo.QuorumPercent < 0 || vo.QuorumPercent > 100 ||
+		vo.PassPercent <= 0 || vo.PassPercent > 100 ||
+		vo.ProposalThreshold < 0 {
+		return model.Governance{}, errorx.ErrGovernanceInvalid
+	}
+	return model.Governance{
+		VotingStrategy:    vo.VotingStrategy,
+		VotingPeriod:      vo.VotingPeriod,
+		QuorumPercent:     vo.QuorumPercent,
+		PassPercent:       vo.PassPercent,
+		ProposalThreshold: vo.ProposalThreshold,
+	}, nil
+}
+
+func toOrganizationVO(org *model.Organization) *organization.OrganizationVO {
+	vo := &organization.OrganizationVO{
+		ID:          org.ID,
+		Name:        org.Name,
+		Slug:        org.Slug,
+		Description: org.Description,
+		Avatar:      org.Avatar,
+		Governance: &organization.GovernanceVO{
+			VotingStrategy:    org.Governance.VotingStrategy,
+			VotingPeriod:      org.Governance.VotingPeriod,
+			QuorumPercent:     org.Governance.QuorumPercent,
+			PassPercent:       org.Governance.PassPercent,
+			ProposalThreshold: org.Governance.ProposalThreshold,
+		},
+		CreatorID: org.CreatorID,
+		Status:    enum.GetOrganizationStatusDesc(org.Status),
+		CreatedAt: org.CreatedAt,
+		UpdatedAt: org.UpdatedAt,
+	}
+	if !org.ArchivedAt.IsZero() {
+		vo.ArchivedAt = &org.ArchivedAt
+	}
+	return vo
+}
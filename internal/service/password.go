@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/jwt"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
+	"github.com/NoANameGroup/DAOld-Backend/internal/mail"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/security"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	passwordForgotEmailKeyPrefix = "password_forgot:email:"
+	passwordForgotIPKeyPrefix    = "password_forgot:ip:"
+)
+
+type IPasswordService interface {
+	ForgotPassword(ctx context.Context, req *user.ForgotPasswordReq) (*user.ForgotPasswordResp, error)
+	ResetPassword(ctx context.Context, req *user.ResetPasswordReq) (*user.ResetPasswordResp, error)
+}
+
+type PasswordService struct {
+	Config                  *config.Config
+	UserRepository          *repository.UserRepository
+	RefreshTokenRepository  *repository.RefreshTokenRepository
+	SessionRepository       *repository.SessionRepository
+	PasswordResetRepository *repository.PasswordResetRepository
+	TokenManager            *jwt.Manager
+	MailSender              mail.Sender
+	Store                   kv.Store
+	AuditService            *AuditService
+	LockoutService          *LockoutService
+}
+
+var PasswordServiceSet = wire.NewSet(
+	wire.Struct(new(PasswordService), "*"),
+	wire.Bind(new(IPasswordService), new(*PasswordService)),
+)
+
+// ForgotPassword 发送密码重置邮件
+// 无论邮箱是否注册都返回成功, 避免泄露账号是否存在
+func (s *PasswordService) ForgotPassword(ctx context.Context, req *user.ForgotPasswordReq) (*user.ForgotPasswordResp, error) {
+	var err error
+	var userModel *model.User
+	var token string
+
+	email := normalizeEmail(req.Email)
+	clientIP, _ := ctx.Value(consts.ContextClientIP).(string)
+
+	// 按邮箱与 IP 限流
+	if err = s.checkForgotRate(ctx, email, clientIP); err != nil {
+		return nil, err
+	}
+
+	// 获取用户, 不存在时直接返回成功; 钱包注册的账号没有邮箱
+	if email == "" {
+		return &user.ForgotPasswordResp{Resp: dto.Success()}, nil
+	}
+	if userModel, err = s.UserRepository.FindUserByEmail(ctx, email); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			log.CtxInfo(ctx, "password reset requested for unknown email")
+			return &user.ForgotPasswordResp{Resp: dto.Success()}, nil
+		}
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+
+	// 生成重置令牌, 数据库中只保存哈希
+	if token, err = security.GenerateRandomToken(32); err != nil {
+		log.CtxError(ctx, "failed to generate reset token: %v", err)
+		return nil, err
+	}
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(s.Config.PasswordReset.Expire) * time.Second)
+	if err = s.PasswordResetRepository.Insert(ctx, &model.PasswordReset{
+		ID:        bson.NewObjectID(),
+		UserID:    userModel.ID,
+		Email:     userModel.Email,
+		TokenHash: security.HashToken(token),
+		RequestIP: clientIP,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}); err != nil {
+		log.CtxError(ctx, "failed to insert password reset: %v", err)
+		return nil, err
+	}
+
+	// 异步发送邮件, 使响应时间与邮箱未注册时一致
+	link := token
+	if s.Config.PasswordReset.URL != "" {
+		link = s.Config.PasswordReset.URL + "?token=" + url.QueryEscape(token)
+	}
+	msg := &mail.Message{
+		To:      userModel.Email,
+		Subject: "重置你的 DAOld 密码",
+		Body: fmt.Sprintf("%s 你好,\n\n我们收到了重置密码的请求, 请在 %s 前打开以下链接设置新密码:\n\n%s\n\n如果这不是你本人的操作, 请忽略此邮件, 你的密码不会被修改。\n",
+			userModel.Username, expiresAt.Format(time.DateTime), link),
+	}
+	go func() {
+		if err := s.MailSender.Send(context.Background(), msg); err != nil {
+			log.Error("failed to send password reset email: %v", err)
+		}
+	}()
+
+	return &user.ForgotPasswordResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+func (s *PasswordService) ResetPassword(ctx context.Context, req *user.ResetPasswordReq) (*user.ResetPasswordResp, error) {
+	var err error
+	var ok bool
+	var reset *model.PasswordReset
+	var hashPassword string
+
+	// 检查确认密码是否匹配
+	if req.NewPassword != req.ConfirmPassword {
+		return nil, errorx.ErrConfirmPasswordNotMatch
+	}
+
+	// 查找重置令牌
+	if reset, err = s.PasswordResetRepository.FindByTokenHash(ctx, security.HashToken(req.Token)); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrPasswordResetTokenInvalid
+		}
+		log.CtxError(ctx, "failed to find password reset: %v", err)
+		return nil, err
+	}
+	if reset.Used || time.Now().After(reset.ExpiresAt) {
+		return nil, errorx.ErrPasswordResetTokenInvalid
+	}
+
+	// 标记令牌已使用
+	if ok, err = s.PasswordResetRepository.MarkUsed(ctx, reset.ID, time.Now()); err != nil {
+		log.CtxError(ctx, "failed to mark password reset used: %v", err)
+		return nil, err
+	} else if !ok {
+		return nil, errorx.ErrPasswordResetTokenInvalid
+	}
+
+	// 生成哈希密码并更新
+	if hashPassword, err = security.HashPassword(req.NewPassword); err != nil {
+		log.CtxError(ctx, "failed to hash password: %v", err)
+		return nil, err
+	}
+	if err = s.UserRepository.UpdatePassword(ctx, reset.UserID, hashPassword); err != nil {
+		log.CtxError(ctx, "failed to update password: %v", err)
+		return nil, err
+	}
+	if err = s.UserRepository.UpdateUser(ctx, reset.UserID, bson.M{consts.UpdatedAt: time.Now()}); err != nil {
+		log.CtxError(ctx, "failed to update user: %v", err)
+		return nil, err
+	}
+
+	// 其余重置令牌失效
+	if err = s.PasswordResetRepository.MarkAllUsedByUserID(ctx, reset.UserID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// 通过邮件完成重置即证明了邮箱所有权
+	if _, err = s.UserRepository.MarkEmailVerified(ctx, reset.UserID, reset.Email); err != nil {
+		return nil, err
+	}
+
+	// 吊销该用户的全部会话
+	if err = revokeSessions(ctx, s.TokenManager, s.RefreshTokenRepository, s.SessionRepository, reset.UserID); err != nil {
+		return nil, err
+	}
+
+	// 与登录成功一样清除该邮箱的登录失败计数与锁定, 两步验证的锁定不受影响
+	s.LockoutService.LoginSucceeded(ctx, reset.Email)
+
+	// 记录审计事件, 重置时没有登录用户, 操作者为空, 密码只记录已变更
+	s.AuditService.Record(ctx, &AuditEntry{
+		Action:     AuditActionUserPasswordReset,
+		TargetType: AuditTargetUser,
+		TargetID:   reset.UserID.Hex(),
+		Changes:    []model.AuditChange{{Field: consts.Password, Before: auditRedacted, After: auditRedacted}},
+	})
+
+	log.CtxInfo(ctx, "password of user %s reset", reset.UserID.Hex())
+	return &user.ResetPasswordResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// checkForgotRate 同一邮箱与同一 IP 在一小时内的请求次数分别受限
+func (s *PasswordService) checkForgotRate(ctx context.Context, email, clientIP string) error {
+	limits := []struct {
+		key   string
+		limit int64
+	}{
+		{passwordForgotEmailKeyPrefix + security.HashToken(email), s.Config.PasswordReset.PerEmailPerHour},
+		{passwordForgotIPKeyPrefix + clientIP, s.Config.PasswordReset.PerIPPerHour},
+	}
+	for _, l := range limits {
+		n, err := s.Store.Incr(ctx, l.key, time.Hour)
+		if err != nil {
+			log.CtxError(ctx, "failed to count password forgot requests: %v", err)
+			return err
+		}
+		if n > l.limit {
+			log.CtxInfo(ctx, "password forgot rate limited, key=%s", l.key)
+			return errorx.ErrPasswordResetTooFrequent
+		}
+	}
+	return nil
+}
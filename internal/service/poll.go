@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/poll"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/tally"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	maxPollTitleLen    = 128
+	maxPollOptionLen   = 64
+	minPollOptions     = 2
+	maxPollOptions     = 20
+	defaultPollCredits = 100
+	maxPollCredits     = 10000
+)
+
+type IPollService interface {
+	CreatePoll(ctx context.Context, req *poll.CreatePollReq) (*poll.CreatePollResp, error)
+	GetPoll(ctx context.Context, req *poll.GetPollReq) (*poll.GetPollResp, error)
+	CastBallot(ctx context.Context, req *poll.CastBallotReq) (*poll.CastBallotResp, error)
+	GetMyBallot(ctx context.Context, req *poll.GetMyBallotReq) (*poll.GetMyBallotResp, error)
+	GetPollResult(ctx context.Context, req *poll.GetPollResultReq) (*poll.GetPollResultResp, error)
+}
+
+type PollService struct {
+	PollRepository       *repository.PollRepository
+	PollBallotRepository *repository.PollBallotRepository
+}
+
+var PollServiceSet = wire.NewSet(
+	wire.Struct(new(PollService), "*"),
+	wire.Bind(new(IPollService), new(*PollService)),
+)
+
+func (s *PollService) CreatePoll(ctx context.Context, req *poll.CreatePollReq) (*poll.CreatePollResp, error) {
+	var err error
+
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验参数
+	if req.Title == "" || utf8.RuneCountInString(req.Title) > maxPollTitleLen {
+		return nil, errorx.ErrPollTitleInvalid
+	}
+	if err = validatePollOptions(req.Options); err != nil {
+		return nil, err
+	}
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = tally.OnePersonOneVote
+	}
+	if _, ok := tally.Get(strategy); !ok {
+		return nil, errorx.ErrPollStrategyInvalid
+	}
+	// 投票不关联持仓, 没有可用的票权来源, 加权计票会退化为一人一票
+	if strategy == tally.Weighted {
+		return nil, errorx.ErrPollStrategyUnsupported
+	}
+	now := time.Now()
+	if !req.Deadline.After(now) || req.Deadline.After(now.Add(maxVotingPeriod*time.Second)) {
+		return nil, errorx.ErrPollDeadlineInvalid
+	}
+	credits := int64(0)
+	if strategy == tally.Quadratic {
+		if credits = req.Credits; credits == 0 {
+			credits = defaultPollCredits
+		}
+		if credits < 0 || credits > maxPollCredits {
+			return nil, errorx.ErrPollCreditsInvalid
+		}
+	}
+
+	// 创建投票
+	p := &model.Poll{
+		ID:          bson.NewObjectID(),
+		Title:       req.Title,
+		Description: req.Description,
+		CreatorID:   userId,
+		Options:     req.Options,
+		Strategy:    strategy,
+		Credits:     credits,
+		Deadline:    req.Deadline,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err = s.PollRepository.Insert(ctx, p); err != nil {
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "poll %s created by user %s with strategy %s", p.ID.Hex(), userId.Hex(), strategy)
+	return &poll.CreatePollResp{
+		Resp:   dto.Success(),
+		PollVO: toPollVO(p, now),
+	}, nil
+}
+
+func (s *PollService) GetPoll(ctx context.Context, req *poll.GetPollReq) (*poll.GetPollResp, error) {
+	p, err := s.findByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &poll.GetPollResp{
+		Resp:   dto.Success(),
+		PollVO: toPollVO(p, time.Now()),
+	}, nil
+}
+
+// CastBallot 投票或改票, 截止后不能再修改
+func (s *PollService) CastBallot(ctx context.Context, req *poll.CastBallotReq) (*poll.CastBallotResp, error) {
+	var err error
+	var p *model.Poll
+	var ballot *model.PollBallot
+
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取投票并校验是否截止
+	if p, err = s.findByID(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !now.Before(p.Deadline) {
+		return nil, errorx.ErrPollClosed
+	}
+
+	// 按计票方式校验选票
+	ballot = &model.PollBallot{
+		ID:        bson.NewObjectID(),
+		PollID:    p.ID,
+		VoterID:   userId,
+		Choices:   req.Choices,
+		Credits:   req.Credits,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	strategy, ok := tally.Get(p.Strategy)
+	if !ok {
+		log.CtxError(ctx, "poll %s has unknown strategy %s", p.ID.Hex(), p.Strategy)
+		return nil, errorx.ErrPollStrategyInvalid
+	}
+	if err = strategy.Validate(p.Options, toTallyBallot(p, ballot)); err != nil {
+		log.CtxInfo(ctx, "invalid ballot for poll %s: %v", p.ID.Hex(), err)
+		return nil, errorx.ErrBallotInvalid
+	}
+
+	// 保存选票
+	if err = s.PollBallotRepository.Upsert(ctx, ballot); err != nil {
+		return nil, err
+	}
+	if ballot, err = s.PollBallotRepository.FindByVoter(ctx, p.ID, userId); err != nil {
+		log.CtxError(ctx, "failed to find ballot: %v", err)
+		return nil, err
+	}
+
+	return &poll.CastBallotResp{
+		Resp:     dto.Success(),
+		BallotVO: toBallotVO(ballot),
+	}, nil
+}
+
+func (s *PollService) GetMyBallot(ctx context.Context, req *poll.GetMyBallotReq) (*poll.GetMyBallotResp, error) {
+	var err error
+	var p *model.Poll
+	var ballot *model.PollBallot
+
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取选票
+	if p, err = s.findByID(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	if ballot, err = s.PollBallotRepository.FindByVoter(ctx, p.ID, userId); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrBallotNotFound
+		}
+		log.CtxError(ctx, "failed to find ballot: %v", err)
+		return nil, err
+	}
+
+	return &poll.GetMyBallotResp{
+		Resp:     dto.Success(),
+		BallotVO: toBallotVO(ballot),
+	}, nil
+}
+
+// GetPollResult 按投票的计票方式统计全部选票, 截止前返回实时结果
+func (s *PollService) GetPollResult(ctx context.Context, req *poll.GetPollResultReq) (*poll.GetPollResultResp, error) {
+	var err error
+	var p *model.Poll
+	var ballots []*model.PollBallot
+	var result *tally.Result
+
+	// 获取投票与选票
+	if p, err = s.findByID(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	if ballots, err = s.PollBallotRepository.FindByPollID(ctx, p.ID); err != nil {
+		return nil, err
+	}
+
+	// 计票
+	strategy, ok := tally.Get(p.Strategy)
+	if !ok {
+		log.CtxError(ctx, "poll %s has unknown strategy %s", p.ID.Hex(), p.Strategy)
+		return nil, errorx.ErrPollStrategyInvalid
+	}
+	tallyBallots := make([]tally.Ballot, 0, len(ballots))
+	for _, b := range ballots {
+		tallyBallots = append(tallyBallots, toTallyBallot(p, b))
+	}
+	if result, err = strategy.Tally(p.Options, tallyBallots); err != nil {
+		log.CtxError(ctx, "failed to tally poll %s: %v", p.ID.Hex(), err)
+		return nil, err
+	}
+
+	return &poll.GetPollResultResp{
+		Resp:   dto.Success(),
+		Final:  !time.Now().Before(p.Deadline),
+		Result: result,
+	}, nil
+}
+
+func (s *PollService) findByID(ctx context.Context, id string) (*model.Poll, error) {
+	pollId, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errorx.ErrPollIDInvalid
+	}
+	p, err := s.PollRepository.FindByID(ctx, pollId)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrPollNotFound
+		}
+		log.CtxError(ctx, "failed to find poll: %v", err)
+		return nil, err
+	}
+	return p, nil
+}
+
+func validatePollOptions(options []string) error {
+	if len(options) < minPollOptions || len(options) > maxPollOptions {
+		return errorx.ErrPollOptionsInvalid
+	}
+	seen := make(map[string]bool, len(options))
+	for _, o := range options {
+		if o == "" || utf8.RuneCountInString(o) > maxPollOptionLen || seen[o] {
+			return errorx.ErrPollOptionsInvalid
+		}
+		seen[o] = true
+	}
+	return nil
+}
+
+// toTallyBallot 转换为计票库的选票
+// 二次方计票的预算为投票设置的积分, 其他方式每张选票计 1 票
+func toTallyBallot(p *model.Poll, b *model.PollBallot) tally.Ballot {
+	ballot := tally.Ballot{
+		Voter:   b.VoterID.Hex(),
+		Weight:  1,
+		Choices: b.Choices,
+	}
+	if p.Strategy == tally.Quadratic {
+		ballot.Weight = float64(p.Credits)
+		ballot.Credits = make(map[string]float64, len(b.Credits))
+		for o, c := range b.Credits {
+			ballot.Credits[o] = float64(c)
+		}
+	}
+	return ballot
+}
+
+func toPollVO(p *model.Poll, now time.Time) *poll.PollVO {
+	return &poll.PollVO{
+		ID:          p.ID,
+		Title:       p.Title,
+		Description: p.Description,
+		CreatorID:   p.CreatorID,
+		Options:     p.Options,
+		Strategy:    p.Strategy,
+		Credits:     p.Credits,
+		Deadline:    p.Deadline,
+		Closed:      !now.Before(p.Deadline),
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+func toBallotVO(b *model.PollBallot) *poll.BallotVO {
+	return &poll.BallotVO{
+		PollID:    b.PollID,
+		Choices:   b.Choices,
+		Credits:   b.Credits,
+		CreatedAt: b.CreatedAt,
+		UpdatedAt: b.UpdatedAt,
+	}
+}
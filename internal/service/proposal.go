@@ -0,0 +1,435 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/proposal"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	maxProposalTitleLen         = 128
+	minProposalVotingPeriod     = 60 * 60
+	defaultProposalVotingPeriod = 7 * 24 * 60 * 60
+)
+
+// proposalTransitions 提案状态机, 只允许表中列出的流转, 已执行、未通过与已取消为终态
+var proposalTransitions = map[enum.ProposalStatus][]enum.ProposalStatus{
+	enum.ProposalDraft:     {enum.ProposalActive, enum.ProposalCancelled},
+	enum.ProposalActive:    {enum.ProposalSucceeded, enum.ProposalDefeated, enum.ProposalCancelled},
+	enum.ProposalSucceeded: {enum.ProposalExecuted, enum.ProposalCancelled},
+}
+
+// canTransition 判断状态机是否允许从 from 流转到 to
+func canTransition(from, to enum.ProposalStatus) bool {
+	return slices.Contains(proposalTransitions[from], to)
+}
+
+type IProposalService interface {
+	CreateProposal(ctx context.Context, req *proposal.CreateProposalReq) (*proposal.CreateProposalResp, error)
+	GetProposal(ctx context.Context, req *proposal.GetProposalReq) (*proposal.GetProposalResp, error)
+	UpdateProposal(ctx context.Context, req *proposal.UpdateProposalReq) (*proposal.UpdateProposalResp, error)
+	ActivateProposal(ctx context.Context, req *proposal.ActivateProposalReq) (*proposal.TransitionProposalResp, error)
+	CloseProposal(ctx context.Context, req *proposal.CloseProposalReq) (*proposal.TransitionProposalResp, error)
+	ExecuteProposal(ctx context.Context, req *proposal.ExecuteProposalReq) (*proposal.TransitionProposalResp, error)
+	CancelProposal(ctx context.Context, req *proposal.CancelProposalReq) (*proposal.TransitionProposalResp, error)
+	ListProposals(ctx context.Context, req *proposal.ListProposalsReq) (*proposal.ListProposalsResp, error)
+}
+
+type ProposalService struct {
+	ProposalRepository *repository.ProposalRepository
+	Authorizer         *auth.Authorizer
+}
+
+var ProposalServiceSet = wire.NewSet(
+	wire.Struct(new(ProposalService), "*"),
+	wire.Bind(new(IProposalService), new(*ProposalService)),
+)
+
+// CreateProposal 创建提案草稿, 只有状态正常的用户可以发起
+func (s *ProposalService) CreateProposal(ctx context.Context, req *proposal.CreateProposalReq) (*proposal.CreateProposalResp, error) {
+	var err error
+
+	// 获取当前用户并校验状态
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if principal.Status != enum.StatusActive {
+		return nil, errorx.ErrProposalCreateForbidden
+	}
+
+	// 校验参数
+	if err = validateProposalTitle(req.Title); err != nil {
+		return nil, err
+	}
+	votingPeriod := req.VotingPeriod
+	if votingPeriod == 0 {
+		votingPeriod = defaultProposalVotingPeriod
+	}
+	if err = validateProposalVotingPeriod(votingPeriod); err != nil {
+		return nil, err
+	}
+
+	// 创建草稿
+	now := time.Now()
+	p := &model.Proposal{
+		ID:           bson.NewObjectID(),
+		Title:        req.Title,
+		Description:  req.Description,
+		ProposerID:   principal.UserID,
+		Status:       enum.ProposalDraft,
+		VotingPeriod: votingPeriod,
+		Transitions: []model.ProposalTransition{{
+			To:      enum.ProposalDraft,
+			ActorID: principal.UserID,
+			At:      now,
+		}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err = s.ProposalRepository.Insert(ctx, p); err != nil {
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "proposal %s created by user %s", p.ID.Hex(), principal.UserID.Hex())
+	return &proposal.CreateProposalResp{
+		Resp:       dto.Success(),
+		ProposalVO: toProposalVO(p),
+	}, nil
+}
+
+func (s *ProposalService) GetProposal(ctx context.Context, req *proposal.GetProposalReq) (*proposal.GetProposalResp, error) {
+	p, err := s.findByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proposal.GetProposalResp{
+		Resp:       dto.Success(),
+		ProposalVO: toProposalVO(p),
+	}, nil
+}
+
+// UpdateProposal 修改草稿, 只有发起人可以修改
+func (s *ProposalService) UpdateProposal(ctx context.Context, req *proposal.UpdateProposalReq) (*proposal.UpdateProposalResp, error) {
+	var err error
+	var p *model.Proposal
+
+	// 获取当前用户
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取提案并校验权限
+	if p, err = s.findByID(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	if p.ProposerID != principal.UserID {
+		return nil, errorx.ErrUserPermissionsInsufficient
+	}
+	if p.Status != enum.ProposalDraft {
+		return nil, errorx.ErrProposalTransitionDenied
+	}
+
+	// 构造更新内容
+	update := bson.M{}
+	if req.Title != "" {
+		if err = validateProposalTitle(req.Title); err != nil {
+			return nil, err
+		}
+		update[consts.Title] = req.Title
+	}
+	if req.Description != "" {
+		update[consts.Description] = req.Description
+	}
+	if req.VotingPeriod != 0 {
+		if err = validateProposalVotingPeriod(req.VotingPeriod); err != nil {
+			return nil, err
+		}
+		update[consts.VotingPeriod] = req.VotingPeriod
+	}
+	update[consts.UpdatedAt] = time.Now()
+
+	// 更新草稿
+	if err = s.ProposalRepository.UpdateDraft(ctx, p.ID, update); err != nil {
+		if errors.Is(err, repository.ErrProposalStatusChanged) {
+			return nil, errorx.ErrProposalTransitionDenied
+		}
+		return nil, err
+	}
+	if p, err = s.ProposalRepository.FindByID(ctx, p.ID); err != nil {
+		log.CtxError(ctx, "failed to find proposal: %v", err)
+		return nil, err
+	}
+
+	return &proposal.UpdateProposalResp{
+		Resp:       dto.Success(),
+		ProposalVO: toProposalVO(p),
+	}, nil
+}
+
+// ActivateProposal 发起人开启投票, 投票截止时间为激活时间加投票期
+func (s *ProposalService) ActivateProposal(ctx context.Context, req *proposal.ActivateProposalReq) (*proposal.TransitionProposalResp, error) {
+	var err error
+	var p *model.Proposal
+
+	// 获取当前用户
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取提案并校验权限
+	if p, err = s.findByID(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	if p.ProposerID != principal.UserID {
+		return nil, errorx.ErrUserPermissionsInsufficient
+	}
+	if principal.Status != enum.StatusActive {
+		return nil, errorx.ErrProposalCreateForbidden
+	}
+
+	// 开启投票
+	now := time.Now()
+	return s.transition(ctx, p, enum.ProposalActive, principal.UserID, "", now, bson.M{
+		consts.VotingStartAt: now,
+		consts.VotingEndAt:   now.Add(time.Duration(p.VotingPeriod) * time.Second),
+	})
+}
+
+// CloseProposal 投票结束后登记投票结果
+func (s *ProposalService) CloseProposal(ctx context.Context, req *proposal.CloseProposalReq) (*proposal.TransitionProposalResp, error) {
+	var err error
+	var p *model.Proposal
+
+	// 获取当前用户并校验权限
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !s.Authorizer.Allows(principal, auth.PermProposalManage) {
+		return nil, errorx.ErrUserPermissionsInsufficient
+	}
+
+	// 解析投票结果
+	outcome := enum.GetProposalStatusCode(req.Outcome)
+	if outcome != enum.ProposalSucceeded && outcome != enum.ProposalDefeated {
+		return nil, errorx.ErrProposalOutcomeInvalid
+	}
+
+	// 获取提案, 投票截止前不能结算
+	if p, err = s.findByID(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if p.Status == enum.ProposalActive && now.Before(p.VotingEndAt) {
+		return nil, errorx.ErrProposalVotingNotEnded
+	}
+
+	return s.transition(ctx, p, outcome, principal.UserID, req.Reason, now, nil)
+}
+
+// ExecuteProposal 登记已通过的提案已经执行
+func (s *ProposalService) ExecuteProposal(ctx context.Context, req *proposal.ExecuteProposalReq) (*proposal.TransitionProposalResp, error) {
+	var err error
+	var p *model.Proposal
+
+	// 获取当前用户并校验权限
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !s.Authorizer.Allows(principal, auth.PermProposalManage) {
+		return nil, errorx.ErrUserPermissionsInsufficient
+	}
+
+	// 获取提案
+	if p, err = s.findByID(ctx, req.ID); err != nil {
+		return nil, err
+	}
+
+	return s.transition(ctx, p, enum.ProposalExecuted, principal.UserID, req.Reason, time.Now(), nil)
+}
+
+// CancelProposal 取消提案, 发起人可以取消自己草稿或投票中的提案, 取消任意提案需要 proposal.cancel 权限
+func (s *ProposalService) CancelProposal(ctx context.Context, req *proposal.CancelProposalReq) (*proposal.TransitionProposalResp, error) {
+	var err error
+	var p *model.Proposal
+
+	// 获取当前用户
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取提案并校验权限
+	if p, err = s.findByID(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	if !s.Authorizer.Allows(principal, auth.PermProposalCancel) {
+		if p.ProposerID != principal.UserID {
+			return nil, errorx.ErrUserPermissionsInsufficient
+		}
+		if p.Status != enum.ProposalDraft && p.Status != enum.ProposalActive {
+			return nil, errorx.ErrProposalTransitionDenied
+		}
+	}
+
+	return s.transition(ctx, p, enum.ProposalCancelled, principal.UserID, req.Reason, time.Now(), nil)
+}
+
+func (s *ProposalService) ListProposals(ctx context.Context, req *proposal.ListProposalsReq) (*proposal.ListProposalsResp, error) {
+	var err error
+	var total int64
+	var proposals []*model.Proposal
+	var status enum.ProposalStatus
+	var proposerId bson.ObjectID
+
+	// 解析过滤条件
+	if req.Status != "" {
+		if status = enum.GetProposalStatusCode(req.Status); status == 0 {
+			return nil, errorx.ErrProposalStatusInvalid
+		}
+	}
+	if req.ProposerID != "" {
+		if proposerId, err = bson.ObjectIDFromHex(req.ProposerID); err != nil {
+			return nil, errorx.ErrUserIDFormatInvalid
+		}
+	}
+
+	// 计算分页
+	skip, limit := pageBounds(req.PageParam)
+
+	// 查询
+	if proposals, err = s.ProposalRepository.FindProposals(ctx, status, proposerId, skip, limit); err != nil {
+		return nil, err
+	}
+	if total, err = s.ProposalRepository.CountProposals(ctx, status, proposerId); err != nil {
+		return nil, err
+	}
+
+	vos := make([]*proposal.ProposalVO, 0, len(proposals))
+	for _, p := range proposals {
+		vos = append(vos, toProposalVO(p))
+	}
+	return &proposal.ListProposalsResp{
+		Resp:      dto.Success(),
+		Total:     total,
+		Proposals: vos,
+	}, nil
+}
+
+// transition 按状态机校验并执行流转, 记录操作人与时间
+func (s *ProposalService) transition(ctx context.Context, p *model.Proposal, to enum.ProposalStatus, actorId bson.ObjectID, reason string, now time.Time, update bson.M) (*proposal.TransitionProposalResp, error) {
+	var err error
+
+	if !canTransition(p.Status, to) {
+		log.CtxInfo(ctx, "proposal %s cannot move from %d to %d", p.ID.Hex(), p.Status, to)
+		return nil, errorx.ErrProposalTransitionDenied
+	}
+
+	// 以当前状态为前置条件更新, 避免并发流转
+	transition := model.ProposalTransition{
+		From:    p.Status,
+		To:      to,
+		ActorID: actorId,
+		Reason:  reason,
+		At:      now,
+	}
+	if err = s.ProposalRepository.Transition(ctx, p.ID, transition, update); err != nil {
+		if errors.Is(err, repository.ErrProposalStatusChanged) {
+			return nil, errorx.ErrProposalTransitionDenied
+		}
+		return nil, err
+	}
+	if p, err = s.ProposalRepository.FindByID(ctx, p.ID); err != nil {
+		log.CtxError(ctx, "failed to find proposal: %v", err)
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "proposal %s moved from %d to %d by user %s", p.ID.Hex(), transition.From, to, actorId.Hex())
+	return &proposal.TransitionProposalResp{
+		Resp:       dto.Success(),
+		ProposalVO: toProposalVO(p),
+	}, nil
+}
+
+func (s *ProposalService) findByID(ctx context.Context, id string) (*model.Proposal, error) {
+	proposalId, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errorx.ErrProposalIDInvalid
+	}
+	p, err := s.ProposalRepository.FindByID(ctx, proposalId)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrProposalNotFound
+		}
+		log.CtxError(ctx, "failed to find proposal: %v", err)
+		return nil, err
+	}
+	return p, nil
+}
+
+func validateProposalTitle(title string) error {
+	if title == "" || utf8.RuneCountInString(title) > maxProposalTitleLen {
+		return errorx.ErrProposalTitleInvalid
+	}
+	return nil
+}
+
+func validateProposalVotingPeriod(period int64) error {
+	if period < minProposalVotingPeriod || period > maxVotingPeriod {
+		return errorx.ErrProposalPeriodInvalid
+	}
+	return nil
+}
+
+func toProposalVO(p *model.Proposal) *proposal.ProposalVO {
+	vo := &proposal.ProposalVO{
+		ID:           p.ID,
+		Title:        p.Title,
+		Description:  p.Description,
+		ProposerID:   p.ProposerID,
+		Status:       enum.GetProposalStatusDesc(p.Status),
+		VotingPeriod: p.VotingPeriod,
+		Transitions:  make([]*proposal.TransitionVO, 0, len(p.Transitions)),
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+	}
+	if !p.VotingStartAt.IsZero() {
+		vo.VotingStartAt = &p.VotingStartAt
+	}
+	if !p.VotingEndAt.IsZero() {
+		vo.VotingEndAt = &p.VotingEndAt
+	}
+	for _, t := range p.Transitions {
+		tvo := &proposal.TransitionVO{
+			To:      enum.GetProposalStatusDesc(t.To),
+			ActorID: t.ActorID,
+			Reason:  t.Reason,
+			At:      t.At,
+		}
+		if t.From != 0 {
+			tvo.From = enum.GetProposalStatusDesc(t.From)
+		}
+		vo.Transitions = append(vo.Transitions, tvo)
+	}
+	return vo
+}
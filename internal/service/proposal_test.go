@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+)
+
+func TestCanTransition(t *testing.T) {
+	statuses := []enum.ProposalStatus{
+		enum.ProposalDraft,
+		enum.ProposalActive,
+		enum.ProposalSucceeded,
+		enum.ProposalDefeated,
+		enum.ProposalExecuted,
+		enum.ProposalCancelled,
+	}
+	tests := []struct {
+		from    enum.ProposalStatus
+		allowed []enum.ProposalStatus
+	}{
+		{enum.ProposalDraft, []enum.ProposalStatus{enum.ProposalActive, enum.ProposalCancelled}},
+		{enum.ProposalActive, []enum.ProposalStatus{enum.ProposalSucceeded, enum.ProposalDefeated, enum.ProposalCancelled}},
+		{enum.ProposalSucceeded, []enum.ProposalStatus{enum.ProposalExecuted, enum.ProposalCancelled}},
+		{enum.ProposalDefeated, nil},
+		{enum.ProposalExecuted, nil},
+		{enum.ProposalCancelled, nil},
+	}
+	for _, tt := range tests {
+		allowed := make(map[enum.ProposalStatus]bool, len(tt.allowed))
+		for _, to := range tt.allowed {
+			allowed[to] = true
+		}
+		for _, to := range statuses {
+			if got := canTransition(tt.from, to); got != allowed[to] {
+				t.Errorf("canTransition(%s, %s) = %v, want %v",
+					enum.GetProposalStatusDesc(tt.from), enum.GetProposalStatusDesc(to), got, allowed[to])
+			}
+		}
+	}
+}
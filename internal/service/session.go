@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/jwt"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
+	"github.com/NoANameGroup/DAOld-Backend/internal/mail"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	sessionSeenKeyPrefix = "session_seen:"
+	sessionSeenInterval  = time.Minute // 最后活跃时间的最小更新间隔
+	maxSessionUALen      = 512
+	unknownDevice        = "未知设备"
+)
+
+// 按顺序匹配 User-Agent, 靠前的规则优先(如 Edge 与 Chrome 都包含 "Chrome/")
+var (
+	browserPatterns = []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+		{"python-requests/", "Python"},
+		{"Go-http-client/", "Go"},
+		{"PostmanRuntime/", "Postman"},
+	}
+	osPatterns = []struct{ token, name string }{
+		{"Windows", "Windows"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"CrOS", "ChromeOS"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+type ISessionService interface {
+	ListSessions(ctx context.Context, req *user.ListSessionsReq) (*user.ListSessionsResp, error)
+	RevokeSession(ctx context.Context, req *user.RevokeSessionReq) (*user.RevokeSessionResp, error)
+	RevokeOtherSessions(ctx context.Context) (*user.RevokeOtherSessionsResp, error)
+}
+
+type SessionService struct {
+	SessionRepository      *repository.SessionRepository
+	RefreshTokenRepository *repository.RefreshTokenRepository
+	TokenManager           *jwt.Manager
+	MailSender             mail.Sender
+	Store                  kv.Store
+}
+
+var SessionServiceSet = wire.NewSet(
+	wire.Struct(new(SessionService), "*"),
+	wire.Bind(new(ISessionService), new(*SessionService)),
+)
+
+// ListSessions 分页查看当前用户的登录记录, 标记发起请求的会话
+func (s *SessionService) ListSessions(ctx context.Context, req *user.ListSessionsReq) (*user.ListSessionsResp, error) {
+	var err error
+	var total int64
+	var sessions []*model.Session
+
+	// 获取当前用户
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 计算分页
+	skip, limit := pageBounds(req.PageParam)
+	now := time.Now()
+	activeAt := time.Time{}
+	if req.Active {
+		activeAt = now
+	}
+
+	// 查询
+	if sessions, err = s.SessionRepository.FindByUserID(ctx, principal.UserID, activeAt, skip, limit); err != nil {
+		return nil, err
+	}
+	if total, err = s.SessionRepository.CountByUserID(ctx, principal.UserID, activeAt); err != nil {
+		return nil, err
+	}
+
+	vos := make([]*user.SessionVO, 0, len(sessions))
+	for _, session := range sessions {
+		vos = append(vos, toSessionVO(session, principal.FamilyID, now))
+	}
+	return &user.ListSessionsResp{
+		Resp:     dto.Success(),
+		Total:    total,
+		Sessions: vos,
+	}, nil
+}
+
+// RevokeSession 吊销当前用户的指定会话, 吊销当前会话等同于退出登录
+func (s *SessionService) RevokeSession(ctx context.Context, req *user.RevokeSessionReq) (*user.RevokeSessionResp, error) {
+	var err error
+	var session *model.Session
+
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取会话, 只能吊销自己的会话
+	sessionId, err := bson.ObjectIDFromHex(req.SessionID)
+	if err != nil {
+		return nil, errorx.ErrSessionIDInvalid
+	}
+	if session, err = s.SessionRepository.FindByID(ctx, userId, sessionId); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrSessionNotFound
+		}
+		log.CtxError(ctx, "failed to find session: %v", err)
+		return nil, err
+	}
+
+	// 吊销会话, 已吊销的会话直接返回成功
+	if !session.Revoked {
+		if err = s.revokeFamily(ctx, session.FamilyID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &user.RevokeSessionResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// RevokeOtherSessions 吊销当前用户除发起请求的会话外的全部有效会话
+func (s *SessionService) RevokeOtherSessions(ctx context.Context) (*user.RevokeOtherSessionsResp, error) {
+	var err error
+	var sessions []*model.Session
+
+	// 获取当前用户
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取全部有效会话
+	if sessions, err = s.SessionRepository.FindByUserID(ctx, principal.UserID, time.Now(), 0, 0); err != nil {
+		return nil, err
+	}
+
+	// 逐个吊销
+	var count int64
+	for _, session := range sessions {
+		if session.FamilyID == principal.FamilyID {
+			continue
+		}
+		if err = s.revokeFamily(ctx, session.FamilyID); err != nil {
+			return nil, err
+		}
+		count++
+	}
+
+	return &user.RevokeOtherSessionsResp{
+		Resp:  dto.Success(),
+		Count: count,
+	}, nil
+}
+
+// createSession 登录成功后记录会话, 在从未使用过的设备上登录时发送邮件提醒
+func (s *SessionService) createSession(ctx context.Context, userModel *model.User, tokens *tokenPair) error {
+	var err error
+	var sessions, sameDevice int64
+
+	userAgent, _ := ctx.Value(consts.ContextUserAgent).(string)
+	ip, _ := ctx.Value(consts.ContextClientIP).(string)
+	device := describeDevice(userAgent)
+	if len(userAgent) > maxSessionUALen {
+		userAgent = userAgent[:maxSessionUALen]
+	}
+
+	// 检查是否为新设备, 首次登录不提醒
+	if sessions, err = s.SessionRepository.CountByUserID(ctx, userModel.ID, time.Time{}); err != nil {
+		return err
+	}
+	if sessions > 0 {
+		if sameDevice, err = s.SessionRepository.CountByDevice(ctx, userModel.ID, device); err != nil {
+			return err
+		}
+	}
+
+	// 记录会话
+	now := time.Now()
+	if err = s.SessionRepository.Insert(ctx, &model.Session{
+		ID:         bson.NewObjectID(),
+		UserID:     userModel.ID,
+		FamilyID:   tokens.FamilyID,
+		TokenID:    tokens.TokenID,
+		Device:     device,
+		UserAgent:  userAgent,
+		IP:         ip,
+		ExpiresAt:  tokens.RefreshExpiresAt,
+		LastSeenAt: now,
+		CreatedAt:  now,
+	}); err != nil {
+		return err
+	}
+
+	// 异步发送新设备登录提醒, 钱包注册的账号没有邮箱
+	if sessions > 0 && sameDevice == 0 && userModel.Email != "" {
+		log.CtxInfo(ctx, "user %s logged in from new device %s", userModel.ID.Hex(), device)
+		msg := &mail.Message{
+			To:      userModel.Email,
+			Subject: "你的 DAOld 账号在新设备上登录",
+			Body: fmt.Sprintf("%s 你好,\n\n你的账号于 %s 在新设备上登录:\n\n设备: %s\nIP: %s\n\n如果这是你本人的操作, 请忽略此邮件。否则请立即修改密码, 并在会话管理中吊销该设备的会话。\n",
+				userModel.Username, now.Format(time.DateTime), device, ip),
+		}
+		go func() {
+			if err := s.MailSender.Send(context.Background(), msg); err != nil {
+				log.Error("failed to send new device login email: %v", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// refreshSession 刷新令牌后记录新的访问令牌并延长会话有效期
+func (s *SessionService) refreshSession(ctx context.Context, tokens *tokenPair) error {
+	return s.SessionRepository.UpdateOnRefresh(ctx, tokens.FamilyID, tokens.TokenID, tokens.RefreshExpiresAt, time.Now())
+}
+
+// touch 更新会话的最后活跃时间, 同一会话每分钟最多写一次数据库
+// 失败只记录日志, 不影响请求
+func (s *SessionService) touch(ctx context.Context, familyId string) {
+	if familyId == "" {
+		return
+	}
+
+	key := sessionSeenKeyPrefix + familyId
+	if _, ok, err := s.Store.Get(ctx, key); err != nil || ok {
+		return
+	}
+	if err := s.Store.Set(ctx, key, "1", sessionSeenInterval); err != nil {
+		log.CtxError(ctx, "failed to mark session seen: %v", err)
+		return
+	}
+	if err := s.SessionRepository.UpdateLastSeen(ctx, familyId, time.Now()); err != nil {
+		log.CtxError(ctx, "failed to update session last seen: %v", err)
+	}
+}
+
+// revokeFamily 吊销一次登录的会话记录、刷新令牌与访问令牌
+func (s *SessionService) revokeFamily(ctx context.Context, familyId string) error {
+	if err := revokeTokenFamily(ctx, s.TokenManager, s.RefreshTokenRepository, familyId); err != nil {
+		return err
+	}
+	if err := s.SessionRepository.RevokeByFamilyID(ctx, familyId, time.Now()); err != nil {
+		log.CtxError(ctx, "failed to revoke session: %v", err)
+		return err
+	}
+	return nil
+}
+
+// describeDevice 从 User-Agent 中解析浏览器与操作系统, 作为判断新设备的依据
+func describeDevice(userAgent string) string {
+	browser := matchPattern(userAgent, browserPatterns)
+	os := matchPattern(userAgent, osPatterns)
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return unknownDevice
+	}
+}
+
+func matchPattern(userAgent string, patterns []struct{ token, name string }) string {
+	for _, p := range patterns {
+		if strings.Contains(userAgent, p.token) {
+			return p.name
+		}
+	}
+	return ""
+}
+
+func toSessionVO(session *model.Session, currentFamilyId string, now time.Time) *user.SessionVO {
+	vo := &user.SessionVO{
+		ID:         session.ID,
+		Device:     session.Device,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		Current:    session.FamilyID == currentFamilyId,
+		Active:     !session.Revoked && now.Before(session.ExpiresAt),
+		ExpiresAt:  session.ExpiresAt,
+		LastSeenAt: session.LastSeenAt,
+		CreatedAt:  session.CreatedAt,
+	}
+	if session.Revoked {
+		vo.RevokedAt = &session.RevokedAt
+	}
+	return vo
+}
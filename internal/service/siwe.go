@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/eth"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/siwe"
+	"github.com/google/wire"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	siweNonceKeyPrefix     = "siwe_nonce:"
+	siweNonceUsedKeyPrefix = "siwe_nonce_used:"
+)
+
+type ISIWEService interface {
+	Nonce(ctx context.Context) (*user.SIWENonceResp, error)
+	Login(ctx context.Context, req *user.SIWELoginReq) (*user.LoginResp, error)
+}
+
+type SIWEService struct {
+	Config           *config.Config
+	Store            kv.Store
+	UserRepository   *repository.UserRepository
+	WalletRepository *repository.WalletRepository
+	WalletService    *WalletService
+	InviteService    *InviteService
+	UserService      *UserService
+}
+
+var SIWEServiceSet = wire.NewSet(
+	wire.Struct(new(SIWEService), "*"),
+	wire.Bind(new(ISIWEService), new(*SIWEService)),
+)
+
+// Nonce 签发一次性 nonce, 客户端将其写入 EIP-4361 消息后交给钱包签名
+func (s *SIWEService) Nonce(ctx context.Context) (*user.SIWENonceResp, error) {
+	if s.Config.SIWE.Domain == "" {
+		return nil, errorx.ErrSIWEDisabled
+	}
+
+	nonce, err := siwe.GenerateNonce()
+	if err != nil {
+		log.CtxError(ctx, "failed to generate siwe nonce: %v", err)
+		return nil, err
+	}
+	if err = s.Store.Set(ctx, siweNonceKeyPrefix+nonce, "1", time.Duration(s.Config.SIWE.NonceExpire)*time.Second); err != nil {
+		log.CtxError(ctx, "failed to store siwe nonce: %v", err)
+		return nil, err
+	}
+
+	return &user.SIWENonceResp{
+		Resp:      dto.Success(),
+		Nonce:     nonce,
+		ExpiresIn: s.Config.SIWE.NonceExpire,
+	}, nil
+}
+
+// Login 校验钱包签名并登录, 地址未绑定任何账号时自动创建账号
+func (s *SIWEService) Login(ctx context.Context, req *user.SIWELoginReq) (*user.LoginResp, error) {
+	var err error
+	var msg *siwe.Message
+	var userModel *model.User
+
+	if s.Config.SIWE.Domain == "" {
+		return nil, errorx.ErrSIWEDisabled
+	}
+
+	// 解析并校验消息
+	if msg, err = siwe.ParseMessage(req.Message); err != nil {
+		log.CtxInfo(ctx, "invalid siwe message: %v", err)
+		return nil, errorx.ErrSIWEMessageInvalid
+	}
+	if err = msg.Validate(siwe.ValidateOptions{
+		Domain:   s.Config.SIWE.Domain,
+		URI:      s.Config.SIWE.URI,
+		ChainIDs: s.Config.SIWE.ChainIDs,
+		MaxAge:   time.Duration(s.Config.SIWE.MaxAge) * time.Second,
+	}); err != nil {
+		log.CtxInfo(ctx, "siwe message rejected: %v", err)
+		if errors.Is(err, siwe.ErrExpired) {
+			return nil, errorx.ErrSIWEMessageExpired
+		}
+		return nil, errorx.ErrSIWEMessageInvalid
+	}
+
+	// 校验签名
+	if err = msg.VerifySignature(req.Signature); err != nil {
+		log.CtxInfo(ctx, "siwe signature rejected for %s: %v", msg.Address, err)
+		return nil, errorx.ErrSIWESignatureInvalid
+	}
+
+	// 消费 nonce, 同一 nonce 只能登录一次
+	if err = s.consumeNonce(ctx, msg.Nonce); err != nil {
+		return nil, err
+	}
+
+	// 查找或创建地址对应的账号
+	if userModel, err = s.resolveUser(ctx, msg.Address, req.InviteCode); err != nil {
+		return nil, err
+	}
+
+	return s.UserService.continueLogin(ctx, userModel)
+}
+
+// consumeNonce 校验 nonce 由本服务签发且未被使用
+func (s *SIWEService) consumeNonce(ctx context.Context, nonce string) error {
+	_, ok, err := s.Store.Get(ctx, siweNonceKeyPrefix+nonce)
+	if err != nil {
+		log.CtxError(ctx, "failed to get siwe nonce: %v", err)
+		return err
+	}
+	if !ok {
+		return errorx.ErrSIWENonceInvalid
+	}
+
+	ttl := time.Duration(s.Config.SIWE.NonceExpire) * time.Second
+	n, err := s.Store.Incr(ctx, siweNonceUsedKeyPrefix+nonce, ttl)
+	if err != nil {
+		log.CtxError(ctx, "failed to mark siwe nonce used: %v", err)
+		return err
+	}
+	if n > 1 {
+		log.CtxInfo(ctx, "siwe nonce replayed")
+		return errorx.ErrSIWENonceInvalid
+	}
+	if err = s.Store.Del(ctx, siweNonceKeyPrefix+nonce); err != nil {
+		log.CtxError(ctx, "failed to delete siwe nonce: %v", err)
+	}
+	return nil
+}
+
+// resolveUser 获取地址绑定的账号, 未绑定时创建新账号并将该地址设为主钱包
+func (s *SIWEService) resolveUser(ctx context.Context, address, inviteCode string) (*model.User, error) {
+	address, err := eth.ChecksumAddress(address)
+	if err != nil {
+		return nil, errorx.ErrSIWEMessageInvalid
+	}
+
+	userModel, err := s.WalletService.FindUserByAddress(ctx, address)
+	if err == nil {
+		return userModel, nil
+	}
+	if !errors.Is(err, errorx.ErrWalletNotFound) {
+		return nil, err
+	}
+
+	// 校验并占用邀请码
+	invite, err := s.InviteService.RedeemForRegistration(ctx, inviteCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// 钱包账号通过签名证明身份, 没有待验证的邮箱
+	now := time.Now()
+	newUser := &model.User{
+		ID:        bson.NewObjectID(),
+		Username:  address[:6] + "…" + address[len(address)-4:],
+		Role:      enum.RoleUser,
+		Status:    enum.StatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	ApplyInvite(newUser, invite)
+	if err = s.UserRepository.Insert(ctx, newUser); err != nil {
+		log.CtxError(ctx, "failed to insert user: %v", err)
+		if invite != nil {
+			s.InviteService.Release(ctx, invite)
+		}
+		return nil, err
+	}
+	if err = s.WalletRepository.Insert(ctx, &model.Wallet{
+		ID:        bson.NewObjectID(),
+		UserID:    newUser.ID,
+		Address:   address,
+		Primary:   true,
+		CreatedAt: now,
+	}); err != nil {
+		if delErr := s.UserRepository.DeleteUser(ctx, newUser.ID); delErr != nil {
+			log.CtxError(ctx, "failed to roll back user %s: %v", newUser.ID.Hex(), delErr)
+		}
+		if invite != nil {
+			s.InviteService.Release(ctx, invite)
+		}
+		if errors.Is(err, repository.ErrWalletAddressExisted) {
+			// 并发登录已创建账号, 以已绑定的账号为准
+			return s.resolveUser(ctx, address, "")
+		}
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "user %s created by wallet %s", newUser.ID.Hex(), address)
+	return newUser, nil
+}
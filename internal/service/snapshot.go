@@ -0,0 +1,339 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
+	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/admin"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/lib"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	maxSnapshotNameLen   = 64
+	maxSnapshotDiffItems = 1000
+	objectIDLen          = 12
+)
+
+type ISnapshotService interface {
+	CreateSnapshot(ctx context.Context, req *admin.CreateSnapshotReq) (*admin.CreateSnapshotResp, error)
+	GetSnapshot(ctx context.Context, req *admin.GetSnapshotReq) (*admin.GetSnapshotResp, error)
+	ListSnapshots(ctx context.Context, req *admin.ListSnapshotsReq) (*admin.ListSnapshotsResp, error)
+	ListSnapshotMembers(ctx context.Context, req *admin.ListSnapshotMembersReq) (*admin.ListSnapshotMembersResp, error)
+	CheckSnapshotMember(ctx context.Context, req *admin.CheckSnapshotMemberReq) (*admin.CheckSnapshotMemberResp, error)
+	DiffSnapshots(ctx context.Context, req *admin.DiffSnapshotsReq) (*admin.DiffSnapshotsResp, error)
+}
+
+type SnapshotService struct {
+	UserRepository     *repository.UserRepository
+	SnapshotRepository *repository.SnapshotRepository
+}
+
+var SnapshotServiceSet = wire.NewSet(
+	wire.Struct(new(SnapshotService), "*"),
+	wire.Bind(new(ISnapshotService), new(*SnapshotService)),
+)
+
+// CreateSnapshot 冻结当前符合条件的成员名单
+func (s *SnapshotService) CreateSnapshot(ctx context.Context, req *admin.CreateSnapshotReq) (*admin.CreateSnapshotResp, error) {
+	var err error
+	var ids []bson.ObjectID
+
+	// 获取当前用户ID
+	operatorId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析条件
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > maxSnapshotNameLen {
+		return nil, errorx.ErrSnapshotNameInvalid
+	}
+	now := time.Now()
+	filter := model.SnapshotFilter{RegisteredBefore: req.RegisteredBefore}
+	if filter.RegisteredBefore.IsZero() {
+		filter.RegisteredBefore = now
+	} else if filter.RegisteredBefore.After(now) {
+		return nil, errorx.ErrSnapshotCutoffInvalid
+	}
+	if req.Status != "" {
+		if filter.Status = enum.GetUserStatusCode(req.Status); filter.Status == 0 {
+			return nil, errorx.ErrUserStatusInvalid
+		}
+	}
+	if req.Role != "" {
+		if filter.Role = enum.GetUserRoleCode(req.Role); filter.Role == 0 {
+			return nil, errorx.ErrUserRoleInvalid
+		}
+	}
+
+	// 收集成员
+	query := &repository.UserQuery{
+		Status:    filter.Status,
+		Role:      filter.Role,
+		CreatedTo: filter.RegisteredBefore,
+	}
+	if err = s.UserRepository.ScanUsers(ctx, query, repository.UserSort{Field: consts.CreatedAt}, func(u *model.User) error {
+		ids = append(ids, u.ID)
+		return nil
+	}); err != nil {
+		log.CtxError(ctx, "failed to scan users for snapshot: %v", err)
+		return nil, err
+	}
+
+	// 排序后编码、计算哈希并压缩
+	content := encodeSnapshotMembers(ids)
+	sum := sha256.Sum256(content)
+	data, err := lib.GzipCompress(content)
+	if err != nil {
+		log.CtxError(ctx, "failed to compress snapshot: %v", err)
+		return nil, err
+	}
+
+	// 保存快照
+	snapshot := &model.Snapshot{
+		ID:          bson.NewObjectID(),
+		Name:        req.Name,
+		Filter:      filter,
+		MemberCount: int64(len(content) / objectIDLen),
+		ContentHash: hex.EncodeToString(sum[:]),
+		Data:        data,
+		CreatorID:   operatorId,
+		CreatedAt:   now,
+	}
+	if err = s.SnapshotRepository.Insert(ctx, snapshot); err != nil {
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "user %s created snapshot %s with %d members", operatorId.Hex(), snapshot.ID.Hex(), snapshot.MemberCount)
+	return &admin.CreateSnapshotResp{
+		Resp:       dto.Success(),
+		SnapshotVO: toSnapshotVO(snapshot),
+	}, nil
+}
+
+func (s *SnapshotService) GetSnapshot(ctx context.Context, req *admin.GetSnapshotReq) (*admin.GetSnapshotResp, error) {
+	snapshot, err := s.findByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin.GetSnapshotResp{
+		Resp:       dto.Success(),
+		SnapshotVO: toSnapshotVO(snapshot),
+	}, nil
+}
+
+func (s *SnapshotService) ListSnapshots(ctx context.Context, req *admin.ListSnapshotsReq) (*admin.ListSnapshotsResp, error) {
+	var err error
+	var total int64
+	var snapshots []*model.Snapshot
+
+	// 计算分页
+	skip, limit := pageBounds(req.PageParam)
+
+	// 查询
+	if snapshots, err = s.SnapshotRepository.FindSnapshots(ctx, skip, limit); err != nil {
+		return nil, err
+	}
+	if total, err = s.SnapshotRepository.CountSnapshots(ctx); err != nil {
+		return nil, err
+	}
+
+	vos := make([]*admin.SnapshotVO, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		vos = append(vos, toSnapshotVO(snapshot))
+	}
+	return &admin.ListSnapshotsResp{
+		Resp:      dto.Success(),
+		Total:     total,
+		Snapshots: vos,
+	}, nil
+}
+
+// ListSnapshotMembers 按ID顺序分页返回快照成员
+func (s *SnapshotService) ListSnapshotMembers(ctx context.Context, req *admin.ListSnapshotMembersReq) (*admin.ListSnapshotMembersResp, error) {
+	members, err := s.loadMembers(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 计算分页
+	skip, limit := pageBounds(req.PageParam)
+	start := min(skip, int64(len(members)))
+	end := min(start+limit, int64(len(members)))
+
+	return &admin.ListSnapshotMembersResp{
+		Resp:    dto.Success(),
+		Total:   int64(len(members)),
+		Members: members[start:end],
+	}, nil
+}
+
+// CheckSnapshotMember 判断用户是否在快照中
+func (s *SnapshotService) CheckSnapshotMember(ctx context.Context, req *admin.CheckSnapshotMemberReq) (*admin.CheckSnapshotMemberResp, error) {
+	userId, err := bson.ObjectIDFromHex(req.UserID)
+	if err != nil {
+		return nil, errorx.ErrUserIDFormatInvalid
+	}
+	members, err := s.loadMembers(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, found := slices.BinarySearchFunc(members, userId, compareObjectID)
+	return &admin.CheckSnapshotMemberResp{
+		Resp:   dto.Success(),
+		Member: found,
+	}, nil
+}
+
+// DiffSnapshots 比较两个快照的成员, 两个列表均已排序, 一次归并即可得到差异
+func (s *SnapshotService) DiffSnapshots(ctx context.Context, req *admin.DiffSnapshotsReq) (*admin.DiffSnapshotsResp, error) {
+	current, err := s.loadMembers(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	against, err := s.loadMembers(ctx, req.Against)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &admin.DiffSnapshotsResp{
+		Resp:    dto.Success(),
+		Added:   make([]bson.ObjectID, 0),
+		Removed: make([]bson.ObjectID, 0),
+	}
+	added := func(id bson.ObjectID) {
+		if resp.AddedCount++; len(resp.Added) < maxSnapshotDiffItems {
+			resp.Added = append(resp.Added, id)
+		}
+	}
+	removed := func(id bson.ObjectID) {
+		if resp.RemovedCount++; len(resp.Removed) < maxSnapshotDiffItems {
+			resp.Removed = append(resp.Removed, id)
+		}
+	}
+	i, j := 0, 0
+	for i < len(current) || j < len(against) {
+		switch {
+		case j == len(against) || (i < len(current) && compareObjectID(current[i], against[j]) < 0):
+			added(current[i])
+			i++
+		case i == len(current) || compareObjectID(current[i], against[j]) > 0:
+			removed(against[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	resp.Truncated = resp.AddedCount > maxSnapshotDiffItems || resp.RemovedCount > maxSnapshotDiffItems
+
+	return resp, nil
+}
+
+func (s *SnapshotService) findByID(ctx context.Context, id string) (*model.Snapshot, error) {
+	snapshotId, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errorx.ErrSnapshotIDInvalid
+	}
+	snapshot, err := s.SnapshotRepository.FindByID(ctx, snapshotId)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrSnapshotNotFound
+		}
+		log.CtxError(ctx, "failed to find snapshot: %v", err)
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// loadMembers 解压快照并校验哈希, 返回排好序的成员ID
+func (s *SnapshotService) loadMembers(ctx context.Context, id string) ([]bson.ObjectID, error) {
+	snapshot, err := s.findByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	content, err := lib.GzipDecompress(snapshot.Data)
+	if err != nil {
+		log.CtxError(ctx, "failed to decompress snapshot %s: %v", id, err)
+		return nil, errorx.ErrSnapshotCorrupted
+	}
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != snapshot.ContentHash || len(content)%objectIDLen != 0 {
+		log.CtxError(ctx, "snapshot %s content hash mismatch", id)
+		return nil, errorx.ErrSnapshotCorrupted
+	}
+
+	members := make([]bson.ObjectID, len(content)/objectIDLen)
+	for i := range members {
+		copy(members[i][:], content[i*objectIDLen:])
+	}
+	return members, nil
+}
+
+// encodeSnapshotMembers 按字节升序排列后拼接成员ID, 相同成员集合的编码与哈希一致
+func encodeSnapshotMembers(ids []bson.ObjectID) []byte {
+	slices.SortFunc(ids, compareObjectID)
+	ids = slices.Compact(ids)
+	content := make([][]byte, 0, len(ids))
+	for _, id := range ids {
+		content = append(content, id[:])
+	}
+	return lib.BuildBytes(content...)
+}
+
+func compareObjectID(a, b bson.ObjectID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+// pageBounds 将分页参数转换为 skip 与 limit
+func pageBounds(page dto.PageParam) (int64, int64) {
+	pageSize := page.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	} else if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	skip := int64(0)
+	if page.PageNum > 1 {
+		skip = int64(page.PageNum-1) * int64(pageSize)
+	}
+	return skip, int64(pageSize)
+}
+
+func toSnapshotVO(snapshot *model.Snapshot) *admin.SnapshotVO {
+	vo := &admin.SnapshotVO{
+		ID:               snapshot.ID,
+		Name:             snapshot.Name,
+		RegisteredBefore: snapshot.Filter.RegisteredBefore,
+		MemberCount:      snapshot.MemberCount,
+		ContentHash:      snapshot.ContentHash,
+		CreatorID:        snapshot.CreatorID,
+		CreatedAt:        snapshot.CreatedAt,
+	}
+	if snapshot.Filter.Status != 0 {
+		vo.Status = enum.GetUserStatusDesc(snapshot.Filter.Status)
+	}
+	if snapshot.Filter.Role != 0 {
+		vo.Role = enum.GetUserRoleDesc(snapshot.Filter.Role)
+	}
+	return vo
+}
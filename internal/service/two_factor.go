@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/security"
+	"github.com/google/wire"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	mfaPendingKeyPrefix  = "mfa_pending:"
+	mfaAttemptsKeyPrefix = "mfa_attempts:"
+)
+
+type ITwoFactorService interface {
+	SetupTOTP(ctx context.Context) (*user.SetupTOTPResp, error)
+	ConfirmTOTP(ctx context.Context, req *user.ConfirmTOTPReq) (*user.ConfirmTOTPResp, error)
+	DisableTOTP(ctx context.Context, req *user.DisableTOTPReq) (*user.DisableTOTPResp, error)
+	RegenerateRecoveryCodes(ctx context.Context, req *user.RegenerateRecoveryCodesReq) (*user.RegenerateRecoveryCodesResp, error)
+}
+
+type TwoFactorService struct {
+	Config         *config.Config
+	UserRepository *repository.UserRepository
+	Authorizer     *auth.Authorizer
+	Store          kv.Store
+	LockoutService *LockoutService
+	WalletService  *WalletService
+}
+
+var TwoFactorServiceSet = wire.NewSet(
+	wire.Struct(new(TwoFactorService), "*"),
+	wire.Bind(new(ITwoFactorService), new(*TwoFactorService)),
+)
+
+// SetupTOTP 生成待确认的 TOTP 密钥, 用户用验证器扫描后需提交首个验证码确认
+func (s *TwoFactorService) SetupTOTP(ctx context.Context) (*user.SetupTOTPResp, error) {
+	var err error
+	var userModel *model.User
+	var secret string
+
+	// 获取当前用户
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userModel, err = s.UserRepository.FindUserByUserID(ctx, userId); err != nil {
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+	if userModel.TOTPEnabled {
+		return nil, errorx.ErrTwoFactorAlreadyEnabled
+	}
+
+	// 生成密钥, 确认前不生效
+	if secret, err = security.GenerateTOTPSecret(); err != nil {
+		log.CtxError(ctx, "failed to generate totp secret: %v", err)
+		return nil, err
+	}
+	if err = s.UserRepository.SetTOTPPendingSecret(ctx, userId, secret); err != nil {
+		return nil, err
+	}
+
+	return &user.SetupTOTPResp{
+		Resp:   dto.Success(),
+		Secret: secret,
+		URI:    security.TOTPURI(s.Config.TwoFactor.Issuer, userModel.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP 用首个验证码确认密钥并启用两步验证, 同时返回恢复码
+func (s *TwoFactorService) ConfirmTOTP(ctx context.Context, req *user.ConfirmTOTPReq) (*user.ConfirmTOTPResp, error) {
+	var err error
+	var ok bool
+	var userModel *model.User
+	var codes, hashes []string
+
+	// 获取当前用户
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userModel, err = s.UserRepository.FindUserByUserID(ctx, userId); err != nil {
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+	if userModel.TOTPEnabled {
+		return nil, errorx.ErrTwoFactorAlreadyEnabled
+	}
+	if userModel.TOTPPending == "" {
+		return nil, errorx.ErrTwoFactorNotSetup
+	}
+
+	// 校验首个验证码
+	step, ok := security.ValidateTOTP(userModel.TOTPPending, req.Code, time.Now())
+	if !ok {
+		log.CtxInfo(ctx, "totp confirmation code incorrect for user %s", userId.Hex())
+		return nil, errorx.ErrTwoFactorCodeInvalid
+	}
+
+	// 生成恢复码并启用
+	if codes, hashes, err = s.generateRecoveryCodes(ctx); err != nil {
+		return nil, err
+	}
+	if ok, err = s.UserRepository.EnableTOTP(ctx, userId, userModel.TOTPPending, step, hashes); err != nil {
+		return nil, err
+	} else if !ok {
+		// 确认期间密钥被重新生成, 需要重新扫描
+		return nil, errorx.ErrTwoFactorNotSetup
+	}
+
+	log.CtxInfo(ctx, "totp enabled for user %s", userId.Hex())
+	return &user.ConfirmTOTPResp{
+		Resp:          dto.Success(),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (s *TwoFactorService) DisableTOTP(ctx context.Context, req *user.DisableTOTPReq) (*user.DisableTOTPResp, error) {
+	var err error
+	var userModel *model.User
+
+	// 获取当前用户
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userModel, err = s.UserRepository.FindUserByUserID(ctx, principal.UserID); err != nil {
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+	if !userModel.TOTPEnabled {
+		return nil, errorx.ErrTwoFactorNotEnabled
+	}
+
+	// 角色策略要求开启两步验证时不允许关闭
+	if s.Authorizer.RequiresTwoFactor(userModel.Role) {
+		return nil, errorx.ErrTwoFactorRequired
+	}
+
+	// 确认身份后校验验证码, 未设置密码的账号使用已绑定钱包的签名确认
+	if err = s.confirmIdentity(ctx, userModel, req.Password, user.ReauthProof{Address: req.Address, Signature: req.Signature}); err != nil {
+		return nil, err
+	}
+	if err = s.verifyCodeWithLockout(ctx, userModel, req.Code, req.RecoveryCode); err != nil {
+		return nil, err
+	}
+
+	// 关闭两步验证
+	if err = s.UserRepository.DisableTOTP(ctx, userModel.ID); err != nil {
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "totp disabled for user %s", userModel.ID.Hex())
+	return &user.DisableTOTPResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码, 旧的恢复码全部失效
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, req *user.RegenerateRecoveryCodesReq) (*user.RegenerateRecoveryCodesResp, error) {
+	var err error
+	var userModel *model.User
+	var codes, hashes []string
+
+	// 获取当前用户
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userModel, err = s.UserRepository.FindUserByUserID(ctx, userId); err != nil {
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+	if !userModel.TOTPEnabled {
+		return nil, errorx.ErrTwoFactorNotEnabled
+	}
+
+	// 只接受 TOTP 验证码, 避免用最后一个恢复码无限续期
+	if err = s.verifyCodeWithLockout(ctx, userModel, req.Code, ""); err != nil {
+		return nil, err
+	}
+
+	// 生成新的恢复码
+	if codes, hashes, err = s.generateRecoveryCodes(ctx); err != nil {
+		return nil, err
+	}
+	if err = s.UserRepository.SetRecoveryCodes(ctx, userId, hashes); err != nil {
+		return nil, err
+	}
+
+	return &user.RegenerateRecoveryCodesResp{
+		Resp:          dto.Success(),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// IssuePendingToken 密码校验通过后签发短期的二次验证令牌, 令牌只能用于完成本次登录
+func (s *TwoFactorService) IssuePendingToken(ctx context.Context, userId bson.ObjectID) (string, error) {
+	token, err := security.GenerateRandomToken(32)
+	if err != nil {
+		log.CtxError(ctx, "failed to generate mfa token: %v", err)
+		return "", err
+	}
+
+	ttl := time.Duration(s.Config.TwoFactor.PendingExpire) * time.Second
+	if err = s.Store.Set(ctx, mfaPendingKeyPrefix+security.HashToken(token), userId.Hex(), ttl); err != nil {
+		log.CtxError(ctx, "failed to store mfa token: %v", err)
+		return "", err
+	}
+	return token, nil
+}
+
+// VerifyPendingLogin 校验二次验证令牌与验证码, 成功后令牌失效并返回待登录的用户
+// 失败次数同时按令牌与用户计数, 按用户计数达到阈值后锁定账号的两步验证
+func (s *TwoFactorService) VerifyPendingLogin(ctx context.Context, req *user.LoginTwoFactorReq) (*model.User, error) {
+	var err error
+	var userModel *model.User
+
+	// 查找二次验证令牌
+	tokenHash := security.HashToken(req.MFAToken)
+	value, ok, err := s.Store.Get(ctx, mfaPendingKeyPrefix+tokenHash)
+	if err != nil {
+		log.CtxError(ctx, "failed to get mfa token: %v", err)
+		return nil, err
+	}
+	if !ok {
+		return nil, errorx.ErrMFATokenInvalid
+	}
+	userId, err := bson.ObjectIDFromHex(value)
+	if err != nil {
+		return nil, errorx.ErrMFATokenInvalid
+	}
+	if err = s.LockoutService.CheckTwoFactor(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	// 限制同一令牌的尝试次数, 超过后需要重新输入密码
+	ttl := time.Duration(s.Config.TwoFactor.PendingExpire) * time.Second
+	attempts, err := s.Store.Incr(ctx, mfaAttemptsKeyPrefix+tokenHash, ttl)
+	if err != nil {
+		log.CtxError(ctx, "failed to count mfa attempts: %v", err)
+		return nil, err
+	}
+	if attempts > s.Config.TwoFactor.MaxAttempts {
+		log.CtxInfo(ctx, "too many mfa attempts for user %s", userId.Hex())
+		s.discardPendingToken(ctx, tokenHash)
+		return nil, errorx.ErrMFATokenInvalid
+	}
+
+	// 校验验证码
+	if userModel, err = s.UserRepository.FindUserByUserID(ctx, userId); err != nil {
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+	if !userModel.TOTPEnabled {
+		return nil, errorx.ErrMFATokenInvalid
+	}
+	if err = s.verifyCode(ctx, userModel, req.Code, req.RecoveryCode); err != nil {
+		if errors.Is(err, errorx.ErrTwoFactorCodeInvalid) {
+			return nil, s.LockoutService.TwoFactorFailed(ctx, userId)
+		}
+		return nil, err
+	}
+
+	s.LockoutService.TwoFactorSucceeded(ctx, userId)
+	s.discardPendingToken(ctx, tokenHash)
+	return userModel, nil
+}
+
+func (s *TwoFactorService) discardPendingToken(ctx context.Context, tokenHash string) {
+	for _, key := range []string{mfaPendingKeyPrefix + tokenHash, mfaAttemptsKeyPrefix + tokenHash} {
+		if err := s.Store.Del(ctx, key); err != nil {
+			log.CtxError(ctx, "failed to delete mfa token: %v", err)
+		}
+	}
+}
+
+// verifyCode 校验 TOTP 验证码或恢复码, 二者均为一次性
+func (s *TwoFactorService) verifyCode(ctx context.Context, userModel *model.User, code, recoveryCode string) error {
+	if code != "" {
+		step, ok := security.ValidateTOTP(userModel.TOTPSecret, code, time.Now())
+		if !ok {
+			log.CtxInfo(ctx, "totp code incorrect for user %s", userModel.ID.Hex())
+			return errorx.ErrTwoFactorCodeInvalid
+		}
+		used, err := s.UserRepository.UseTOTPStep(ctx, userModel.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			log.CtxInfo(ctx, "totp code replayed for user %s", userModel.ID.Hex())
+			return errorx.ErrTwoFactorCodeInvalid
+		}
+		return nil
+	}
+
+	if recoveryCode != "" {
+		consumed, err := s.UserRepository.ConsumeRecoveryCode(ctx, userModel.ID, security.HashToken(security.NormalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			log.CtxInfo(ctx, "recovery code incorrect for user %s", userModel.ID.Hex())
+			return errorx.ErrTwoFactorCodeInvalid
+		}
+		log.CtxInfo(ctx, "recovery code used by user %s", userModel.ID.Hex())
+		return nil
+	}
+
+	return errorx.ErrTwoFactorCodeInvalid
+}
+
+// confirmIdentity 确认敏感操作由账号持有者本人发起
+// 设置了密码的账号校验密码; 钱包注册且未设置密码的账号校验两步验证码或已绑定钱包的签名
+func (s *TwoFactorService) confirmIdentity(ctx context.Context, userModel *model.User, password string, proof user.ReauthProof) error {
+	if userModel.Password != "" {
+		if !security.ComparePassword(userModel.Password, password) {
+			log.CtxInfo(ctx, "wrong password")
+			return errorx.ErrPasswordIncorrect
+		}
+		return nil
+	}
+
+	switch {
+	case proof.Code != "" && userModel.TOTPEnabled:
+		return s.verifyCodeWithLockout(ctx, userModel, proof.Code, "")
+	case proof.Signature != "":
+		return s.WalletService.verifyReauth(ctx, userModel.ID, proof.Address, proof.Signature)
+	default:
+		log.CtxInfo(ctx, "password-less user %s provided no reauth proof", userModel.ID.Hex())
+		return errorx.ErrReauthRequired
+	}
+}
+
+// verifyCodeWithLockout 校验验证码或恢复码, 与登录共用按用户计数的两步验证失败次数与锁定
+func (s *TwoFactorService) verifyCodeWithLockout(ctx context.Context, userModel *model.User, code, recoveryCode string) error {
+	if err := s.LockoutService.CheckTwoFactor(ctx, userModel.ID); err != nil {
+		return err
+	}
+	if err := s.verifyCode(ctx, userModel, code, recoveryCode); err != nil {
+		if errors.Is(err, errorx.ErrTwoFactorCodeInvalid) {
+			return s.LockoutService.TwoFactorFailed(ctx, userModel.ID)
+		}
+		return err
+	}
+	s.LockoutService.TwoFactorSucceeded(ctx, userModel.ID)
+	return nil
+}
+
+// generateRecoveryCodes 生成恢复码, 返回明文(只展示一次)与用于存储的哈希
+func (s *TwoFactorService) generateRecoveryCodes(ctx context.Context) ([]string, []string, error) {
+	codes, err := security.GenerateRecoveryCodes(s.Config.TwoFactor.RecoveryCodes)
+	if err != nil {
+		log.CtxError(ctx, "failed to generate recovery codes: %v", err)
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = security.HashToken(security.NormalizeRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
 	"github.com/NoANameGroup/DAOld-Backend/internal/consts"
 	"github.com/NoANameGroup/DAOld-Backend/internal/consts/enum"
 	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
@@ -14,23 +18,42 @@ import (
 	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
 	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
 	"github.com/NoANameGroup/DAOld-Backend/pkg/security"
+	"github.com/google/uuid"
 	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type IUserService interface {
 	Register(ctx context.Context, req *user.RegisterReq) (*user.RegisterResp, error)
 	Login(ctx context.Context, req *user.LoginReq) (*user.LoginResp, error)
+	LoginTwoFactor(ctx context.Context, req *user.LoginTwoFactorReq) (*user.LoginResp, error)
+	RefreshToken(ctx context.Context, req *user.RefreshTokenReq) (*user.RefreshTokenResp, error)
 	GetMyProfile(ctx context.Context) (*user.GetMyProfileResp, error)
 	ChangePassword(ctx context.Context, req *user.ChangePasswordReq) (*user.ChangePasswordResp, error)
 	DeleteAccount(ctx context.Context, req *user.DeleteAccountReq) (*user.DeleteAccountResp, error)
 	UpdateMyProfile(ctx context.Context, req *user.UpdateMyProfileReq) (*user.UpdateMyProfileResp, error)
-	Logout() (*user.LogoutResp, error)
+	Logout(ctx context.Context) (*user.LogoutResp, error)
+	LogoutAll(ctx context.Context) (*user.LogoutAllResp, error)
 	UpdateUserRole(ctx context.Context, req *user.UpdateUserRoleReq) (*user.UpdateUserRoleResp, error)
 }
 
 type UserService struct {
-	UserRepository *repository.UserRepository
+	Config                 *config.Config
+	UserRepository         *repository.UserRepository
+	RefreshTokenRepository *repository.RefreshTokenRepository
+	SessionRepository      *repository.SessionRepository
+	WalletRepository       *repository.WalletRepository
+	DelegationRepository   *repository.DelegationRepository
+	APIKeyRepository       *repository.APIKeyRepository
+	TokenManager           *jwt.Manager
+	Authorizer             *auth.Authorizer
+	VerificationService    *VerificationService
+	TwoFactorService       *TwoFactorService
+	InviteService          *InviteService
+	AuditService           *AuditService
+	LockoutService         *LockoutService
+	SessionService         *SessionService
 }
 
 var UserServiceSet = wire.NewSet(
@@ -42,13 +65,15 @@ func (s *UserService) Register(ctx context.Context, req *user.RegisterReq) (*use
 	var err error
 	var isExist bool
 	var hashPassword string
+	var invite *model.InviteCode
 
-	// 检查邮箱是否已被注册
-	if isExist, err = s.UserRepository.IsEmailExist(ctx, req.Email); err != nil {
+	// 检查邮箱是否已被注册, 邮箱统一转为小写保存
+	email := normalizeEmail(req.Email)
+	if isExist, err = s.UserRepository.IsEmailExist(ctx, email); err != nil {
 		log.CtxError(ctx, "failed to check existing email: %v", err)
 		return nil, err
 	} else if isExist {
-		log.CtxInfo(ctx, "email already registered: %s", req.Email)
+		log.CtxInfo(ctx, "email already registered: %s", email)
 		return nil, errorx.ErrEmailExisted
 	}
 
@@ -58,72 +83,325 @@ func (s *UserService) Register(ctx context.Context, req *user.RegisterReq) (*use
 		return nil, err
 	}
 
-	// 创建用户
+	// 校验并占用邀请码
+	if invite, err = s.InviteService.RedeemForRegistration(ctx, req.InviteCode); err != nil {
+		return nil, err
+	}
+
+	// 创建用户, 启用邮箱验证时验证邮箱前为未验证状态
+	verifyEmail := s.VerificationService.Enabled()
 	newUser := &model.User{
-		ID:        bson.NewObjectID(),
-		Email:     req.Email,
-		Username:  req.Username,
-		Password:  hashPassword,
-		Role:      enum.RoleUser,
-		Status:    enum.StatusActive,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:              bson.NewObjectID(),
+		Email:           email,
+		EmailUnverified: verifyEmail,
+		Username:        req.Username,
+		Password:        hashPassword,
+		Role:            enum.RoleUser,
+		Status:          enum.StatusActive,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
+	ApplyInvite(newUser, invite)
+
 	// 插入数据库
 	if err = s.UserRepository.Insert(ctx, newUser); err != nil {
 		log.CtxError(ctx, "failed to insert user: %v", err)
+		if invite != nil {
+			s.InviteService.Release(ctx, invite)
+		}
 		return nil, err
 	}
 
+	// 发送验证邮件, 失败时用户可以登录后重新发送
+	if verifyEmail {
+		if err = s.VerificationService.SendVerificationEmail(ctx, newUser); err != nil {
+			log.CtxError(ctx, "failed to send verification email: %v", err)
+		}
+	}
+
 	return &user.RegisterResp{Resp: dto.Success()}, nil
 }
 
 func (s *UserService) Login(ctx context.Context, req *user.LoginReq) (*user.LoginResp, error) {
 	var err error
-	var token string
 	var newUser *model.User
 
-	// 获取用户
-	if newUser, err = s.UserRepository.FindUserByEmail(ctx, req.Email); err != nil {
-		log.CtxError(ctx, "failed to find user: %v", err)
+	// 账号或 IP 处于锁定期时直接拒绝
+	email := normalizeEmail(req.Email)
+	clientIP, _ := ctx.Value(consts.ContextClientIP).(string)
+	if err = s.LockoutService.CheckLogin(ctx, email, clientIP); err != nil {
 		return nil, err
 	}
 
+	// 获取用户, 不存在时同样比较一次密码, 使响应与耗时和密码错误时一致
+	if newUser, err = s.UserRepository.FindUserByEmail(ctx, email); err != nil {
+		if !errors.Is(err, monc.ErrNotFound) {
+			log.CtxError(ctx, "failed to find user: %v", err)
+			return nil, err
+		}
+		security.CompareDummyPassword(req.Password)
+		log.CtxInfo(ctx, "username or password incorrect")
+		return nil, s.LockoutService.LoginFailed(ctx, email, clientIP, bson.NilObjectID)
+	}
+
 	// 校验密码是否正确
 	if !security.ComparePassword(newUser.Password, req.Password) {
 		log.CtxInfo(ctx, "username or password incorrect")
-		return nil, errorx.ErrUsernameOrPasswordIncorrect
+		return nil, s.LockoutService.LoginFailed(ctx, email, clientIP, newUser.ID)
+	}
+
+	// 开启了两步验证时, 二次验证通过后才清除失败计数
+	if !newUser.TOTPEnabled {
+		s.LockoutService.LoginSucceeded(ctx, email)
+	}
+
+	return s.continueLogin(ctx, newUser)
+}
+
+// continueLogin 第一因素(密码或钱包签名)通过后继续登录, 开启了两步验证时只签发二次验证令牌
+func (s *UserService) continueLogin(ctx context.Context, userModel *model.User) (*user.LoginResp, error) {
+	var err error
+	var mfaToken string
+
+	// 校验账号状态
+	if err = checkUserStatus(ctx, s.UserRepository, userModel); err != nil {
+		return nil, err
+	}
+
+	// 已开启两步验证时只签发二次验证令牌
+	if userModel.TOTPEnabled {
+		if mfaToken, err = s.TwoFactorService.IssuePendingToken(ctx, userModel.ID); err != nil {
+			return nil, err
+		}
+		return &user.LoginResp{
+			Resp:        dto.Success(),
+			UserID:      userModel.ID,
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
 	}
 
+	return s.completeLogin(ctx, userModel)
+}
+
+// LoginTwoFactor 提交两步验证码或恢复码, 完成登录
+func (s *UserService) LoginTwoFactor(ctx context.Context, req *user.LoginTwoFactorReq) (*user.LoginResp, error) {
+	var err error
+	var userModel *model.User
+
+	// 校验二次验证令牌与验证码
+	if userModel, err = s.TwoFactorService.VerifyPendingLogin(ctx, req); err != nil {
+		return nil, err
+	}
+	if userModel.Email != "" {
+		s.LockoutService.LoginSucceeded(ctx, userModel.Email)
+	}
+
+	// 校验账号状态, 两步之间账号可能已被封禁
+	if err = checkUserStatus(ctx, s.UserRepository, userModel); err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, userModel)
+}
+
+// completeLogin 认证通过后更新最后登录时间, 签发令牌并记录会话
+func (s *UserService) completeLogin(ctx context.Context, userModel *model.User) (*user.LoginResp, error) {
+	var err error
+	var tokens *tokenPair
+
 	// 更新最后登录时间
-	if err = s.UserRepository.UpdateLastLoginAt(ctx, newUser.ID, time.Now()); err != nil {
+	if err = s.UserRepository.UpdateLastLoginAt(ctx, userModel.ID, time.Now()); err != nil {
 		log.CtxError(ctx, "failed to update last login at: %v", err)
 		return nil, err
 	}
 
-	// 生成 token
-	token, err = jwt.GenerateToken(newUser.ID)
-	if err != nil {
-		log.CtxError(ctx, "failed to generate token: %v", err)
+	// 生成 token, 每次登录开启一个新的刷新令牌家族
+	if tokens, err = s.issueTokens(ctx, userModel.ID, uuid.NewString()); err != nil {
+		return nil, err
+	}
+
+	// 记录会话
+	if err = s.SessionService.createSession(ctx, userModel, tokens); err != nil {
+		log.CtxError(ctx, "failed to create session: %v", err)
 		return nil, err
 	}
 
 	return &user.LoginResp{
-		Resp:        dto.Success(),
-		AccessToken: token,
-		UserID:      newUser.ID,
+		Resp:                   dto.Success(),
+		AccessToken:            tokens.AccessToken,
+		RefreshToken:           tokens.RefreshToken,
+		ExpiresIn:              int64(s.TokenManager.AccessExpire().Seconds()),
+		UserID:                 userModel.ID,
+		TwoFactorSetupRequired: !userModel.TOTPEnabled && s.Authorizer.RequiresTwoFactor(userModel.Role),
 	}, nil
 }
 
+func (s *UserService) RefreshToken(ctx context.Context, req *user.RefreshTokenReq) (*user.RefreshTokenResp, error) {
+	var err error
+	var ok bool
+	var oldToken *model.RefreshToken
+	var userModel *model.User
+	var tokens *tokenPair
+
+	// 查找刷新令牌
+	if req.RefreshToken == "" {
+		return nil, errorx.ErrRefreshTokenInvalid
+	}
+	if oldToken, err = s.RefreshTokenRepository.FindByTokenHash(ctx, security.HashToken(req.RefreshToken)); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrRefreshTokenInvalid
+		}
+		log.CtxError(ctx, "failed to find refresh token: %v", err)
+		return nil, err
+	}
+
+	// 校验令牌状态
+	if oldToken.Revoked || time.Now().After(oldToken.ExpiresAt) {
+		log.CtxInfo(ctx, "refresh token revoked or expired, family=%s", oldToken.FamilyID)
+		return nil, errorx.ErrRefreshTokenInvalid
+	}
+
+	// 标记为已使用, 已使用的令牌再次出现说明被盗用, 吊销整个家族及其访问令牌
+	if !oldToken.Used {
+		if ok, err = s.RefreshTokenRepository.MarkUsed(ctx, oldToken.ID, time.Now()); err != nil {
+			log.CtxError(ctx, "failed to mark refresh token used: %v", err)
+			return nil, err
+		}
+	}
+	if oldToken.Used || !ok {
+		log.CtxError(ctx, "refresh token reuse detected, user=%s, family=%s", oldToken.UserID.Hex(), oldToken.FamilyID)
+		if err = s.SessionService.revokeFamily(ctx, oldToken.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, errorx.ErrRefreshTokenReused
+	}
+
+	// 校验账号状态
+	if userModel, err = s.UserRepository.FindUserByUserID(ctx, oldToken.UserID); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrRefreshTokenInvalid
+		}
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+	if err = checkUserStatus(ctx, s.UserRepository, userModel); err != nil {
+		return nil, err
+	}
+
+	// 在同一家族下签发新的令牌
+	if tokens, err = s.issueTokens(ctx, oldToken.UserID, oldToken.FamilyID); err != nil {
+		return nil, err
+	}
+
+	// 更新会话
+	if err = s.SessionService.refreshSession(ctx, tokens); err != nil {
+		log.CtxError(ctx, "failed to refresh session: %v", err)
+		return nil, err
+	}
+
+	return &user.RefreshTokenResp{
+		Resp:         dto.Success(),
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int64(s.TokenManager.AccessExpire().Seconds()),
+		UserID:       oldToken.UserID,
+	}, nil
+}
+
+// normalizeEmail 去除首尾空白并转为小写, 注册、登录与找回密码使用同一规则
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// revokeTokenFamily 吊销一次登录派生出的全部刷新令牌与访问令牌
+// 刷新令牌被重放时同样调用, 被盗用者已拿到的访问令牌也随之失效
+func revokeTokenFamily(ctx context.Context, tokenManager *jwt.Manager, refreshTokenRepository *repository.RefreshTokenRepository, familyId string) error {
+	if err := refreshTokenRepository.RevokeFamily(ctx, familyId); err != nil {
+		log.CtxError(ctx, "failed to revoke refresh token family: %v", err)
+		return err
+	}
+	if err := tokenManager.RevokeFamily(ctx, familyId); err != nil {
+		log.CtxError(ctx, "failed to revoke token family: %v", err)
+		return err
+	}
+	return nil
+}
+
+// revokeSessions 吊销用户的全部访问令牌、刷新令牌与会话
+func revokeSessions(ctx context.Context, tokenManager *jwt.Manager, refreshTokenRepository *repository.RefreshTokenRepository,
+	sessionRepository *repository.SessionRepository, userId bson.ObjectID) error {
+	if err := tokenManager.RevokeAll(ctx, userId); err != nil {
+		log.CtxError(ctx, "failed to revoke all tokens: %v", err)
+		return err
+	}
+	if err := refreshTokenRepository.RevokeByUserID(ctx, userId); err != nil {
+		log.CtxError(ctx, "failed to revoke refresh tokens: %v", err)
+		return err
+	}
+	if err := sessionRepository.RevokeByUserID(ctx, userId, time.Now()); err != nil {
+		log.CtxError(ctx, "failed to revoke sessions: %v", err)
+		return err
+	}
+	return nil
+}
+
+// tokenPair 一次签发的访问令牌与刷新令牌
+type tokenPair struct {
+	AccessToken      string
+	RefreshToken     string
+	TokenID          string // 访问令牌 ID
+	FamilyID         string
+	RefreshExpiresAt time.Time
+}
+
+// issueTokens 签发访问令牌, 并在指定家族下生成新的刷新令牌
+func (s *UserService) issueTokens(ctx context.Context, userId bson.ObjectID, familyId string) (*tokenPair, error) {
+	var err error
+	tokens := &tokenPair{FamilyID: familyId}
+
+	// 生成访问令牌
+	if tokens.AccessToken, tokens.TokenID, err = s.TokenManager.GenerateToken(userId, familyId); err != nil {
+		log.CtxError(ctx, "failed to generate token: %v", err)
+		return nil, err
+	}
+
+	// 生成刷新令牌, 数据库中只保存哈希
+	if tokens.RefreshToken, err = security.GenerateRandomToken(32); err != nil {
+		log.CtxError(ctx, "failed to generate refresh token: %v", err)
+		return nil, err
+	}
+
+	refreshExpire := s.Config.Auth.RefreshExpire
+	if refreshExpire <= 0 {
+		refreshExpire = consts.DefaultRefreshExpire
+	}
+	now := time.Now()
+	tokens.RefreshExpiresAt = now.Add(time.Duration(refreshExpire) * time.Second)
+	if err = s.RefreshTokenRepository.Insert(ctx, &model.RefreshToken{
+		ID:        bson.NewObjectID(),
+		UserID:    userId,
+		FamilyID:  familyId,
+		TokenHash: security.HashToken(tokens.RefreshToken),
+		ExpiresAt: tokens.RefreshExpiresAt,
+		CreatedAt: now,
+	}); err != nil {
+		log.CtxError(ctx, "failed to insert refresh token: %v", err)
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
 func (s *UserService) GetMyProfile(ctx context.Context) (*user.GetMyProfileResp, error) {
 	var err error
 	var userModel *model.User
 
-	// 获取用户ID并转换类型
-	userId, ok := ctx.Value(consts.ContextUserID).(bson.ObjectID)
-	if !ok {
-		return nil, errorx.ErrContextUserIDInvalid
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// 获取用户信息
@@ -134,35 +412,41 @@ func (s *UserService) GetMyProfile(ctx context.Context) (*user.GetMyProfileResp,
 	}
 
 	return &user.GetMyProfileResp{
-		Resp: dto.Success(),
-		UserVO: &user.UserVO{
-			Email:       userModel.Email,
-			Username:    userModel.Username,
-			Avatar:      userModel.Avatar,
-			FirstName:   userModel.FirstName,
-			LastName:    userModel.LastName,
-			Gender:      enum.GetUserGenderDesc(userModel.Gender),
-			Role:        enum.GetUserRoleDesc(userModel.Role),
-			Status:      enum.GetUserStatusDesc(userModel.Status),
-			Phone:       userModel.Phone,
-			Address:     userModel.Address,
-			Bio:         userModel.Bio,
-			Birthday:    userModel.Birthday.Format("2006-01-02"),
-			LastLoginAt: userModel.LastLoginAt,
-			CreatedAt:   userModel.CreatedAt,
-		},
+		Resp:   dto.Success(),
+		UserVO: toUserVO(userModel),
 	}, nil
 }
 
+// toUserVO 将用户模型转换为对外展示的 UserVO
+func toUserVO(userModel *model.User) *user.UserVO {
+	return &user.UserVO{
+		Email:       userModel.Email,
+		Username:    userModel.Username,
+		Avatar:      userModel.Avatar,
+		FirstName:   userModel.FirstName,
+		LastName:    userModel.LastName,
+		Gender:      enum.GetUserGenderDesc(userModel.Gender),
+		Role:        enum.GetUserRoleDesc(userModel.Role),
+		Status:      enum.GetUserStatusDesc(userModel.Status),
+		TwoFactor:   userModel.TOTPEnabled,
+		Phone:       userModel.Phone,
+		Address:     userModel.Address,
+		Bio:         userModel.Bio,
+		Birthday:    userModel.Birthday.Format("2006-01-02"),
+		LastLoginAt: userModel.LastLoginAt,
+		CreatedAt:   userModel.CreatedAt,
+	}
+}
+
 func (s *UserService) ChangePassword(ctx context.Context, req *user.ChangePasswordReq) (*user.ChangePasswordResp, error) {
 	var err error
 	var userModel *model.User
 	var hashPassword string
 
-	// 获取用户ID并转换类型
-	userId, ok := ctx.Value(consts.ContextUserID).(bson.ObjectID)
-	if !ok {
-		return nil, errorx.ErrContextUserIDInvalid
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// 获取用户
@@ -172,10 +456,9 @@ func (s *UserService) ChangePassword(ctx context.Context, req *user.ChangePasswo
 		return nil, err
 	}
 
-	// 校验旧密码是否正确
-	if !security.ComparePassword(userModel.Password, req.OldPassword) {
-		log.CtxInfo(ctx, "wrong password")
-		return nil, errorx.ErrPasswordIncorrect
+	// 校验旧密码, 未设置密码的账号校验两步验证码或钱包签名
+	if err = s.TwoFactorService.confirmIdentity(ctx, userModel, req.OldPassword, req.ReauthProof); err != nil {
+		return nil, err
 	}
 
 	// 检查新旧密码是否相同
@@ -208,6 +491,14 @@ func (s *UserService) ChangePassword(ctx context.Context, req *user.ChangePasswo
 		return nil, err
 	}
 
+	// 记录审计事件, 密码只记录已变更
+	s.AuditService.Record(ctx, &AuditEntry{
+		Action:     AuditActionUserPasswordChange,
+		TargetType: AuditTargetUser,
+		TargetID:   userId.Hex(),
+		Changes:    []model.AuditChange{{Field: consts.Password, Before: auditRedacted, After: auditRedacted}},
+	})
+
 	return &user.ChangePasswordResp{
 		Resp: dto.Success(),
 	}, nil
@@ -217,10 +508,10 @@ func (s *UserService) DeleteAccount(ctx context.Context, req *user.DeleteAccount
 	var err error
 	var userModel *model.User
 
-	// 获取用户ID并转换类型
-	userId, ok := ctx.Value(consts.ContextUserID).(bson.ObjectID)
-	if !ok {
-		return nil, errorx.ErrContextUserIDInvalid
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// 获取用户
@@ -230,10 +521,9 @@ func (s *UserService) DeleteAccount(ctx context.Context, req *user.DeleteAccount
 		return nil, err
 	}
 
-	// 校验旧密码是否正确
-	if !security.ComparePassword(userModel.Password, req.Password) {
-		log.CtxInfo(ctx, "wrong password")
-		return nil, errorx.ErrPasswordIncorrect
+	// 校验密码, 未设置密码的账号校验两步验证码或钱包签名
+	if err = s.TwoFactorService.confirmIdentity(ctx, userModel, req.Password, req.ReauthProof); err != nil {
+		return nil, err
 	}
 
 	// 检查确认密码是否匹配
@@ -242,22 +532,52 @@ func (s *UserService) DeleteAccount(ctx context.Context, req *user.DeleteAccount
 		return nil, errorx.ErrConfirmationNotMatch
 	}
 
+	// 删除绑定的钱包, 关联数据均先于用户删除, 失败后可以重试
+	if err = s.WalletRepository.DeleteByUserID(ctx, userId); err != nil {
+		return nil, err
+	}
+	// 删除委托关系
+	if err = s.DelegationRepository.DeleteByUserID(ctx, userId); err != nil {
+		return nil, err
+	}
+	// 吊销全部令牌与会话
+	if err = revokeSessions(ctx, s.TokenManager, s.RefreshTokenRepository, s.SessionRepository, userId); err != nil {
+		return nil, err
+	}
+	// 吊销全部 API Key
+	if err = s.APIKeyRepository.RevokeByUserID(ctx, userId, time.Now()); err != nil {
+		return nil, err
+	}
+
 	// 删除用户
 	if err = s.UserRepository.DeleteUser(ctx, userId); err != nil {
 		log.CtxError(ctx, "failed to delete user: %v", err)
 		return nil, err
 	}
 
+	// 记录审计事件, 保留被删除账号的标识信息
+	s.AuditService.Record(ctx, &AuditEntry{
+		Action:     AuditActionUserDelete,
+		TargetType: AuditTargetUser,
+		TargetID:   userId.Hex(),
+		Changes: []model.AuditChange{
+			{Field: consts.Username, Before: userModel.Username},
+			{Field: consts.Email, Before: userModel.Email},
+			{Field: consts.Role, Before: enum.GetUserRoleDesc(userModel.Role)},
+			{Field: consts.Status, Before: enum.GetUserStatusDesc(userModel.Status)},
+		},
+	})
+
 	return &user.DeleteAccountResp{
 		Resp: dto.Success(),
 	}, nil
 }
 
 func (s *UserService) UpdateMyProfile(ctx context.Context, req *user.UpdateMyProfileReq) (*user.UpdateMyProfileResp, error) {
-	// 获取用户ID并转换类型
-	userId, ok := ctx.Value(consts.ContextUserID).(bson.ObjectID)
-	if !ok {
-		return nil, errorx.ErrContextUserIDInvalid
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	update := bson.M{}
@@ -312,36 +632,95 @@ func (s *UserService) UpdateMyProfile(ctx context.Context, req *user.UpdateMyPro
 	}, nil
 }
 
-func (s *UserService) Logout() (*user.LogoutResp, error) {
+func (s *UserService) Logout(ctx context.Context) (*user.LogoutResp, error) {
+	// 获取当前令牌
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 吊销当前令牌
+	if err = s.TokenManager.Revoke(ctx, principal.TokenID, principal.ExpiresAt); err != nil {
+		log.CtxError(ctx, "failed to revoke token: %v", err)
+		return nil, err
+	}
+
+	// 吊销本次登录的会话与刷新令牌
+	if principal.FamilyID != "" {
+		if err = s.SessionService.revokeFamily(ctx, principal.FamilyID); err != nil {
+			return nil, err
+		}
+	}
+
 	return &user.LogoutResp{
 		Resp: dto.Success(),
 	}, nil
 }
 
-func (s *UserService) UpdateUserRole(ctx context.Context, req *user.UpdateUserRoleReq) (*user.UpdateUserRoleResp, error) {
-	// 获取当前用户ID并转换类型
-	userId, ok := ctx.Value(consts.ContextUserID).(bson.ObjectID)
-	if !ok {
-		return nil, errorx.ErrContextUserIDInvalid
+func (s *UserService) LogoutAll(ctx context.Context) (*user.LogoutAllResp, error) {
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// 检查是否有权限
-	if isAdmin, err := s.UserRepository.IsAdmin(ctx, userId); !isAdmin {
-		log.CtxError(ctx, "user is not admin")
-		return nil, errorx.ErrUserPermissionsInsufficient
-	} else if err != nil {
-		log.CtxError(ctx, "failed to check user role: %v", err)
+	// 吊销该用户的全部令牌
+	if err = revokeSessions(ctx, s.TokenManager, s.RefreshTokenRepository, s.SessionRepository, userId); err != nil {
 		return nil, err
 	}
 
-	// 从路径参数获取用户ID
+	return &user.LogoutAllResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// UpdateUserRole 修改其他用户的角色, 目标用户的角色必须严格低于自己, 授予的角色不能高于自己
+func (s *UserService) UpdateUserRole(ctx context.Context, req *user.UpdateUserRoleReq) (*user.UpdateUserRoleResp, error) {
+	// 获取当前用户, 权限已由路由上的 RequirePermission 校验
+	principal, err := auth.GetPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userId := principal.UserID
+
+	// 从路径参数获取用户ID, 不能修改自己的角色
 	targetId, ok := ctx.Value(consts.ContextTargetID).(bson.ObjectID)
 	if !ok {
 		return nil, errorx.ErrContextUserIDInvalid
 	}
+	if targetId == userId {
+		return nil, errorx.ErrCannotModifySelf
+	}
+
+	// 校验角色是否存在, 只能授予不高于自己的角色
+	role := enum.GetUserRoleCode(req.Role)
+	if !s.Authorizer.IsRoleDefined(role) {
+		log.CtxInfo(ctx, "role not defined: %s", req.Role)
+		return nil, errorx.ErrUserRoleInvalid
+	}
+	if !s.Authorizer.Covers(principal.Role, role) {
+		log.CtxInfo(ctx, "user %s cannot grant role %s above own role", userId.Hex(), req.Role)
+		return nil, errorx.ErrUserPermissionsInsufficient
+	}
+
+	// 校验目标用户是否存在
+	target, err := s.UserRepository.FindUserByUserID(ctx, targetId)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrUserNotFound
+		}
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+	// 只能修改角色严格低于自己的用户
+	if !s.Authorizer.Outranks(principal.Role, target.Role) {
+		log.CtxInfo(ctx, "user %s (%s) cannot update role of user %s (%s)", userId.Hex(), enum.GetUserRoleDesc(principal.Role), targetId.Hex(), enum.GetUserRoleDesc(target.Role))
+		return nil, errorx.ErrTargetRoleNotLower
+	}
 
 	// 更新数据库
-	if err := s.UserRepository.UpdateUserRole(ctx, targetId, enum.GetUserRoleCode(req.Role)); err != nil {
+	log.CtxInfo(ctx, "user %s updates role of user %s to %s", userId.Hex(), targetId.Hex(), req.Role)
+	if err := s.UserRepository.UpdateUserRole(ctx, targetId, role); err != nil {
 		log.CtxError(ctx, "failed to update user role: %v", err)
 		return nil, err
 	}
@@ -352,6 +731,14 @@ func (s *UserService) UpdateUserRole(ctx context.Context, req *user.UpdateUserRo
 		return nil, err
 	}
 
+	// 记录审计事件
+	s.AuditService.Record(ctx, &AuditEntry{
+		Action:     AuditActionUserRoleUpdate,
+		TargetType: AuditTargetUser,
+		TargetID:   targetId.Hex(),
+		Changes:    []model.AuditChange{{Field: consts.Role, Before: enum.GetUserRoleDesc(target.Role), After: enum.GetUserRoleDesc(role)}},
+	})
+
 	return &user.UpdateUserRoleResp{
 		Resp: dto.Success(),
 	}, nil
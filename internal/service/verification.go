@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/mail"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/security"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type IVerificationService interface {
+	SendVerificationEmail(ctx context.Context, userModel *model.User) error
+	VerifyEmail(ctx context.Context, req *user.VerifyEmailReq) (*user.VerifyEmailResp, error)
+	ResendVerificationEmail(ctx context.Context) (*user.ResendVerificationEmailResp, error)
+}
+
+type VerificationService struct {
+	Config                      *config.Config
+	UserRepository              *repository.UserRepository
+	EmailVerificationRepository *repository.EmailVerificationRepository
+	MailSender                  mail.Sender
+}
+
+var VerificationServiceSet = wire.NewSet(
+	wire.Struct(new(VerificationService), "*"),
+	wire.Bind(new(IVerificationService), new(*VerificationService)),
+)
+
+// emailVerifyPayload 邮箱验证令牌的载荷, 令牌经 HMAC 签名, Nonce 的哈希保存在数据库中用于保证只能使用一次
+type emailVerifyPayload struct {
+	UserID    string `json:"uid"`
+	Nonce     string `json:"n"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Enabled 判断是否启用了邮箱验证
+func (s *VerificationService) Enabled() bool {
+	return s.Config.EmailVerify.Secret != ""
+}
+
+// SendVerificationEmail 生成验证令牌并发送验证邮件
+func (s *VerificationService) SendVerificationEmail(ctx context.Context, userModel *model.User) error {
+	var err error
+	var nonce string
+
+	// 生成随机数
+	if nonce, err = security.GenerateRandomToken(16); err != nil {
+		log.CtxError(ctx, "failed to generate nonce: %v", err)
+		return err
+	}
+
+	// 保存发放记录
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(s.Config.EmailVerify.Expire) * time.Second)
+	if err = s.EmailVerificationRepository.Insert(ctx, &model.EmailVerification{
+		ID:        bson.NewObjectID(),
+		UserID:    userModel.ID,
+		Email:     userModel.Email,
+		NonceHash: security.HashToken(nonce),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}); err != nil {
+		log.CtxError(ctx, "failed to insert email verification: %v", err)
+		return err
+	}
+
+	// 签名令牌
+	payload, _ := json.Marshal(&emailVerifyPayload{UserID: userModel.ID.Hex(), Nonce: nonce, ExpiresAt: expiresAt.Unix()})
+	token := security.SignToken([]byte(s.Config.EmailVerify.Secret), payload)
+
+	// 发送邮件
+	link := token
+	if s.Config.EmailVerify.URL != "" {
+		link = s.Config.EmailVerify.URL + "?token=" + url.QueryEscape(token)
+	}
+	return s.MailSender.Send(ctx, &mail.Message{
+		To:      userModel.Email,
+		Subject: "请验证你的 DAOld 邮箱",
+		Body: fmt.Sprintf("%s 你好,\n\n请在 %s 前打开以下链接完成邮箱验证:\n\n%s\n\n如果这不是你本人的操作, 请忽略此邮件。\n",
+			userModel.Username, expiresAt.Format(time.DateTime), link),
+	})
+}
+
+func (s *VerificationService) VerifyEmail(ctx context.Context, req *user.VerifyEmailReq) (*user.VerifyEmailResp, error) {
+	var err error
+	var ok bool
+	var payload emailVerifyPayload
+	var verification *model.EmailVerification
+
+	if !s.Enabled() {
+		return nil, errorx.ErrEmailVerifyDisabled
+	}
+
+	// 校验签名与有效期
+	data, err := security.VerifySignedToken([]byte(s.Config.EmailVerify.Secret), req.Token)
+	if err != nil || json.Unmarshal(data, &payload) != nil {
+		log.CtxInfo(ctx, "email verify token signature invalid")
+		return nil, errorx.ErrEmailVerifyTokenInvalid
+	}
+	if time.Now().Unix() > payload.ExpiresAt {
+		return nil, errorx.ErrEmailVerifyTokenInvalid
+	}
+
+	// 查找发放记录
+	if verification, err = s.EmailVerificationRepository.FindByNonceHash(ctx, security.HashToken(payload.Nonce)); err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrEmailVerifyTokenInvalid
+		}
+		log.CtxError(ctx, "failed to find email verification: %v", err)
+		return nil, err
+	}
+	if verification.UserID.Hex() != payload.UserID || verification.Used {
+		log.CtxInfo(ctx, "email verify token reused or mismatched, user=%s", payload.UserID)
+		return nil, errorx.ErrEmailVerifyTokenInvalid
+	}
+
+	// 标记令牌已使用
+	if ok, err = s.EmailVerificationRepository.MarkUsed(ctx, verification.ID, time.Now()); err != nil {
+		log.CtxError(ctx, "failed to mark email verification used: %v", err)
+		return nil, err
+	} else if !ok {
+		return nil, errorx.ErrEmailVerifyTokenInvalid
+	}
+
+	// 标记邮箱已验证, 邮箱在发送后被修改时令牌失效
+	if ok, err = s.UserRepository.MarkEmailVerified(ctx, verification.UserID, verification.Email); err != nil {
+		log.CtxError(ctx, "failed to mark email verified: %v", err)
+		return nil, err
+	} else if !ok {
+		return nil, errorx.ErrEmailVerifyTokenInvalid
+	}
+
+	return &user.VerifyEmailResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+func (s *VerificationService) ResendVerificationEmail(ctx context.Context) (*user.ResendVerificationEmailResp, error) {
+	var err error
+	var count int64
+	var userModel *model.User
+	var latest *model.EmailVerification
+
+	if !s.Enabled() {
+		return nil, errorx.ErrEmailVerifyDisabled
+	}
+
+	// 获取当前用户
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userModel, err = s.UserRepository.FindUserByUserID(ctx, userId); err != nil {
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+	if !userModel.EmailUnverified {
+		return nil, errorx.ErrEmailAlreadyVerified
+	}
+
+	// 限制发送频率
+	now := time.Now()
+	if latest, err = s.EmailVerificationRepository.FindLatestByUserID(ctx, userId); err == nil {
+		if now.Sub(latest.CreatedAt) < time.Duration(s.Config.EmailVerify.ResendInterval)*time.Second {
+			return nil, errorx.ErrEmailSendTooFrequent
+		}
+	} else if !errors.Is(err, monc.ErrNotFound) {
+		log.CtxError(ctx, "failed to find latest email verification: %v", err)
+		return nil, err
+	}
+	if count, err = s.EmailVerificationRepository.CountByUserIDSince(ctx, userId, now.Add(-24*time.Hour)); err != nil {
+		return nil, err
+	}
+	if count >= s.Config.EmailVerify.ResendPerDay {
+		return nil, errorx.ErrEmailSendTooFrequent
+	}
+
+	// 发送邮件
+	if err = s.SendVerificationEmail(ctx, userModel); err != nil {
+		log.CtxError(ctx, "failed to send verification email: %v", err)
+		return nil, err
+	}
+
+	return &user.ResendVerificationEmailResp{
+		Resp: dto.Success(),
+	}, nil
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/internal/auth"
+	"github.com/NoANameGroup/DAOld-Backend/internal/config"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto"
+	"github.com/NoANameGroup/DAOld-Backend/internal/dto/user"
+	"github.com/NoANameGroup/DAOld-Backend/internal/errorx"
+	"github.com/NoANameGroup/DAOld-Backend/internal/kv"
+	"github.com/NoANameGroup/DAOld-Backend/internal/model"
+	"github.com/NoANameGroup/DAOld-Backend/internal/repository"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/eth"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/log"
+	"github.com/NoANameGroup/DAOld-Backend/pkg/siwe"
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	walletChallengeKeyPrefix = "wallet_challenge:"
+	walletChallengeExpire    = 5 * time.Minute
+	reauthChallengeKeyPrefix = "reauth_challenge:"
+)
+
+type IWalletService interface {
+	Challenge(ctx context.Context, req *user.WalletChallengeReq) (*user.WalletChallengeResp, error)
+	AddWallet(ctx context.Context, req *user.AddWalletReq) (*user.AddWalletResp, error)
+	ListWallets(ctx context.Context) (*user.ListWalletsResp, error)
+	SetPrimaryWallet(ctx context.Context, req *user.SetPrimaryWalletReq) (*user.SetPrimaryWalletResp, error)
+	RemoveWallet(ctx context.Context, req *user.RemoveWalletReq) (*user.RemoveWalletResp, error)
+	ReauthChallenge(ctx context.Context) (*user.ReauthChallengeResp, error)
+}
+
+type WalletService struct {
+	Config           *config.Config
+	Store            kv.Store
+	UserRepository   *repository.UserRepository
+	WalletRepository *repository.WalletRepository
+}
+
+var WalletServiceSet = wire.NewSet(
+	wire.Struct(new(WalletService), "*"),
+	wire.Bind(new(IWalletService), new(*WalletService)),
+)
+
+// Challenge 生成绑定钱包的签名消息, 用户用该钱包签名后提交以证明持有该地址
+func (s *WalletService) Challenge(ctx context.Context, req *user.WalletChallengeReq) (*user.WalletChallengeResp, error) {
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 规范化地址
+	address, err := eth.ChecksumAddress(req.Address)
+	if err != nil {
+		return nil, errorx.ErrWalletAddressInvalid
+	}
+
+	// 生成签名消息, 消息中包含账号与 nonce, 签名无法用于绑定到其他账号
+	nonce, err := siwe.GenerateNonce()
+	if err != nil {
+		log.CtxError(ctx, "failed to generate wallet challenge nonce: %v", err)
+		return nil, err
+	}
+	message := fmt.Sprintf("DAOld 请求将钱包绑定到你的账号。\n\n钱包: %s\n账号: %s\nNonce: %s\n签发时间: %s",
+		address, userId.Hex(), nonce, time.Now().UTC().Format(time.RFC3339))
+	if err = s.Store.Set(ctx, walletChallengeKey(userId, address), message, walletChallengeExpire); err != nil {
+		log.CtxError(ctx, "failed to store wallet challenge: %v", err)
+		return nil, err
+	}
+
+	return &user.WalletChallengeResp{
+		Resp:      dto.Success(),
+		Message:   message,
+		ExpiresIn: int64(walletChallengeExpire.Seconds()),
+	}, nil
+}
+
+// AddWallet 校验签名并绑定钱包, 用户的第一个钱包自动成为主钱包
+func (s *WalletService) AddWallet(ctx context.Context, req *user.AddWalletReq) (*user.AddWalletResp, error) {
+	var err error
+	var count int64
+
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 规范化地址
+	address, err := eth.ChecksumAddress(req.Address)
+	if err != nil {
+		return nil, errorx.ErrWalletAddressInvalid
+	}
+
+	// 获取签名消息, 每条消息只能使用一次
+	key := walletChallengeKey(userId, address)
+	message, ok, err := s.Store.Get(ctx, key)
+	if err != nil {
+		log.CtxError(ctx, "failed to get wallet challenge: %v", err)
+		return nil, err
+	}
+	if !ok {
+		return nil, errorx.ErrWalletChallengeInvalid
+	}
+	if err = s.Store.Del(ctx, key); err != nil {
+		log.CtxError(ctx, "failed to delete wallet challenge: %v", err)
+		return nil, err
+	}
+
+	// 校验签名
+	sig, err := eth.ParseSignature(req.Signature)
+	if err != nil {
+		return nil, errorx.ErrSIWESignatureInvalid
+	}
+	signer, err := eth.RecoverAddress([]byte(message), sig)
+	if err != nil || signer != address {
+		log.CtxInfo(ctx, "wallet signature rejected for %s", address)
+		return nil, errorx.ErrSIWESignatureInvalid
+	}
+
+	// 绑定钱包
+	if count, err = s.WalletRepository.CountByUserID(ctx, userId); err != nil {
+		return nil, err
+	}
+	wallet := &model.Wallet{
+		ID:        bson.NewObjectID(),
+		UserID:    userId,
+		Address:   address,
+		Primary:   count == 0,
+		CreatedAt: time.Now(),
+	}
+	if err = s.WalletRepository.Insert(ctx, wallet); err != nil {
+		if errors.Is(err, repository.ErrWalletAddressExisted) {
+			return nil, errorx.ErrWalletAlreadyLinked
+		}
+		return nil, err
+	}
+
+	log.CtxInfo(ctx, "wallet %s linked to user %s", address, userId.Hex())
+	return &user.AddWalletResp{
+		Resp:     dto.Success(),
+		WalletVO: toWalletVO(wallet),
+	}, nil
+}
+
+func (s *WalletService) ListWallets(ctx context.Context) (*user.ListWalletsResp, error) {
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取钱包列表
+	wallets, err := s.WalletRepository.FindByUserID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	vos := make([]*user.WalletVO, 0, len(wallets))
+	for _, wallet := range wallets {
+		vos = append(vos, toWalletVO(wallet))
+	}
+	return &user.ListWalletsResp{
+		Resp:    dto.Success(),
+		Wallets: vos,
+	}, nil
+}
+
+func (s *WalletService) SetPrimaryWallet(ctx context.Context, req *user.SetPrimaryWalletReq) (*user.SetPrimaryWalletResp, error) {
+	// 获取当前用户ID与钱包
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := s.findOwnWallet(ctx, userId, req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设为主钱包
+	if err = s.WalletRepository.SetPrimary(ctx, userId, wallet.Address); err != nil {
+		return nil, err
+	}
+
+	return &user.SetPrimaryWalletResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// RemoveWallet 解绑钱包, 移除主钱包时由最早绑定的钱包接替
+func (s *WalletService) RemoveWallet(ctx context.Context, req *user.RemoveWalletReq) (*user.RemoveWalletResp, error) {
+	var err error
+	var userModel *model.User
+	var wallets []*model.Wallet
+
+	// 获取当前用户与钱包
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := s.findOwnWallet(ctx, userId, req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// 钱包注册的账号没有密码, 不能移除最后一个钱包
+	if userModel, err = s.UserRepository.FindUserByUserID(ctx, userId); err != nil {
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+	if wallets, err = s.WalletRepository.FindByUserID(ctx, userId); err != nil {
+		return nil, err
+	}
+	if userModel.Password == "" && len(wallets) <= 1 {
+		return nil, errorx.ErrWalletLastLoginMethod
+	}
+
+	// 解绑钱包
+	if _, err = s.WalletRepository.Delete(ctx, userId, wallet.Address); err != nil {
+		return nil, err
+	}
+	if wallet.Primary {
+		for _, w := range wallets {
+			if w.Address != wallet.Address {
+				if err = s.WalletRepository.SetPrimary(ctx, userId, w.Address); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+	}
+
+	log.CtxInfo(ctx, "wallet %s unlinked from user %s", wallet.Address, userId.Hex())
+	return &user.RemoveWalletResp{
+		Resp: dto.Success(),
+	}, nil
+}
+
+// FindUserByAddress 根据钱包地址查找绑定的用户, 地址可以是任意大小写形式
+func (s *WalletService) FindUserByAddress(ctx context.Context, address string) (*model.User, error) {
+	userId, err := s.FindUserIDByAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	userModel, err := s.UserRepository.FindUserByUserID(ctx, userId)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrUserNotFound
+		}
+		log.CtxError(ctx, "failed to find user: %v", err)
+		return nil, err
+	}
+	return userModel, nil
+}
+
+// FindUserIDByAddress 根据钱包地址查找绑定的用户ID
+func (s *WalletService) FindUserIDByAddress(ctx context.Context, address string) (bson.ObjectID, error) {
+	address, err := eth.ChecksumAddress(address)
+	if err != nil {
+		return bson.NilObjectID, errorx.ErrWalletAddressInvalid
+	}
+	wallet, err := s.WalletRepository.FindByAddress(ctx, address)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return bson.NilObjectID, errorx.ErrWalletNotFound
+		}
+		log.CtxError(ctx, "failed to find wallet: %v", err)
+		return bson.NilObjectID, err
+	}
+	return wallet.UserID, nil
+}
+
+// ReauthChallenge 生成确认敏感操作的签名消息, 未设置密码的账号用已绑定的钱包签名代替密码
+// 消息与绑定钱包的消息不同, 签名不能互相替代
+func (s *WalletService) ReauthChallenge(ctx context.Context) (*user.ReauthChallengeResp, error) {
+	// 获取当前用户ID
+	userId, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 生成签名消息, 同一账号只保留最近的一条
+	nonce, err := siwe.GenerateNonce()
+	if err != nil {
+		log.CtxError(ctx, "failed to generate reauth challenge nonce: %v", err)
+		return nil, err
+	}
+	message := fmt.Sprintf("DAOld 请求确认你的账号操作。\n\n账号: %s\nNonce: %s\n签发时间: %s",
+		userId.Hex(), nonce, time.Now().UTC().Format(time.RFC3339))
+	if err = s.Store.Set(ctx, reauthChallengeKeyPrefix+userId.Hex(), message, walletChallengeExpire); err != nil {
+		log.CtxError(ctx, "failed to store reauth challenge: %v", err)
+		return nil, err
+	}
+
+	return &user.ReauthChallengeResp{
+		Resp:      dto.Success(),
+		Message:   message,
+		ExpiresIn: int64(walletChallengeExpire.Seconds()),
+	}, nil
+}
+
+// verifyReauth 校验已绑定钱包对确认消息的签名, 每条消息只能使用一次
+func (s *WalletService) verifyReauth(ctx context.Context, userId bson.ObjectID, address, signature string) error {
+	wallet, err := s.findOwnWallet(ctx, userId, address)
+	if err != nil {
+		return err
+	}
+
+	// 获取签名消息
+	key := reauthChallengeKeyPrefix + userId.Hex()
+	message, ok, err := s.Store.Get(ctx, key)
+	if err != nil {
+		log.CtxError(ctx, "failed to get reauth challenge: %v", err)
+		return err
+	}
+	if !ok {
+		return errorx.ErrWalletChallengeInvalid
+	}
+	if err = s.Store.Del(ctx, key); err != nil {
+		log.CtxError(ctx, "failed to delete reauth challenge: %v", err)
+		return err
+	}
+
+	// 校验签名
+	sig, err := eth.ParseSignature(signature)
+	if err != nil {
+		return errorx.ErrSIWESignatureInvalid
+	}
+	signer, err := eth.RecoverAddress([]byte(message), sig)
+	if err != nil || signer != wallet.Address {
+		log.CtxInfo(ctx, "reauth signature rejected for %s", wallet.Address)
+		return errorx.ErrSIWESignatureInvalid
+	}
+	return nil
+}
+
+// findOwnWallet 获取当前用户绑定的指定钱包
+func (s *WalletService) findOwnWallet(ctx context.Context, userId bson.ObjectID, address string) (*model.Wallet, error) {
+	address, err := eth.ChecksumAddress(address)
+	if err != nil {
+		return nil, errorx.ErrWalletAddressInvalid
+	}
+	wallet, err := s.WalletRepository.FindByAddress(ctx, address)
+	if err != nil {
+		if errors.Is(err, monc.ErrNotFound) {
+			return nil, errorx.ErrWalletNotFound
+		}
+		log.CtxError(ctx, "failed to find wallet: %v", err)
+		return nil, err
+	}
+	if wallet.UserID != userId {
+		return nil, errorx.ErrWalletNotFound
+	}
+	return wallet, nil
+}
+
+func walletChallengeKey(userId bson.ObjectID, address string) string {
+	return walletChallengeKeyPrefix + userId.Hex() + ":" + address
+}
+
+func toWalletVO(wallet *model.Wallet) *user.WalletVO {
+	return &user.WalletVO{
+		Address:   wallet.Address,
+		Primary:   wallet.Primary,
+		CreatedAt: wallet.CreatedAt,
+	}
+}
@@ -0,0 +1,90 @@
+package eth
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrAddressInvalid 地址不是 20 字节的十六进制串, 或大小写与 EIP-55 校验和不符
+var ErrAddressInvalid = errors.New("eth: invalid address")
+
+// Keccak256 计算以太坊使用的 Keccak-256 摘要 (与标准 SHA3-256 的填充不同)
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// HashPersonalMessage 按 EIP-191 (personal_sign) 计算消息摘要
+func HashPersonalMessage(message []byte) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))
+	return Keccak256([]byte(prefix), message)
+}
+
+// PubkeyToAddress 由公钥计算 EIP-55 校验和形式的地址
+func PubkeyToAddress(pub *PublicKey) string {
+	// 去掉非压缩格式的 0x04 前缀, 对 X || Y 取摘要
+	return checksum(Keccak256(pub.SerializeUncompressed()[1:])[12:])
+}
+
+// RecoverAddress 从 personal_sign 签名中恢复签名者地址
+func RecoverAddress(message, sig []byte) (string, error) {
+	pub, err := RecoverPubkey(HashPersonalMessage(message), sig)
+	if err != nil {
+		return "", err
+	}
+	return PubkeyToAddress(pub), nil
+}
+
+// ParseSignature 解析 0x 开头的十六进制签名
+func ParseSignature(s string) ([]byte, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(sig) != 65 {
+		return nil, ErrSignatureInvalid
+	}
+	return sig, nil
+}
+
+// ChecksumAddress 将地址规范化为 EIP-55 校验和形式
+// 全小写或全大写的输入视为未带校验和; 大小写混合时必须与校验和一致
+func ChecksumAddress(addr string) (string, error) {
+	raw, ok := strings.CutPrefix(addr, "0x")
+	if !ok || len(raw) != 40 {
+		return "", ErrAddressInvalid
+	}
+	b, err := hex.DecodeString(raw)
+	if err != nil {
+		return "", ErrAddressInvalid
+	}
+
+	sum := checksum(b)
+	if raw != strings.ToLower(raw) && raw != strings.ToUpper(raw) && sum[2:] != raw {
+		return "", ErrAddressInvalid
+	}
+	return sum, nil
+}
+
+// IsChecksumAddress 判断地址是否已是正确的 EIP-55 校验和形式
+func IsChecksumAddress(addr string) bool {
+	sum, err := ChecksumAddress(addr)
+	return err == nil && sum == addr
+}
+
+func checksum(addr []byte) string {
+	lower := hex.EncodeToString(addr)
+	hash := Keccak256([]byte(lower))
+	out := []byte(lower)
+	for i, c := range out {
+		// 对应哈希半字节 >= 8 的字母大写
+		if c >= 'a' && hash[i/2]>>(4*(1-uint(i%2)))&0x0f >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
@@ -0,0 +1,74 @@
+package eth
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+var (
+	ErrSignatureInvalid = errors.New("eth: invalid signature")
+	ErrRecoveryFailed   = errors.New("eth: public key recovery failed")
+)
+
+// PublicKey 是 secp256k1 公钥
+type PublicKey = secp256k1.PublicKey
+
+// PrivateKey 是 secp256k1 私钥
+type PrivateKey = secp256k1.PrivateKey
+
+// GenerateKey 生成随机私钥
+func GenerateKey() (*PrivateKey, error) {
+	return secp256k1.GeneratePrivateKey()
+}
+
+// NewPrivateKey 由标量构造私钥, d 需在 [1, n-1] 范围内
+func NewPrivateKey(d *big.Int) *PrivateKey {
+	return secp256k1.PrivKeyFromBytes(d.FillBytes(make([]byte, 32)))
+}
+
+// Sign 对 32 字节摘要签名 (RFC 6979), 返回以太坊格式的 65 字节签名 r || s || v, 其中 v 为 27 或 28
+// s 取低半区 (EIP-2), 与主流钱包的输出一致
+func Sign(hash []byte, key *PrivateKey) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, errors.New("eth: hash must be 32 bytes")
+	}
+
+	// 紧凑签名格式为 v || r || s, v 为 27 + 恢复ID
+	compact := ecdsa.SignCompact(key, hash, false)
+	sig := make([]byte, 65)
+	copy(sig, compact[1:])
+	sig[64] = compact[0]
+	return sig, nil
+}
+
+// RecoverPubkey 从摘要与 65 字节签名中恢复签名者公钥, v 可以是 0/1 或 27/28
+// 与以太坊交易的规则一致, 拒绝 s 位于高半区的签名 (EIP-2), 避免同一消息存在两个有效签名
+func RecoverPubkey(hash, sig []byte) (*PublicKey, error) {
+	if len(hash) != 32 || len(sig) != 65 {
+		return nil, ErrSignatureInvalid
+	}
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return nil, ErrSignatureInvalid
+	}
+
+	var s secp256k1.ModNScalar
+	if overflow := s.SetByteSlice(sig[32:64]); overflow || s.IsZero() || s.IsOverHalfOrder() {
+		return nil, ErrSignatureInvalid
+	}
+
+	compact := make([]byte, 65)
+	compact[0] = 27 + v
+	copy(compact[1:], sig[:64])
+	pub, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return nil, ErrRecoveryFailed
+	}
+	return pub, nil
+}
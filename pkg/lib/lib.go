@@ -14,13 +14,15 @@ import (
 	"github.com/google/uuid"
 )
 
-// JSONF 将对象序列化成json格式字符串
+// JSONF 将对象序列化成json格式字符串, 用于日志输出
+// 带有 `log:"redact"` 标签的字段与脱敏名单中的键会被替换为 Redacted, 嵌套的结构体、切片与 map 同样生效
 func JSONF(v any) string {
 	data, err := json.Marshal(v)
 	if err != nil {
-		log.Error("JSONF fail, v=%v, err=%v", v, err)
+		log.Error("JSONF fail, type=%T, err=%v", v, err)
+		return ""
 	}
-	return string(data)
+	return string(redact(v, data))
 }
 
 // GzipCompress gzip压缩
@@ -0,0 +1,206 @@
+package lib
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/common/json"
+)
+
+// Redacted 日志中替换敏感字段的值
+const Redacted = "[REDACTED]"
+
+// 结构体字段带有 `log:"redact"` 标签时, JSONF 输出中该字段被替换为 Redacted
+const (
+	redactTagKey   = "log"
+	redactTagValue = "redact"
+)
+
+// defaultRedactKeys 不论是否带有标签, 以这些名称出现的键都会被脱敏, 比较时忽略大小写、下划线与连字符
+var defaultRedactKeys = []string{
+	"password", "oldPassword", "newPassword", "confirmPassword",
+	"accessToken", "refreshToken", "idToken", "mfaToken",
+	"secret", "clientSecret", "privateKey", "apiKey",
+	"recoveryCode", "recoveryCodes",
+	"authorization", "cookie",
+}
+
+var redactKeys = struct {
+	sync.RWMutex
+	set map[string]struct{}
+}{set: make(map[string]struct{})}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[interface{ MarshalJSON() ([]byte, error) }]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+func init() {
+	AddRedactKeys(defaultRedactKeys...)
+}
+
+// AddRedactKeys 追加需要脱敏的键名
+func AddRedactKeys(keys ...string) {
+	redactKeys.Lock()
+	defer redactKeys.Unlock()
+	for _, key := range keys {
+		if key = normalizeRedactKey(key); key != "" {
+			redactKeys.set[key] = struct{}{}
+		}
+	}
+}
+
+func isRedactKey(key string) bool {
+	redactKeys.RLock()
+	defer redactKeys.RUnlock()
+	_, ok := redactKeys.set[normalizeRedactKey(key)]
+	return ok
+}
+
+func normalizeRedactKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// redact 对 v 序列化得到的 data 脱敏, 解析失败时不输出原文
+func redact(v any, data []byte) []byte {
+	var tree any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&tree); err != nil {
+		return []byte(`"` + Redacted + `"`)
+	}
+
+	redactTagged(reflect.ValueOf(v), tree)
+	redactByKey(tree)
+
+	out, err := json.Marshal(tree)
+	if err != nil {
+		return []byte(`"` + Redacted + `"`)
+	}
+	return out
+}
+
+// redactTagged 同时遍历原值与解析后的 JSON, 将带脱敏标签的字段替换掉
+func redactTagged(v reflect.Value, node any) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return
+	}
+	// 自定义序列化的类型结构与字段不对应, 不再深入
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) ||
+		reflect.PointerTo(v.Type()).Implements(jsonMarshalerType) || reflect.PointerTo(v.Type()).Implements(textMarshalerType) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if obj, ok := node.(map[string]any); ok {
+			redactStruct(v, obj)
+		}
+	case reflect.Slice, reflect.Array:
+		if arr, ok := node.([]any); ok {
+			for i := 0; i < v.Len() && i < len(arr); i++ {
+				redactTagged(v.Index(i), arr[i])
+			}
+		}
+	case reflect.Map:
+		if obj, ok := node.(map[string]any); ok {
+			iter := v.MapRange()
+			for iter.Next() {
+				if child, ok := obj[mapKeyString(iter.Key())]; ok {
+					redactTagged(iter.Value(), child)
+				}
+			}
+		}
+	}
+}
+
+func redactStruct(v reflect.Value, obj map[string]any) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, named := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		// 未命名的嵌入结构体, 其字段提升到外层对象
+		if field.Anonymous && !named {
+			fv := v.Field(i)
+			for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				redactStruct(fv, obj)
+			}
+			continue
+		}
+
+		child, ok := obj[name]
+		if !ok {
+			continue
+		}
+		if field.Tag.Get(redactTagKey) == redactTagValue {
+			obj[name] = redactValue(child)
+			continue
+		}
+		redactTagged(v.Field(i), child)
+	}
+}
+
+// redactByKey 将脱敏名单中的键替换掉, 适用于没有标签的 map 与任意嵌套层级
+func redactByKey(node any) {
+	switch n := node.(type) {
+	case map[string]any:
+		for key, child := range n {
+			if isRedactKey(key) {
+				n[key] = redactValue(child)
+			} else {
+				redactByKey(child)
+			}
+		}
+	case []any:
+		for _, child := range n {
+			redactByKey(child)
+		}
+	}
+}
+
+// redactValue 空值保持原样, 便于排查缺少参数的请求
+func redactValue(node any) any {
+	if node == nil || node == "" {
+		return node
+	}
+	return Redacted
+}
+
+// jsonFieldName 返回字段序列化后的键名, 第二个返回值表示是否在标签中指定了名称
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "-", true
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, false
+}
+
+func mapKeyString(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	return fmt.Sprint(key.Interface())
+}
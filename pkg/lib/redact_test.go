@@ -0,0 +1,122 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+type redactInner struct {
+	Name  string `json:"name"`
+	Token string `json:"token" log:"redact"`
+}
+
+type redactEmbedded struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	PIN  string `json:"pin" log:"redact"`
+}
+
+type redactOuter struct {
+	*redactEmbedded
+	Email    string            `json:"email"`
+	Inner    *redactInner      `json:"inner"`
+	Items    []redactInner     `json:"items"`
+	ByName   map[string]any    `json:"byName"`
+	Codes    []string          `json:"codes" log:"redact"`
+	Attempts int               `json:"attempts" log:"redact"`
+	Headers  map[string]string `json:"headers"`
+	Skipped  string            `json:"-"`
+}
+
+func TestJSONFRedacts(t *testing.T) {
+	cases := []struct {
+		name   string
+		value  any
+		hidden []string // 不能出现在输出中的内容
+		shown  []string // 必须出现在输出中的内容
+	}{
+		{
+			name:  "nil value",
+			value: nil,
+			shown: []string{"null"},
+		},
+		{
+			name:   "tagged field",
+			value:  &redactInner{Name: "alice", Token: "tok-123"},
+			hidden: []string{"tok-123"},
+			shown:  []string{"alice", Redacted},
+		},
+		{
+			name:   "empty tagged field stays empty",
+			value:  redactInner{Name: "alice"},
+			shown:  []string{`"token":""`},
+			hidden: []string{Redacted},
+		},
+		{
+			name: "nested structs, slices and embedded fields",
+			value: &redactOuter{
+				redactEmbedded: &redactEmbedded{Code: 0, Msg: "ok", PIN: "9876"},
+				Email:          "a@example.com",
+				Inner:          &redactInner{Name: "inner", Token: "inner-secret"},
+				Items:          []redactInner{{Name: "first", Token: "item-secret-1"}, {Name: "second", Token: "item-secret-2"}},
+				Codes:          []string{"rc-aaaa", "rc-bbbb"},
+				Attempts:       3,
+				Skipped:        "never",
+			},
+			hidden: []string{"9876", "inner-secret", "item-secret-1", "item-secret-2", "rc-aaaa", "rc-bbbb", `"attempts":3`, "never"},
+			shown:  []string{"a@example.com", "inner", "first", "second", `"code":0`, `"msg":"ok"`},
+		},
+		{
+			name: "default keys in untagged maps",
+			value: map[string]any{
+				"password": "hunter2",
+				"profile": map[string]any{
+					"access_token":  "at-1",
+					"Refresh-Token": "rt-1",
+					"nickname":      "bob",
+				},
+				"list": []any{map[string]any{"newPassword": "p@ss"}},
+			},
+			hidden: []string{"hunter2", "at-1", "rt-1", "p@ss"},
+			shown:  []string{"bob"},
+		},
+		{
+			name: "default keys in untagged struct fields",
+			value: &redactOuter{
+				ByName:  map[string]any{"secret": "s3cr3t", "inner": &redactInner{Token: "deep-secret"}},
+				Headers: map[string]string{"Authorization": "Bearer abc.def", "Accept": "application/json"},
+			},
+			hidden: []string{"s3cr3t", "deep-secret", "abc.def"},
+			shown:  []string{"application/json"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := JSONF(tc.value)
+			for _, s := range tc.hidden {
+				if strings.Contains(got, s) {
+					t.Errorf("JSONF() = %s, must not contain %q", got, s)
+				}
+			}
+			for _, s := range tc.shown {
+				if !strings.Contains(got, s) {
+					t.Errorf("JSONF() = %s, want it to contain %q", got, s)
+				}
+			}
+		})
+	}
+}
+
+func TestAddRedactKeys(t *testing.T) {
+	value := map[string]string{"sessionKey": "sk-1", "other": "visible"}
+	if got := JSONF(value); !strings.Contains(got, "sk-1") {
+		t.Fatalf("JSONF() = %s, want sessionKey kept before it is configured", got)
+	}
+
+	AddRedactKeys("session_key")
+	got := JSONF(value)
+	if strings.Contains(got, "sk-1") || !strings.Contains(got, "visible") {
+		t.Errorf("JSONF() = %s, want only sessionKey redacted", got)
+	}
+}
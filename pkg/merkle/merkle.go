@@ -0,0 +1,133 @@
+// Package merkle 构建 Merkle 树并生成、校验包含证明
+//
+// 树的哈希方式与 OpenZeppelin MerkleProof 兼容, 便于在合约中校验:
+// 叶子为 keccak256(keccak256(data)), 内部节点为两个子节点按字节序排序后拼接的 keccak256.
+// 因为子节点有序, 证明中不需要记录左右位置. 叶子按哈希排序后逐层两两合并, 落单的节点直接进入上一层.
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/NoANameGroup/DAOld-Backend/pkg/eth"
+)
+
+var (
+	ErrEmpty         = errors.New("merkle: no leaves")
+	ErrDuplicateLeaf = errors.New("merkle: duplicate leaf")
+	ErrLeafNotFound  = errors.New("merkle: leaf not in tree")
+	ErrHashInvalid   = errors.New("merkle: malformed hash")
+)
+
+// Hash 32 字节的 keccak256 哈希
+type Hash [32]byte
+
+// Hex 返回 0x 开头的十六进制形式
+func (h Hash) Hex() string {
+	return "0x" + hex.EncodeToString(h[:])
+}
+
+// ParseHash 解析 Hex 的输出, 0x 前缀可省略
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != len(h) {
+		return h, ErrHashInvalid
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// HashLeaf 计算叶子哈希, 两次哈希使叶子不会与内部节点混淆
+func HashLeaf(data []byte) Hash {
+	var h Hash
+	copy(h[:], eth.Keccak256(eth.Keccak256(data)))
+	return h
+}
+
+// hashPair 按字节序排序后拼接两个节点再哈希
+func hashPair(a, b Hash) Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	var h Hash
+	copy(h[:], eth.Keccak256(a[:], b[:]))
+	return h
+}
+
+// Tree 一棵 Merkle 树, levels[0] 为排序后的叶子, 最后一层只有根
+type Tree struct {
+	levels [][]Hash
+}
+
+// New 由叶子哈希构建树, 叶子不能为空或重复
+func New(leaves []Hash) (*Tree, error) {
+	if len(leaves) == 0 {
+		return nil, ErrEmpty
+	}
+	level := slices.Clone(leaves)
+	slices.SortFunc(level, compare)
+	for i := 1; i < len(level); i++ {
+		if level[i] == level[i-1] {
+			return nil, ErrDuplicateLeaf
+		}
+	}
+
+	t := &Tree{levels: [][]Hash{level}}
+	for len(level) > 1 {
+		next := make([]Hash, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+			} else {
+				next = append(next, hashPair(level[i], level[i+1]))
+			}
+		}
+		t.levels = append(t.levels, next)
+		level = next
+	}
+	return t, nil
+}
+
+// Root 返回树根
+func (t *Tree) Root() Hash {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Len 返回叶子数量
+func (t *Tree) Len() int {
+	return len(t.levels[0])
+}
+
+// Proof 返回叶子的包含证明, 即自底向上每一层的兄弟节点
+func (t *Tree) Proof(leaf Hash) ([]Hash, error) {
+	i, found := slices.BinarySearchFunc(t.levels[0], leaf, compare)
+	if !found {
+		return nil, ErrLeafNotFound
+	}
+
+	proof := make([]Hash, 0, len(t.levels)-1)
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if sibling := i ^ 1; sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		i /= 2
+	}
+	return proof, nil
+}
+
+// Verify 离线校验叶子是否包含在以 root 为根的树中
+func Verify(root, leaf Hash, proof []Hash) bool {
+	computed := leaf
+	for _, p := range proof {
+		computed = hashPair(computed, p)
+	}
+	return computed == root
+}
+
+func compare(a, b Hash) int {
+	return bytes.Compare(a[:], b[:])
+}
@@ -0,0 +1,163 @@
+package merkle
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"testing"
+
+	"github.com/NoANameGroup/DAOld-Backend/pkg/eth"
+)
+
+var names = []string{"alice", "bob", "carol", "dave", "erin", "frank", "grace", "heidi", "ivan"}
+
+func leavesOf(data []string) []Hash {
+	leaves := make([]Hash, 0, len(data))
+	for _, d := range data {
+		leaves = append(leaves, HashLeaf([]byte(d)))
+	}
+	return leaves
+}
+
+func mustParseHash(t *testing.T, s string) Hash {
+	t.Helper()
+	h, err := ParseHash(s)
+	if err != nil {
+		t.Fatalf("ParseHash(%s) error = %v", s, err)
+	}
+	return h
+}
+
+// 由 go-ethereum 的 keccak256 按 OpenZeppelin MerkleProof 的有序拼接方式独立计算
+func TestRootVectors(t *testing.T) {
+	tests := []struct {
+		leaves int
+		root   string
+	}{
+		{1, "0x71d363481396fa56a00521fc812bc7e101310bfc1bc5dfd0f52533c3a4edfad7"},
+		{2, "0x5c0635e5fc3db392effff40439547497d727e51565c585cdfcf781257024849d"},
+		{3, "0x3def50d8336a0954a395aff55e90f74e78db55ad6daa0031065f1e9ebd5eb494"},
+		{4, "0x0a6b94000e4da2ed3f7fe152f44ce116decc9242e6923a22ece1edcb5bbc797c"},
+		{5, "0xc0d61b134359470055355304789cecbebae9791a88f23930d10a61c0636dc333"},
+	}
+	for _, tt := range tests {
+		tree, err := New(leavesOf(names[:tt.leaves]))
+		if err != nil {
+			t.Fatalf("New(%d leaves) error = %v", tt.leaves, err)
+		}
+		if got := tree.Root(); got != mustParseHash(t, tt.root) {
+			t.Errorf("Root(%d leaves) = %s, want %s", tt.leaves, got.Hex(), tt.root)
+		}
+	}
+}
+
+func TestHashLeaf(t *testing.T) {
+	want := "0xeed97ccc1751cbc11370f50e89f468d3d815069eacf3d55b7134d7bc9ee3412b"
+	if got := HashLeaf([]byte("bob")); got.Hex() != want {
+		t.Errorf("HashLeaf(bob) = %s, want %s", got.Hex(), want)
+	}
+}
+
+func TestProofVerify(t *testing.T) {
+	for n := 1; n <= len(names); n++ {
+		t.Run(fmt.Sprintf("%d leaves", n), func(t *testing.T) {
+			leaves := leavesOf(names[:n])
+			tree, err := New(leaves)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if tree.Len() != n {
+				t.Fatalf("Len() = %d, want %d", tree.Len(), n)
+			}
+			maxProof := bits.Len(uint(n - 1))
+			for i, leaf := range leaves {
+				proof, err := tree.Proof(leaf)
+				if err != nil {
+					t.Fatalf("Proof(%s) error = %v", names[i], err)
+				}
+				if len(proof) > maxProof {
+					t.Errorf("Proof(%s) has %d hashes, want at most %d", names[i], len(proof), maxProof)
+				}
+				if !Verify(tree.Root(), leaf, proof) {
+					t.Errorf("Verify(%s) = false", names[i])
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsTampered(t *testing.T) {
+	leaves := leavesOf(names[:4])
+	tree, err := New(leaves)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	root := tree.Root()
+	leaf := leaves[1]
+	proof, err := tree.Proof(leaf)
+	if err != nil {
+		t.Fatalf("Proof() error = %v", err)
+	}
+	if len(proof) < 2 {
+		t.Fatalf("Proof() has %d hashes, want at least 2", len(proof))
+	}
+
+	flipped := append([]Hash(nil), proof...)
+	flipped[0][31] ^= 1
+	var otherRoot Hash
+	otherRoot[0] = 1
+
+	tests := []struct {
+		name  string
+		root  Hash
+		leaf  Hash
+		proof []Hash
+	}{
+		{"flipped proof bit", root, leaf, flipped},
+		{"truncated proof", root, leaf, proof[:len(proof)-1]},
+		{"extra proof hash", root, leaf, append(append([]Hash(nil), proof...), leaves[0])},
+		{"swapped proof order", root, leaf, append([]Hash{proof[1], proof[0]}, proof[2:]...)},
+		{"other leaf", root, HashLeaf([]byte("mallory")), proof},
+		{"single hash leaf", root, Hash(eth.Keccak256([]byte("bob"))), proof},
+		{"other root", otherRoot, leaf, proof},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if Verify(tt.root, tt.leaf, tt.proof) {
+				t.Error("Verify() = true, want false")
+			}
+		})
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := New(nil); !errors.Is(err, ErrEmpty) {
+		t.Errorf("New(nil) error = %v, want %v", err, ErrEmpty)
+	}
+	leaves := leavesOf([]string{"alice", "bob", "alice"})
+	if _, err := New(leaves); !errors.Is(err, ErrDuplicateLeaf) {
+		t.Errorf("New(duplicate) error = %v, want %v", err, ErrDuplicateLeaf)
+	}
+
+	tree, err := New(leavesOf(names[:3]))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err = tree.Proof(HashLeaf([]byte("mallory"))); !errors.Is(err, ErrLeafNotFound) {
+		t.Errorf("Proof(missing) error = %v, want %v", err, ErrLeafNotFound)
+	}
+}
+
+func TestParseHash(t *testing.T) {
+	h := HashLeaf([]byte("alice"))
+	for _, s := range []string{h.Hex(), h.Hex()[2:]} {
+		if got, err := ParseHash(s); err != nil || got != h {
+			t.Errorf("ParseHash(%s) = %s, %v, want %s", s, got.Hex(), err, h.Hex())
+		}
+	}
+	for _, s := range []string{"", "0x", "0x1234", h.Hex() + "00", "0x" + string(make([]byte, 64))} {
+		if _, err := ParseHash(s); !errors.Is(err, ErrHashInvalid) {
+			t.Errorf("ParseHash(%q) error = %v, want %v", s, err, ErrHashInvalid)
+		}
+	}
+}
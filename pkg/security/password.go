@@ -1,11 +1,17 @@
 package security
 
 import (
-	"fmt"
+	"sync"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// dummyHash 用于账号不存在时的比较, 首次使用时生成
+var dummyHash = sync.OnceValue(func() []byte {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("daold-dummy-password"), bcrypt.DefaultCost)
+	return hashed
+})
+
 // HashPassword 将明文密码生成 bcrypt 哈希
 func HashPassword(plain string) (string, error) {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
@@ -17,7 +23,12 @@ func HashPassword(plain string) (string, error) {
 
 // ComparePassword 比较明文密码与哈希是否匹配
 func ComparePassword(hashed, plain string) bool {
-	fmt.Printf("Comparing hashed: %s with plain: %s\n\n", hashed, plain) // Debugging line
 	err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte(plain))
 	return err == nil
 }
+
+// CompareDummyPassword 与固定哈希做一次比较并丢弃结果
+// 账号不存在时调用, 使耗时与密码错误时一致, 避免通过响应时间判断账号是否存在
+func CompareDummyPassword(plain string) {
+	_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(plain))
+}
@@ -0,0 +1,47 @@
+package security
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStdout 收集 fn 执行期间写入标准输出与标准错误的内容
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, w
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	fn()
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestComparePasswordDoesNotPrint(t *testing.T) {
+	const plain = "correct-horse-battery"
+	hashed, err := HashPassword(plain)
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	var ok, wrong bool
+	output := captureStdout(t, func() {
+		ok = ComparePassword(hashed, plain)
+		wrong = ComparePassword(hashed, "wrong-password")
+	})
+	if !ok || wrong {
+		t.Fatalf("ComparePassword() = %v, %v, want true, false", ok, wrong)
+	}
+	for _, s := range []string{plain, "wrong-password", hashed} {
+		if strings.Contains(output, s) {
+			t.Errorf("ComparePassword() printed %q", s)
+		}
+	}
+}
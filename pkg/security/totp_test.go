@@ -0,0 +1,98 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret 为 RFC 6238 附录 B 中 SHA-1 的密钥 "12345678901234567890" 的 Base32 编码
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// RFC 6238 附录 B 的 SHA-1 测试向量, 取 8 位验证码的后 6 位
+func TestTOTPCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		step := TOTPStep(time.Unix(tt.unix, 0))
+		got, err := TOTPCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatalf("TOTPCode(%d) error = %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+		if gotStep, ok := ValidateTOTP(rfc6238Secret, tt.want, time.Unix(tt.unix, 0)); !ok || gotStep != step {
+			t.Errorf("ValidateTOTP(%d) = %d, %v, want %d, true", tt.unix, gotStep, ok, step)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := TOTPStep(now)
+	tests := []struct {
+		name   string
+		offset int64
+		ok     bool
+	}{
+		{"previous step", -1, true},
+		{"current step", 0, true},
+		{"next step", 1, true},
+		{"two steps behind", -2, false},
+		{"two steps ahead", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := TOTPCode(rfc6238Secret, current+tt.offset)
+			if err != nil {
+				t.Fatalf("TOTPCode() error = %v", err)
+			}
+			step, ok := ValidateTOTP(rfc6238Secret, code, now)
+			if ok != tt.ok {
+				t.Fatalf("ValidateTOTP() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && step != current+tt.offset {
+				t.Errorf("ValidateTOTP() step = %d, want %d", step, current+tt.offset)
+			}
+		})
+	}
+}
+
+// 同一验证码在有效期内再次提交时返回相同的时间步, 调用方据此拒绝重放
+func TestValidateTOTPReplayStep(t *testing.T) {
+	issued := time.Unix(1234567890, 0)
+	code, err := TOTPCode(rfc6238Secret, TOTPStep(issued))
+	if err != nil {
+		t.Fatalf("TOTPCode() error = %v", err)
+	}
+
+	first, ok := ValidateTOTP(rfc6238Secret, code, issued)
+	if !ok {
+		t.Fatal("ValidateTOTP() first use rejected")
+	}
+	replay, ok := ValidateTOTP(rfc6238Secret, code, issued.Add(TOTPPeriod*time.Second))
+	if !ok || replay != first {
+		t.Fatalf("ValidateTOTP() replay = %d, %v, want %d, true", replay, ok, first)
+	}
+}
+
+func TestValidateTOTPRejectsMalformed(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "94287082", "abcdef"} {
+		if _, ok := ValidateTOTP(rfc6238Secret, code, now); ok {
+			t.Errorf("ValidateTOTP(%q) accepted", code)
+		}
+	}
+	if _, ok := ValidateTOTP("not base32!", "287082", now); ok {
+		t.Error("ValidateTOTP() accepted an invalid secret")
+	}
+}
@@ -0,0 +1,304 @@
+// Package siwe 实现 EIP-4361 (Sign-In with Ethereum) 消息的解析、校验与验签
+package siwe
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/pkg/eth"
+)
+
+const (
+	headerSuffix = " wants you to sign in with your Ethereum account:"
+	version      = "1"
+)
+
+var (
+	ErrMessageInvalid   = errors.New("siwe: malformed message")
+	ErrDomainMismatch   = errors.New("siwe: domain mismatch")
+	ErrURIMismatch      = errors.New("siwe: uri mismatch")
+	ErrChainIDMismatch  = errors.New("siwe: chain id not allowed")
+	ErrNonceMismatch    = errors.New("siwe: nonce mismatch")
+	ErrExpired          = errors.New("siwe: message expired")
+	ErrNotYetValid      = errors.New("siwe: message not yet valid")
+	ErrSignatureInvalid = errors.New("siwe: signature does not match address")
+)
+
+// Message 是一条 EIP-4361 消息
+type Message struct {
+	Scheme         string
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime time.Time // 零值表示未指定
+	NotBefore      time.Time // 零值表示未指定
+	RequestID      string
+	Resources      []string
+
+	raw string // 解析时的原始文本, 验签以原文为准
+}
+
+// ValidateOptions 服务端对消息的要求
+type ValidateOptions struct {
+	Domain   string        // 必须与消息的 domain 完全一致
+	URI      string        // 非空时消息的 URI 必须以此为前缀
+	ChainIDs []int64       // 非空时消息的 Chain ID 必须在其中
+	Nonce    string        // 非空时必须与消息的 nonce 一致
+	MaxAge   time.Duration // 大于零时 Issued At 距今不得超过此时长
+	Now      time.Time     // 零值时使用当前时间
+}
+
+// clockSkew 容忍客户端与服务端的时钟误差
+const clockSkew = time.Minute
+
+// ParseMessage 解析 EIP-4361 消息文本
+func ParseMessage(text string) (*Message, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	m := &Message{}
+	i := 0
+	next := func() (string, bool) {
+		if i >= len(lines) {
+			return "", false
+		}
+		i++
+		return lines[i-1], true
+	}
+
+	// 标题行: [scheme://]domain wants you to sign in with your Ethereum account:
+	header, _ := next()
+	authority, ok := strings.CutSuffix(header, headerSuffix)
+	if !ok || authority == "" {
+		return nil, fmt.Errorf("%w: header", ErrMessageInvalid)
+	}
+	if scheme, domain, ok := strings.Cut(authority, "://"); ok {
+		m.Scheme, m.Domain = scheme, domain
+	} else {
+		m.Domain = authority
+	}
+
+	// 地址行
+	address, _ := next()
+	if _, err := eth.ChecksumAddress(address); err != nil {
+		return nil, fmt.Errorf("%w: address", ErrMessageInvalid)
+	}
+	m.Address = address
+
+	// 空行, 可选的声明, 空行
+	if line, _ := next(); line != "" {
+		return nil, fmt.Errorf("%w: expected blank line after address", ErrMessageInvalid)
+	}
+	line, _ := next()
+	if line != "" && !strings.HasPrefix(line, "URI: ") {
+		m.Statement = line
+		if line, _ = next(); line != "" {
+			return nil, fmt.Errorf("%w: expected blank line after statement", ErrMessageInvalid)
+		}
+		line, _ = next()
+	} else if line == "" {
+		line, _ = next()
+	}
+
+	// 字段
+	var uri, ver, chainID, nonce, issuedAt, expirationTime, notBefore, requestID string
+	for _, f := range []struct {
+		name     string
+		dst      *string
+		required bool
+	}{
+		{"URI", &uri, true},
+		{"Version", &ver, true},
+		{"Chain ID", &chainID, true},
+		{"Nonce", &nonce, true},
+		{"Issued At", &issuedAt, true},
+		{"Expiration Time", &expirationTime, false},
+		{"Not Before", &notBefore, false},
+		{"Request ID", &requestID, false},
+	} {
+		value, ok := strings.CutPrefix(line, f.name+": ")
+		if !ok {
+			if f.required {
+				return nil, fmt.Errorf("%w: missing %s", ErrMessageInvalid, f.name)
+			}
+			continue
+		}
+		*f.dst = value
+		line, _ = next()
+	}
+
+	// 可选的资源列表
+	if line == "Resources:" {
+		for i < len(lines) {
+			resource, ok := strings.CutPrefix(lines[i], "- ")
+			if !ok {
+				break
+			}
+			m.Resources = append(m.Resources, resource)
+			i++
+		}
+		line, _ = next()
+	}
+	if line != "" || i < len(lines) {
+		return nil, fmt.Errorf("%w: unexpected trailing content", ErrMessageInvalid)
+	}
+
+	var err error
+	if _, err = url.Parse(uri); err != nil || uri == "" {
+		return nil, fmt.Errorf("%w: uri", ErrMessageInvalid)
+	}
+	m.URI = uri
+	if ver != version {
+		return nil, fmt.Errorf("%w: version", ErrMessageInvalid)
+	}
+	m.Version = ver
+	if m.ChainID, err = strconv.ParseInt(chainID, 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: chain id", ErrMessageInvalid)
+	}
+	if len(nonce) < 8 || !isAlphanumeric(nonce) {
+		return nil, fmt.Errorf("%w: nonce", ErrMessageInvalid)
+	}
+	m.Nonce = nonce
+	if m.IssuedAt, err = time.Parse(time.RFC3339, issuedAt); err != nil {
+		return nil, fmt.Errorf("%w: issued at", ErrMessageInvalid)
+	}
+	if expirationTime != "" {
+		if m.ExpirationTime, err = time.Parse(time.RFC3339, expirationTime); err != nil {
+			return nil, fmt.Errorf("%w: expiration time", ErrMessageInvalid)
+		}
+	}
+	if notBefore != "" {
+		if m.NotBefore, err = time.Parse(time.RFC3339, notBefore); err != nil {
+			return nil, fmt.Errorf("%w: not before", ErrMessageInvalid)
+		}
+	}
+	m.RequestID = requestID
+	m.raw = text
+	return m, nil
+}
+
+// String 按 EIP-4361 格式输出消息, 与 ParseMessage 互逆
+func (m *Message) String() string {
+	var sb strings.Builder
+	if m.Scheme != "" {
+		sb.WriteString(m.Scheme + "://")
+	}
+	sb.WriteString(m.Domain + headerSuffix + "\n")
+	sb.WriteString(m.Address + "\n\n")
+	if m.Statement != "" {
+		sb.WriteString(m.Statement + "\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString("URI: " + m.URI + "\n")
+	sb.WriteString("Version: " + m.Version + "\n")
+	sb.WriteString("Chain ID: " + strconv.FormatInt(m.ChainID, 10) + "\n")
+	sb.WriteString("Nonce: " + m.Nonce + "\n")
+	sb.WriteString("Issued At: " + m.IssuedAt.UTC().Format(time.RFC3339))
+	if !m.ExpirationTime.IsZero() {
+		sb.WriteString("\nExpiration Time: " + m.ExpirationTime.UTC().Format(time.RFC3339))
+	}
+	if !m.NotBefore.IsZero() {
+		sb.WriteString("\nNot Before: " + m.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if m.RequestID != "" {
+		sb.WriteString("\nRequest ID: " + m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		sb.WriteString("\nResources:")
+		for _, r := range m.Resources {
+			sb.WriteString("\n- " + r)
+		}
+	}
+	return sb.String()
+}
+
+// Validate 校验域名、URI、链 ID、nonce 与有效期
+func (m *Message) Validate(opts ValidateOptions) error {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	if m.Domain != opts.Domain {
+		return ErrDomainMismatch
+	}
+	if opts.URI != "" && !strings.HasPrefix(m.URI, opts.URI) {
+		return ErrURIMismatch
+	}
+	if len(opts.ChainIDs) > 0 && !containsInt64(opts.ChainIDs, m.ChainID) {
+		return ErrChainIDMismatch
+	}
+	if opts.Nonce != "" && m.Nonce != opts.Nonce {
+		return ErrNonceMismatch
+	}
+
+	if m.IssuedAt.After(now.Add(clockSkew)) {
+		return ErrNotYetValid
+	}
+	if !m.NotBefore.IsZero() && now.Add(clockSkew).Before(m.NotBefore) {
+		return ErrNotYetValid
+	}
+	if !m.ExpirationTime.IsZero() && !now.Before(m.ExpirationTime) {
+		return ErrExpired
+	}
+	if opts.MaxAge > 0 && now.Sub(m.IssuedAt) > opts.MaxAge {
+		return ErrExpired
+	}
+	return nil
+}
+
+// VerifySignature 校验 personal_sign 签名是否由消息中的地址签出
+func (m *Message) VerifySignature(signature string) error {
+	sig, err := eth.ParseSignature(signature)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	text := m.raw
+	if text == "" {
+		text = m.String()
+	}
+	signer, err := eth.RecoverAddress([]byte(text), sig)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	if !strings.EqualFold(signer, m.Address) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+func isAlphanumeric(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func containsInt64(list []int64, v int64) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateNonce 生成 EIP-4361 要求的字母数字 nonce
+func GenerateNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,160 @@
+package siwe
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NoANameGroup/DAOld-Backend/pkg/eth"
+)
+
+const exampleMessage = `service.org wants you to sign in with your Ethereum account:
+0xe5A12547fe4E872D192E3eCecb76F2Ce1aeA4946
+
+I accept the ServiceOrg Terms of Service: https://service.org/tos
+
+URI: https://service.org/login
+Version: 1
+Chain ID: 1
+Nonce: 32891757
+Issued At: 2021-09-30T16:25:24.000Z
+Resources:
+- ipfs://Qme7ss3ARVgxv6rXqVPiikMJ8u2NLgmgszg13pYrDKEoiu
+- https://example.com/my-web2-claim.json`
+
+func TestParseMessage(t *testing.T) {
+	m, err := ParseMessage(exampleMessage)
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+	if m.Domain != "service.org" || m.Address != "0xe5A12547fe4E872D192E3eCecb76F2Ce1aeA4946" ||
+		m.Statement != "I accept the ServiceOrg Terms of Service: https://service.org/tos" ||
+		m.URI != "https://service.org/login" || m.ChainID != 1 || m.Nonce != "32891757" ||
+		!m.IssuedAt.Equal(time.Date(2021, 9, 30, 16, 25, 24, 0, time.UTC)) || len(m.Resources) != 2 {
+		t.Errorf("ParseMessage() = %+v", m)
+	}
+}
+
+func TestParseMessageRoundTrip(t *testing.T) {
+	issued := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{"minimal", Message{Domain: "daold.xyz", Address: "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", URI: "https://daold.xyz", Version: "1", ChainID: 1, Nonce: "abcdEFGH1234", IssuedAt: issued}},
+		{"with scheme and statement", Message{Scheme: "https", Domain: "daold.xyz", Address: "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", Statement: "Sign in to DAOld", URI: "https://daold.xyz/login", Version: "1", ChainID: 137, Nonce: "abcdEFGH1234", IssuedAt: issued}},
+		{"all optional fields", Message{Domain: "localhost:3000", Address: "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", Statement: "hi", URI: "http://localhost:3000", Version: "1", ChainID: 5, Nonce: "abcdEFGH1234", IssuedAt: issued,
+			ExpirationTime: issued.Add(time.Hour), NotBefore: issued, RequestID: "req-1", Resources: []string{"https://a", "https://b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := tt.msg.String()
+			m, err := ParseMessage(text)
+			if err != nil {
+				t.Fatalf("ParseMessage() error = %v\n%s", err, text)
+			}
+			if got := m.String(); got != text {
+				t.Errorf("round trip mismatch:\n%s\n---\n%s", got, text)
+			}
+		})
+	}
+}
+
+func TestParseMessageInvalid(t *testing.T) {
+	valid := Message{Domain: "daold.xyz", Address: "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", URI: "https://daold.xyz", Version: "1", ChainID: 1, Nonce: "abcdEFGH1234", IssuedAt: time.Now()}
+	tests := []struct {
+		name   string
+		mutate func(m *Message)
+	}{
+		{"bad checksum", func(m *Message) { m.Address = "0x7e5F4552091A69125d5DfCb7b8C2659029395Bdf" }},
+		{"short nonce", func(m *Message) { m.Nonce = "abc" }},
+		{"non alphanumeric nonce", func(m *Message) { m.Nonce = "abcd-efgh-1234" }},
+		{"wrong version", func(m *Message) { m.Version = "2" }},
+		{"missing domain", func(m *Message) { m.Domain = "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid
+			tt.mutate(&m)
+			if _, err := ParseMessage(m.String()); !errors.Is(err, ErrMessageInvalid) {
+				t.Errorf("ParseMessage() error = %v, want ErrMessageInvalid", err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	base := Message{Domain: "daold.xyz", Address: "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", URI: "https://daold.xyz/login", Version: "1", ChainID: 1, Nonce: "abcdEFGH1234", IssuedAt: now.Add(-time.Minute)}
+	opts := ValidateOptions{Domain: "daold.xyz", URI: "https://daold.xyz", ChainIDs: []int64{1, 137}, Nonce: "abcdEFGH1234", MaxAge: 10 * time.Minute, Now: now}
+	tests := []struct {
+		name   string
+		mutate func(m *Message)
+		want   error
+	}{
+		{"valid", func(m *Message) {}, nil},
+		{"other domain", func(m *Message) { m.Domain = "evil.xyz" }, ErrDomainMismatch},
+		{"other uri", func(m *Message) { m.URI = "https://evil.xyz/login" }, ErrURIMismatch},
+		{"chain not allowed", func(m *Message) { m.ChainID = 56 }, ErrChainIDMismatch},
+		{"other nonce", func(m *Message) { m.Nonce = "zzzzzzzz" }, ErrNonceMismatch},
+		{"expired", func(m *Message) { m.ExpirationTime = now.Add(-time.Second) }, ErrExpired},
+		{"too old", func(m *Message) { m.IssuedAt = now.Add(-time.Hour) }, ErrExpired},
+		{"issued in future", func(m *Message) { m.IssuedAt = now.Add(time.Hour) }, ErrNotYetValid},
+		{"not before in future", func(m *Message) { m.NotBefore = now.Add(time.Hour) }, ErrNotYetValid},
+		{"within clock skew", func(m *Message) { m.IssuedAt = now.Add(30 * time.Second) }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := base
+			tt.mutate(&m)
+			if err := m.Validate(opts); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	key, err := eth.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := eth.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := eth.PubkeyToAddress(key.PubKey())
+	msg := Message{Domain: "daold.xyz", Address: address, URI: "https://daold.xyz", Version: "1", ChainID: 1, Nonce: "abcdEFGH1234", IssuedAt: time.Now().UTC().Truncate(time.Second)}
+	text := msg.String()
+
+	sign := func(k *eth.PrivateKey, text string) string {
+		sig, err := eth.Sign(eth.HashPersonalMessage([]byte(text)), k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "0x" + hex.EncodeToString(sig)
+	}
+
+	tests := []struct {
+		name      string
+		signature string
+		wantErr   bool
+	}{
+		{"signed by address owner", sign(key, text), false},
+		{"signed by another key", sign(other, text), true},
+		{"signature of another message", sign(key, text+"\nResources:\n- https://evil"), true},
+		{"malformed signature", "0x1234", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseMessage(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := m.VerifySignature(tt.signature); (err != nil) != tt.wantErr {
+				t.Errorf("VerifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
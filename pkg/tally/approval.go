@@ -0,0 +1,39 @@
+package tally
+
+import "fmt"
+
+// approval 认可投票: 投票人可以认可任意多个选项, 每个被认可的选项得一票
+type approval struct{}
+
+func (approval) Name() string {
+	return Approval
+}
+
+func (approval) Validate(options []string, b Ballot) error {
+	index, err := indexOptions(options)
+	if err != nil {
+		return err
+	}
+	if len(b.Choices) == 0 {
+		return fmt.Errorf("%w: at least one choice required", ErrInvalidBallot)
+	}
+	return checkChoices(index, b.Choices)
+}
+
+func (a approval) Tally(options []string, ballots []Ballot) (*Result, error) {
+	index, err := indexOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateAll(a, options, ballots); err != nil {
+		return nil, err
+	}
+
+	votes := make([]float64, len(options))
+	for _, b := range ballots {
+		for _, c := range b.Choices {
+			votes[index[c]]++
+		}
+	}
+	return singleRound(Approval, options, votes, len(ballots)), nil
+}
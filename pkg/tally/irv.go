@@ -0,0 +1,142 @@
+package tally
+
+import "fmt"
+
+// instantRunoff 排序复选: 每轮统计各票排名最高且未淘汰的选项,
+// 有选项超过半数时胜出, 否则淘汰得票最少的选项进入下一轮
+type instantRunoff struct{}
+
+func (instantRunoff) Name() string {
+	return InstantRunoff
+}
+
+func (instantRunoff) Validate(options []string, b Ballot) error {
+	index, err := indexOptions(options)
+	if err != nil {
+		return err
+	}
+	if len(b.Choices) == 0 {
+		return fmt.Errorf("%w: at least one ranked choice required", ErrInvalidBallot)
+	}
+	return checkChoices(index, b.Choices)
+}
+
+func (v instantRunoff) Tally(options []string, ballots []Ballot) (*Result, error) {
+	index, err := indexOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateAll(v, options, ballots); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Strategy: InstantRunoff, Ballots: len(ballots)}
+	active := make([]bool, len(options))
+	for i := range active {
+		active[i] = true
+	}
+	remaining := len(options)
+	var history [][]float64 // 每轮各选项的得票, 用于淘汰时决胜
+	var eliminated []Score  // 按淘汰顺序
+
+	for {
+		// 统计本轮得票
+		votes := make([]float64, len(options))
+		exhausted, continuing := 0.0, 0.0
+		for _, b := range ballots {
+			counted := false
+			for _, c := range b.Choices {
+				if i := index[c]; active[i] {
+					votes[i]++
+					continuing++
+					counted = true
+					break
+				}
+			}
+			if !counted {
+				exhausted++
+			}
+		}
+		history = append(history, votes)
+
+		round := Round{Number: len(history), Exhausted: exhausted}
+		scores := make([]Score, 0, remaining)
+		for i, o := range options {
+			if active[i] {
+				scores = append(scores, Score{Option: o, Votes: votes[i]})
+			}
+		}
+		round.Scores = scores
+
+		// 过半或只剩一个选项时结束
+		ranking := rank(scores)
+		if continuing == 0 || ranking[0].Votes*2 > continuing || remaining == 1 {
+			result.Rounds = append(result.Rounds, round)
+			result.Ranking = append(ranking, reverse(eliminated)...)
+			if continuing > 0 {
+				result.Winner = ranking[0].Option
+			}
+			return result, nil
+		}
+
+		// 淘汰得票最少的选项
+		loser, tieBreak := lowest(active, history)
+		round.Eliminated, round.TieBreak = options[loser], tieBreak
+		result.Rounds = append(result.Rounds, round)
+		eliminated = append(eliminated, Score{Option: options[loser], Votes: votes[loser]})
+		active[loser] = false
+		remaining--
+
+		// 最后两个选项打平, 由决胜规则决定胜者
+		if remaining == 1 && tieBreak {
+			for i, o := range options {
+				if active[i] {
+					result.Tied = []string{o, options[loser]}
+				}
+			}
+		}
+	}
+}
+
+// lowest 返回本轮应淘汰的选项, 以及是否由决胜规则决定
+// 本轮得票最少的选项并列时, 从最近一轮往前比较得票, 仍相同时淘汰选项列表中靠后的
+func lowest(active []bool, history [][]float64) (int, bool) {
+	current := history[len(history)-1]
+	var candidates []int
+	for i, ok := range active {
+		if !ok {
+			continue
+		}
+		switch {
+		case len(candidates) == 0 || current[i] < current[candidates[0]]:
+			candidates = []int{i}
+		case current[i] == current[candidates[0]]:
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0], false
+	}
+
+	for r := len(history) - 2; r >= 0 && len(candidates) > 1; r-- {
+		var next []int
+		for _, i := range candidates {
+			switch {
+			case len(next) == 0 || history[r][i] < history[r][next[0]]:
+				next = []int{i}
+			case history[r][i] == history[r][next[0]]:
+				next = append(next, i)
+			}
+		}
+		candidates = next
+	}
+	return candidates[len(candidates)-1], true
+}
+
+func reverse(scores []Score) []Score {
+	out := make([]Score, len(scores))
+	for i, s := range scores {
+		out[len(scores)-1-i] = s
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package tally
+
+import "fmt"
+
+// plurality 单选计票: 一人一票, 或按投票权加权
+type plurality struct {
+	name     string
+	weighted bool
+}
+
+func (p plurality) Name() string {
+	return p.name
+}
+
+func (p plurality) Validate(options []string, b Ballot) error {
+	index, err := indexOptions(options)
+	if err != nil {
+		return err
+	}
+	if len(b.Choices) != 1 {
+		return fmt.Errorf("%w: exactly one choice required", ErrInvalidBallot)
+	}
+	if p.weighted && b.Weight < 0 {
+		return fmt.Errorf("%w: negative weight", ErrInvalidBallot)
+	}
+	return checkChoices(index, b.Choices)
+}
+
+func (p plurality) Tally(options []string, ballots []Ballot) (*Result, error) {
+	index, err := indexOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateAll(p, options, ballots); err != nil {
+		return nil, err
+	}
+
+	votes := make([]float64, len(options))
+	for _, b := range ballots {
+		w := 1.0
+		if p.weighted {
+			w = b.Weight
+		}
+		votes[index[b.Choices[0]]] += w
+	}
+	return singleRound(p.name, options, votes, len(ballots)), nil
+}
@@ -0,0 +1,55 @@
+package tally
+
+import (
+	"fmt"
+	"math"
+)
+
+// quadratic 二次方计票: 投票人在积分预算内给选项分配积分, 选项获得积分平方根的票数
+type quadratic struct{}
+
+func (quadratic) Name() string {
+	return Quadratic
+}
+
+func (quadratic) Validate(options []string, b Ballot) error {
+	index, err := indexOptions(options)
+	if err != nil {
+		return err
+	}
+	if len(b.Credits) == 0 {
+		return fmt.Errorf("%w: no credits allocated", ErrInvalidBallot)
+	}
+	spent := 0.0
+	for o, c := range b.Credits {
+		if _, ok := index[o]; !ok {
+			return fmt.Errorf("%w: unknown option %q", ErrInvalidBallot, o)
+		}
+		if c < 0 {
+			return fmt.Errorf("%w: negative credits for %q", ErrInvalidBallot, o)
+		}
+		spent += c
+	}
+	if spent > b.Weight {
+		return fmt.Errorf("%w: %g credits spent, budget is %g", ErrInvalidBallot, spent, b.Weight)
+	}
+	return nil
+}
+
+func (q quadratic) Tally(options []string, ballots []Ballot) (*Result, error) {
+	if _, err := indexOptions(options); err != nil {
+		return nil, err
+	}
+	if err := validateAll(q, options, ballots); err != nil {
+		return nil, err
+	}
+
+	// 按选项顺序累加, 保证浮点结果与选票中 map 的遍历顺序无关
+	votes := make([]float64, len(options))
+	for _, b := range ballots {
+		for i, o := range options {
+			votes[i] += math.Sqrt(b.Credits[o])
+		}
+	}
+	return singleRound(Quadratic, options, votes, len(ballots)), nil
+}
@@ -0,0 +1,176 @@
+// Package tally 根据选票计算投票结果, 计票方式通过 Strategy 插拔
+//
+// 所有计票方式都使用同一条决胜规则: 得票相同时, 选项列表中靠前的选项优先.
+// 排序复选淘汰选项时先比较之前各轮的得票, 仍相同时淘汰列表中靠后的选项.
+package tally
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// 计票方式名称
+const (
+	OnePersonOneVote = "one_person_one_vote"
+	Weighted         = "weighted"
+	Quadratic        = "quadratic"
+	Approval         = "approval"
+	InstantRunoff    = "instant_runoff"
+)
+
+var (
+	ErrNoOptions       = errors.New("tally: no options")
+	ErrDuplicateOption = errors.New("tally: duplicate option")
+	ErrInvalidBallot   = errors.New("tally: invalid ballot")
+)
+
+// Ballot 一张选票
+type Ballot struct {
+	Voter   string
+	Weight  float64            // 加权计票按此计数; 二次方计票中为可分配的积分预算; 其他方式忽略
+	Choices []string           // 单选时只有一项; 认可投票为认可的选项; 排序复选为按偏好从高到低的排名
+	Credits map[string]float64 // 二次方计票中分配给各选项的积分
+}
+
+// Score 一个选项的得票
+type Score struct {
+	Option string  `json:"option"`
+	Votes  float64 `json:"votes"`
+}
+
+// Round 一轮计票, 只有排序复选会有多轮
+type Round struct {
+	Number     int     `json:"number"`
+	Scores     []Score `json:"scores"`               // 本轮仍在竞争的选项, 按选项顺序
+	Exhausted  float64 `json:"exhausted,omitempty"`  // 排名中的选项均已淘汰的选票数
+	Eliminated string  `json:"eliminated,omitempty"` // 本轮被淘汰的选项
+	TieBreak   bool    `json:"tieBreak,omitempty"`   // 淘汰是否由决胜规则决定
+}
+
+// Result 计票结果
+type Result struct {
+	Strategy string   `json:"strategy"`
+	Winner   string   `json:"winner"`         // 无人得票时为空
+	Tied     []string `json:"tied,omitempty"` // 并列第一的选项, Winner 由决胜规则从中选出
+	Ranking  []Score  `json:"ranking"`        // 最终名次, 从高到低
+	Rounds   []Round  `json:"rounds"`         // 每轮明细
+	Ballots  int      `json:"ballots"`        // 计入的选票数
+}
+
+// Strategy 计票方式
+type Strategy interface {
+	Name() string
+	// Validate 校验选票是否符合该计票方式的要求
+	Validate(options []string, b Ballot) error
+	// Tally 计算结果, 任何一张选票无效时返回错误
+	Tally(options []string, ballots []Ballot) (*Result, error)
+}
+
+var strategies = map[string]Strategy{
+	OnePersonOneVote: plurality{name: OnePersonOneVote},
+	Weighted:         plurality{name: Weighted, weighted: true},
+	Quadratic:        quadratic{},
+	Approval:         approval{},
+	InstantRunoff:    instantRunoff{},
+}
+
+// Get 按名称获取计票方式
+func Get(name string) (Strategy, bool) {
+	s, ok := strategies[name]
+	return s, ok
+}
+
+// Names 返回全部计票方式的名称, 按字母顺序
+func Names() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// indexOptions 校验选项并返回选项到下标的映射
+func indexOptions(options []string) (map[string]int, error) {
+	if len(options) == 0 {
+		return nil, ErrNoOptions
+	}
+	index := make(map[string]int, len(options))
+	for i, o := range options {
+		if _, ok := index[o]; ok {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateOption, o)
+		}
+		index[o] = i
+	}
+	return index, nil
+}
+
+// checkChoices 校验选项均存在且不重复
+func checkChoices(index map[string]int, choices []string) error {
+	seen := make(map[string]bool, len(choices))
+	for _, c := range choices {
+		if _, ok := index[c]; !ok {
+			return fmt.Errorf("%w: unknown option %q", ErrInvalidBallot, c)
+		}
+		if seen[c] {
+			return fmt.Errorf("%w: option %q chosen twice", ErrInvalidBallot, c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// validateAll 校验全部选票, 错误中带上投票人
+func validateAll(s Strategy, options []string, ballots []Ballot) error {
+	for _, b := range ballots {
+		if err := s.Validate(options, b); err != nil {
+			return fmt.Errorf("voter %s: %w", b.Voter, err)
+		}
+	}
+	return nil
+}
+
+// singleRound 由一轮得票生成结果, 适用于只有一轮的计票方式
+func singleRound(name string, options []string, votes []float64, ballots int) *Result {
+	scores := make([]Score, len(options))
+	for i, o := range options {
+		scores[i] = Score{Option: o, Votes: votes[i]}
+	}
+	result := &Result{
+		Strategy: name,
+		Ranking:  rank(scores),
+		Rounds:   []Round{{Number: 1, Scores: scores}},
+		Ballots:  ballots,
+	}
+	decide(result)
+	return result
+}
+
+// rank 按得票从高到低排序, 得票相同时保持选项顺序
+func rank(scores []Score) []Score {
+	ranking := make([]Score, len(scores))
+	copy(ranking, scores)
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return ranking[i].Votes > ranking[j].Votes
+	})
+	return ranking
+}
+
+// decide 根据名次确定胜出选项与并列情况
+func decide(result *Result) {
+	if len(result.Ranking) == 0 || result.Ranking[0].Votes <= 0 {
+		return
+	}
+	top := result.Ranking[0].Votes
+	result.Winner = result.Ranking[0].Option
+	for _, s := range result.Ranking[1:] {
+		if s.Votes != top {
+			break
+		}
+		if result.Tied == nil {
+			result.Tied = []string{result.Winner}
+		}
+		result.Tied = append(result.Tied, s.Option)
+	}
+}
@@ -0,0 +1,204 @@
+package tally
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+var abc = []string{"a", "b", "c"}
+
+func choose(choices ...string) Ballot {
+	return Ballot{Choices: choices}
+}
+
+func weighted(w float64, choice string) Ballot {
+	return Ballot{Weight: w, Choices: []string{choice}}
+}
+
+func credits(budget float64, alloc map[string]float64) Ballot {
+	return Ballot{Weight: budget, Credits: alloc}
+}
+
+type tallyCase struct {
+	name    string
+	options []string
+	ballots []Ballot
+	winner  string
+	tied    []string
+	votes   map[string]float64 // 最后一轮各选项得票
+	rounds  int
+	err     error
+}
+
+func runTally(t *testing.T, strategy string, cases []tallyCase) {
+	t.Helper()
+	s, ok := Get(strategy)
+	if !ok {
+		t.Fatalf("Get(%q) not found", strategy)
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := tc.options
+			if options == nil {
+				options = abc
+			}
+			got, err := s.Tally(options, tc.ballots)
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("Tally() error = %v, want %v", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Tally() error = %v", err)
+			}
+			if got.Winner != tc.winner {
+				t.Errorf("Winner = %q, want %q", got.Winner, tc.winner)
+			}
+			if !reflect.DeepEqual(got.Tied, tc.tied) {
+				t.Errorf("Tied = %v, want %v", got.Tied, tc.tied)
+			}
+			if tc.rounds != 0 && len(got.Rounds) != tc.rounds {
+				t.Errorf("len(Rounds) = %d, want %d", len(got.Rounds), tc.rounds)
+			}
+			last := got.Rounds[len(got.Rounds)-1]
+			for _, s := range last.Scores {
+				if want := tc.votes[s.Option]; math.Abs(s.Votes-want) > 1e-9 {
+					t.Errorf("votes[%s] = %v, want %v", s.Option, s.Votes, want)
+				}
+			}
+			if len(got.Ranking) != len(options) {
+				t.Errorf("len(Ranking) = %d, want %d", len(got.Ranking), len(options))
+			}
+		})
+	}
+}
+
+func TestOnePersonOneVote(t *testing.T) {
+	runTally(t, OnePersonOneVote, []tallyCase{
+		{name: "majority", ballots: []Ballot{choose("a"), choose("b"), choose("a")},
+			winner: "a", votes: map[string]float64{"a": 2, "b": 1}},
+		{name: "weight ignored", ballots: []Ballot{weighted(10, "b"), choose("a"), choose("a")},
+			winner: "a", votes: map[string]float64{"a": 2, "b": 1}},
+		{name: "tie goes to earlier option", ballots: []Ballot{choose("c"), choose("b")},
+			winner: "b", tied: []string{"b", "c"}, votes: map[string]float64{"b": 1, "c": 1}},
+		{name: "no ballots", ballots: nil, winner: "", votes: map[string]float64{}},
+		{name: "two choices", ballots: []Ballot{choose("a", "b")}, err: ErrInvalidBallot},
+		{name: "unknown option", ballots: []Ballot{choose("z")}, err: ErrInvalidBallot},
+		{name: "no options", options: []string{}, ballots: nil, err: ErrNoOptions},
+		{name: "duplicate options", options: []string{"a", "a"}, ballots: nil, err: ErrDuplicateOption},
+	})
+}
+
+func TestWeighted(t *testing.T) {
+	runTally(t, Weighted, []tallyCase{
+		{name: "weight beats headcount", ballots: []Ballot{weighted(10, "b"), weighted(3, "a"), weighted(4, "a")},
+			winner: "b", votes: map[string]float64{"a": 7, "b": 10}},
+		{name: "fractional weights", ballots: []Ballot{weighted(0.5, "c"), weighted(0.25, "a")},
+			winner: "c", votes: map[string]float64{"a": 0.25, "c": 0.5}},
+		{name: "tie goes to earlier option", ballots: []Ballot{weighted(2, "c"), weighted(2, "a")},
+			winner: "a", tied: []string{"a", "c"}, votes: map[string]float64{"a": 2, "c": 2}},
+		{name: "zero weight only", ballots: []Ballot{weighted(0, "a")}, winner: "", votes: map[string]float64{}},
+		{name: "negative weight", ballots: []Ballot{weighted(-1, "a")}, err: ErrInvalidBallot},
+	})
+}
+
+func TestQuadratic(t *testing.T) {
+	runTally(t, Quadratic, []tallyCase{
+		{name: "spreading beats concentrating",
+			ballots: []Ballot{
+				credits(100, map[string]float64{"a": 100}),
+				credits(100, map[string]float64{"b": 25}),
+				credits(100, map[string]float64{"b": 25}),
+				credits(100, map[string]float64{"b": 36}),
+			},
+			winner: "b", votes: map[string]float64{"a": 10, "b": 16}},
+		{name: "split allocation", ballots: []Ballot{credits(10, map[string]float64{"a": 4, "c": 1})},
+			winner: "a", votes: map[string]float64{"a": 2, "c": 1}},
+		{name: "tie goes to earlier option", ballots: []Ballot{credits(9, map[string]float64{"c": 9}), credits(9, map[string]float64{"b": 9})},
+			winner: "b", tied: []string{"b", "c"}, votes: map[string]float64{"b": 3, "c": 3}},
+		{name: "over budget", ballots: []Ballot{credits(10, map[string]float64{"a": 6, "b": 5})}, err: ErrInvalidBallot},
+		{name: "negative credits", ballots: []Ballot{credits(10, map[string]float64{"a": -1})}, err: ErrInvalidBallot},
+		{name: "unknown option", ballots: []Ballot{credits(10, map[string]float64{"z": 1})}, err: ErrInvalidBallot},
+		{name: "empty allocation", ballots: []Ballot{credits(10, nil)}, err: ErrInvalidBallot},
+	})
+}
+
+func TestApproval(t *testing.T) {
+	runTally(t, Approval, []tallyCase{
+		{name: "broad support wins", ballots: []Ballot{choose("a", "b"), choose("b", "c"), choose("a")},
+			winner: "a", tied: []string{"a", "b"}, votes: map[string]float64{"a": 2, "b": 2, "c": 1}},
+		{name: "clear winner", ballots: []Ballot{choose("c", "b"), choose("c"), choose("a", "c")},
+			winner: "c", votes: map[string]float64{"a": 1, "b": 1, "c": 3}},
+		{name: "empty ballot", ballots: []Ballot{choose()}, err: ErrInvalidBallot},
+		{name: "duplicate approval", ballots: []Ballot{choose("a", "a")}, err: ErrInvalidBallot},
+	})
+}
+
+func TestInstantRunoff(t *testing.T) {
+	runTally(t, InstantRunoff, []tallyCase{
+		{name: "first round majority", ballots: []Ballot{choose("a", "b"), choose("a"), choose("b")},
+			winner: "a", rounds: 1, votes: map[string]float64{"a": 2, "b": 1}},
+		{name: "transfers decide",
+			ballots: []Ballot{
+				choose("a"), choose("a"), choose("a"), choose("a"),
+				choose("b", "c"), choose("b", "c"), choose("b", "c"),
+				choose("c", "b"), choose("c", "b"),
+			},
+			winner: "b", rounds: 2, votes: map[string]float64{"a": 4, "b": 5}},
+		{name: "exhausted ballots shrink the majority",
+			ballots: []Ballot{
+				choose("a"), choose("a"), choose("a"), choose("a"),
+				choose("b"), choose("b"), choose("b"),
+				choose("c"), choose("c"),
+			},
+			winner: "a", rounds: 2, votes: map[string]float64{"a": 4, "b": 3}},
+		{name: "elimination tie broken by earlier round",
+			options: []string{"b", "a", "c", "d"},
+			ballots: []Ballot{
+				choose("a"), choose("a"), choose("a"),
+				choose("b"), choose("b"),
+				choose("c"), choose("c"),
+				choose("d", "b"),
+			},
+			winner: "a", tied: []string{"a", "b"}, rounds: 4, votes: map[string]float64{"a": 3}},
+		{name: "final tie goes to earlier option", ballots: []Ballot{choose("b"), choose("a")},
+			winner: "a", tied: []string{"a", "b"}, rounds: 3, votes: map[string]float64{"a": 1}},
+		{name: "no ballots", ballots: nil, winner: "", rounds: 1, votes: map[string]float64{}},
+		{name: "empty ranking", ballots: []Ballot{choose()}, err: ErrInvalidBallot},
+		{name: "repeated ranking", ballots: []Ballot{choose("a", "b", "a")}, err: ErrInvalidBallot},
+	})
+}
+
+func TestInstantRunoffRounds(t *testing.T) {
+	s, _ := Get(InstantRunoff)
+	got, err := s.Tally([]string{"a", "b", "c"}, []Ballot{
+		choose("a"), choose("a"),
+		choose("b", "a"),
+		choose("c", "b"), choose("c", "b"),
+	})
+	if err != nil {
+		t.Fatalf("Tally() error = %v", err)
+	}
+
+	// 第一轮 b 最少被淘汰, 其选票转给 a; 第二轮 a 过半
+	want := []Round{
+		{Number: 1, Scores: []Score{{"a", 2}, {"b", 1}, {"c", 2}}, Eliminated: "b"},
+		{Number: 2, Scores: []Score{{"a", 3}, {"c", 2}}},
+	}
+	if !reflect.DeepEqual(got.Rounds, want) {
+		t.Errorf("Rounds = %+v, want %+v", got.Rounds, want)
+	}
+	if order := []Score{{"a", 3}, {"c", 2}, {"b", 1}}; !reflect.DeepEqual(got.Ranking, order) {
+		t.Errorf("Ranking = %+v, want %+v", got.Ranking, order)
+	}
+}
+
+func TestNames(t *testing.T) {
+	want := []string{Approval, InstantRunoff, OnePersonOneVote, Quadratic, Weighted}
+	if got := Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}